@@ -0,0 +1,32 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"task-management-api/internal/database"
+	"task-management-api/internal/search"
+
+	"gorm.io/gorm/logger"
+)
+
+// reindex rebuilds the tasks_fts full-text index from the tasks table.
+// Usage: go run ./cmd/reindex [--db tasks-management.db] [--dry-run]
+func main() {
+	dbPath := flag.String("db", "tasks-management.db", "path to the SQLite database")
+	dryRun := flag.Bool("dry-run", false, "print what would be done without modifying data")
+	flag.Parse()
+
+	db, err := database.Open(*dbPath, logger.Warn)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	n, err := search.Reindex(db, os.Stdout, *dryRun)
+	if err != nil {
+		log.Fatal("Reindex failed: ", err)
+	}
+	if !*dryRun {
+		log.Printf("Reindex complete: %d tasks indexed", n)
+	}
+}
@@ -2,13 +2,81 @@ package main
 
 import (
 	"log"
+	"task-management-api/internal/auth"
 	"task-management-api/internal/database"
+	"task-management-api/internal/handlers"
+	"task-management-api/internal/health"
+	"task-management-api/internal/middleware"
+	"task-management-api/internal/realtime"
 	"task-management-api/internal/routes"
+	"task-management-api/internal/worker"
+	"time"
+)
+
+// Build metadata, injected with e.g.
+// go build -ldflags "-X main.version=1.2.3 -X main.commit=$(git rev-parse HEAD) -X main.buildDate=$(date -u +%Y-%m-%d)"
+var (
+	version   string
+	commit    string
+	buildDate string
 )
 
 func main() {
+	health.SetBuildInfo(version, commit, buildDate)
+
+	// Validate configuration before touching the database
+	if err := handlers.ValidateConfig(); err != nil {
+		log.Fatal("Invalid configuration: ", err)
+	}
+	if _, err := middleware.CORSConfigFromEnv(); err != nil {
+		log.Fatal("Invalid configuration: ", err)
+	}
+	if err := auth.ValidateConfig(); err != nil {
+		log.Fatal("Invalid configuration: ", err)
+	}
+
 	// Init database
 	database.InitDB()
+	if err := handlers.PromoteAdmin(database.GetDB()); err != nil {
+		log.Println("admin promotion failed:", err)
+	}
+	if err := handlers.WarmUsersCache(database.GetDB()); err != nil {
+		log.Println("users cache warm-up failed:", err)
+	}
+	if err := handlers.RestoreUserRevocations(database.GetDB()); err != nil {
+		log.Println("restoring token revocations failed:", err)
+	}
+
+	// Background workers
+	workers := worker.NewManager()
+	// Drop websocket clients that stopped answering pings without closing cleanly
+	workers.Every("prune-stale-websockets", 60*time.Second, func() {
+		realtime.GetHub().PruneStaleConnections(90 * time.Second)
+	})
+	// Alert assignees of open tasks due within the next 24 hours
+	workers.Every("deadline-alerts", time.Hour, func() {
+		if _, err := worker.SendDeadlineAlerts(database.GetDB(), realtime.GetHub(), time.Now()); err != nil {
+			log.Println("deadline alerts failed:", err)
+		}
+	})
+	// Hard-delete soft-deleted tasks whose grace period has passed
+	workers.Every("purge-deleted-tasks", time.Hour, func() {
+		if n, err := worker.PurgeDeletedTasks(database.GetDB(), time.Now()); err != nil {
+			log.Println("task purge failed:", err)
+		} else if n > 0 {
+			log.Printf("Purged %d deleted tasks", n)
+		}
+	})
+	// Reload the users cache so enrichment picks up changes made outside the API
+	workers.Every("warm-users-cache", 10*time.Minute, func() {
+		if err := handlers.WarmUsersCache(database.GetDB()); err != nil {
+			log.Println("users cache warm-up failed:", err)
+		}
+	})
+	// Forget revoked tokens once they would have expired anyway
+	workers.Every("purge-token-revocations", time.Hour, auth.PurgeExpiredRevocations)
+	workers.Start()
+	defer workers.Stop()
 
 	// Setup the routes (public and protected routes)
 	ginRoutes := routes.SetupRoutes()
@@ -25,6 +93,7 @@ func main() {
 	log.Println("  PATCH  /api/tasks/:id/status")
 	log.Println("  DELETE /api/tasks/:id")
 	log.Println("  GET    /health")
+	log.Println("  GET    /api/version")
 
 	if err := ginRoutes.Run(port); err != nil {
 		log.Fatal("Failed to start server: ", err)
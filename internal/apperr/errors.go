@@ -0,0 +1,66 @@
+package apperr
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// StatusCoder is implemented by domain errors that map to an HTTP status
+type StatusCoder interface {
+	error
+	StatusCode() int
+}
+
+// NotFoundError reports a missing resource
+type NotFoundError struct {
+	Resource string
+	ID       string
+}
+
+func (e NotFoundError) Error() string {
+	if e.ID == "" {
+		return fmt.Sprintf("%s not found", e.Resource)
+	}
+	return fmt.Sprintf("%s %s not found", e.Resource, e.ID)
+}
+
+func (e NotFoundError) StatusCode() int { return http.StatusNotFound }
+
+// ValidationError reports invalid input, keyed by field name
+type ValidationError struct {
+	Fields map[string]string
+}
+
+func (e ValidationError) Error() string {
+	if len(e.Fields) == 1 {
+		for field, msg := range e.Fields {
+			return fmt.Sprintf("%s: %s", field, msg)
+		}
+	}
+	return "validation failed"
+}
+
+func (e ValidationError) StatusCode() int { return http.StatusBadRequest }
+
+// ConflictError reports a request that conflicts with the current state
+type ConflictError struct {
+	Message string
+}
+
+func (e ConflictError) Error() string { return e.Message }
+
+func (e ConflictError) StatusCode() int { return http.StatusConflict }
+
+// UnauthorizedError reports a missing or invalid identity
+type UnauthorizedError struct{}
+
+func (e UnauthorizedError) Error() string { return "authentication required" }
+
+func (e UnauthorizedError) StatusCode() int { return http.StatusUnauthorized }
+
+// ForbiddenError reports an identity lacking permission
+type ForbiddenError struct{}
+
+func (e ForbiddenError) Error() string { return "access forbidden" }
+
+func (e ForbiddenError) StatusCode() int { return http.StatusForbidden }
@@ -0,0 +1,48 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// apiKeyPrefix marks raw API keys so they are recognisable in configs and secret scanners
+const apiKeyPrefix = "tm_"
+
+// NewAPIKey creates an API key: its ID, the raw key to hand to the client once, and the bcrypt
+// hash of its secret part to store. The raw key is "tm_<id>.<secret>"; the ID lets the key be
+// found without scanning, since bcrypt hashes cannot be looked up directly.
+func NewAPIKey() (id, rawKey, hash string, err error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", "", err
+	}
+	id = uuid.NewString()
+	secretHex := hex.EncodeToString(secret)
+	hashed, err := bcrypt.GenerateFromPassword([]byte(secretHex), bcrypt.DefaultCost)
+	if err != nil {
+		return "", "", "", err
+	}
+	return id, apiKeyPrefix + id + "." + secretHex, string(hashed), nil
+}
+
+// ParseAPIKey splits a raw API key into its ID and secret; ok is false when it is malformed
+func ParseAPIKey(rawKey string) (id, secret string, ok bool) {
+	rest, found := strings.CutPrefix(rawKey, apiKeyPrefix)
+	if !found {
+		return "", "", false
+	}
+	id, secret, found = strings.Cut(rest, ".")
+	if !found || id == "" || secret == "" {
+		return "", "", false
+	}
+	return id, secret, true
+}
+
+// CheckAPIKeySecret reports whether secret matches the stored bcrypt hash
+func CheckAPIKeySecret(hash, secret string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(secret)) == nil
+}
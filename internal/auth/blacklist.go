@@ -0,0 +1,72 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"task-management-api/internal/cache"
+	"time"
+)
+
+// tokenBlacklist holds the SHA-256 hashes of individually revoked (logged-out) tokens.
+// Each entry expires when the token itself would have.
+var tokenBlacklist = cache.NewSimpleCache[string, struct{}](cache.Options{ConcurrencySafe: true})
+
+// userRevocations maps a user ID to the time their tokens were revoked. Entries only need to
+// outlive the tokens they reject, so they expire after TokenLifetime().
+var userRevocations = cache.NewSimpleCache[string, time.Time](cache.Options{ConcurrencySafe: true})
+
+func init() {
+	cache.Register(tokenBlacklist)
+	cache.Register(userRevocations)
+}
+
+// hashToken keys the blacklist, so raw tokens are never kept in memory
+func hashToken(tokenString string) string {
+	sum := sha256.Sum256([]byte(tokenString))
+	return hex.EncodeToString(sum[:])
+}
+
+// RevokeToken blacklists a single valid token (e.g. on logout) for the rest of its lifetime.
+// Invalid or expired tokens are rejected anyway and return the validation error.
+func RevokeToken(tokenString string) error {
+	claims, err := ValidateToken(tokenString)
+	if err != nil {
+		return err
+	}
+	ttl := TokenLifetime()
+	if claims.ExpiresAt != nil {
+		ttl = time.Until(claims.ExpiresAt.Time)
+	}
+	if ttl <= 0 {
+		// Expiring right now; a zero TTL would instead mean "never expires"
+		return nil
+	}
+	tokenBlacklist.Set(hashToken(tokenString), struct{}{}, ttl)
+	return nil
+}
+
+// PurgeExpiredRevocations drops revocation entries whose tokens have expired anyway.
+// Expired entries are already ignored; this only reclaims their memory.
+func PurgeExpiredRevocations() {
+	tokenBlacklist.PurgeExpired()
+	userRevocations.PurgeExpired()
+}
+
+// RevokeUserTokens rejects every token issued to the user up to and including the second of at
+// (token issue times have one-second precision), e.g. after an admin password reset.
+func RevokeUserTokens(userID string, at time.Time) {
+	userRevocations.Set(userID, at.Truncate(time.Second), TokenLifetime())
+}
+
+// IsRevoked reports whether a validated token was blacklisted, or its user's tokens were
+// revoked after it was issued. Call it after ValidateToken, with the claims it returned.
+func IsRevoked(tokenString string, claims *Claims) bool {
+	if tokenBlacklist.Has(hashToken(tokenString)) {
+		return true
+	}
+	revokedAt, ok := userRevocations.Get(claims.UserID)
+	if !ok {
+		return false
+	}
+	return claims.IssuedAt == nil || !claims.IssuedAt.After(revokedAt)
+}
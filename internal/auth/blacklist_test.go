@@ -0,0 +1,33 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRevokeToken_EntryExpiresWithToken(t *testing.T) {
+	// Issued so that it expires about two seconds from now
+	token, err := GenerateTokenWithDelay("u-1", "alice", "member", time.Now().Add(-TokenLifetime()+2*time.Second))
+	require.NoError(t, err)
+	claims, err := ValidateToken(token)
+	require.NoError(t, err)
+	require.False(t, IsRevoked(token, claims))
+
+	require.NoError(t, RevokeToken(token))
+	require.True(t, IsRevoked(token, claims))
+	require.True(t, tokenBlacklist.Has(hashToken(token)))
+
+	// Other tokens of the same user are unaffected
+	other, err := GenerateToken("u-1", "alice", "member")
+	require.NoError(t, err)
+	otherClaims, err := ValidateToken(other)
+	require.NoError(t, err)
+	require.False(t, IsRevoked(other, otherClaims))
+
+	// The entry lives exactly as long as the token would have
+	require.Eventually(t, func() bool { return !tokenBlacklist.Has(hashToken(token)) }, 3*time.Second, 50*time.Millisecond)
+	_, err = ValidateToken(token)
+	require.Error(t, err)
+}
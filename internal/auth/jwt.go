@@ -1,15 +1,21 @@
 package auth
 
 import (
+    "crypto/rand"
+    "encoding/hex"
     "errors"
+    "fmt"
     "os"
     "time"
 
     "github.com/golang-jwt/jwt/v5"
 )
 
+// insecureDefaultSecret signs tokens when JWT_SECRET is unset; it is refused in release mode
+const insecureDefaultSecret = "development-insecure-secret-change-me"
+
 var (
-    jwtSecret  = []byte(getEnv("JWT_SECRET", "development-insecure-secret-change-me"))
+    jwtSecret  = []byte(getEnv("JWT_SECRET", insecureDefaultSecret))
     jwtIssuer  = getEnv("JWT_ISSUER", "task-management-api")
     jwtAudience = getEnv("JWT_AUDIENCE", "task-management-clients")
 )
@@ -25,25 +31,93 @@ func getEnv(key, fallback string) string {
 type Claims struct {
 	UserID   string `json:"user_id"`
 	Username string `json:"username"`
+	// Role is the user's role (admin or member) at the time the token was issued
+	Role string `json:"role"`
 	jwt.RegisteredClaims
 }
 
-// GenerateToken generates a JWT token for the given user
-func GenerateToken(userID, username string) (string, error) {
+// ErrTokenNotYetValid is returned when a token is presented before its nbf claim
+var ErrTokenNotYetValid = errors.New("token is not valid yet")
+
+// ErrTokenRevoked is returned when refreshing a token that has been revoked
+var ErrTokenRevoked = errors.New("token has been revoked")
+
+// DefaultTokenLifetime is how long a token stays valid after its nbf time when JWT_EXPIRY is unset
+const DefaultTokenLifetime = 24 * time.Hour
+
+// TokenLifetime is how long a token stays valid after its nbf time.
+// Configured via JWT_EXPIRY as a Go duration (e.g. 12h); defaults to 24h.
+func TokenLifetime() time.Duration {
+	if d, err := time.ParseDuration(getEnv("JWT_EXPIRY", "")); err == nil && d > 0 {
+		return d
+	}
+	return DefaultTokenLifetime
+}
+
+// ValidateConfig checks the JWT environment variables; call it once at startup.
+// RS256 requires both key files to load; with HS256 in release mode (GIN_MODE=release)
+// the built-in development secret is refused.
+func ValidateConfig() error {
+	if raw := os.Getenv("JWT_EXPIRY"); raw != "" {
+		if d, err := time.ParseDuration(raw); err != nil || d <= 0 {
+			return fmt.Errorf("JWT_EXPIRY must be a positive duration such as 24h, got %q", raw)
+		}
+	}
+	switch signingAlgorithm() {
+	case AlgorithmRS256:
+		if _, err := loadRSAPrivateKey(os.Getenv("JWT_PRIVATE_KEY_PATH")); err != nil {
+			return err
+		}
+		if _, err := loadRSAPublicKey(os.Getenv("JWT_PUBLIC_KEY_PATH")); err != nil {
+			return err
+		}
+	case AlgorithmHS256:
+		if os.Getenv("GIN_MODE") == "release" && getEnv("JWT_SECRET", insecureDefaultSecret) == insecureDefaultSecret {
+			return errors.New("JWT_SECRET must be set to a private value when GIN_MODE=release")
+		}
+	default:
+		return fmt.Errorf("JWT_ALGORITHM must be HS256 or RS256, got %q", os.Getenv("JWT_ALGORITHM"))
+	}
+	return nil
+}
+
+// RefreshTokenDelay is how long a refreshed token waits before becoming valid,
+// giving in-flight requests carrying the old token time to complete
+const RefreshTokenDelay = 5 * time.Second
+
+// GenerateToken generates a JWT token for the given user and role
+func GenerateToken(userID, username, role string) (string, error) {
+	return GenerateTokenWithDelay(userID, username, role, time.Now())
+}
+
+// GenerateTokenWithDelay generates a JWT token that is not valid before notBefore
+func GenerateTokenWithDelay(userID, username, role string, notBefore time.Time) (string, error) {
+	now := time.Now()
+	// A random jti keeps tokens issued within the same second distinct, so revoking one leaves the others valid
+	jti := make([]byte, 16)
+	if _, err := rand.Read(jti); err != nil {
+		return "", err
+	}
 	claims := Claims{
 		UserID:   userID,
 		Username: username,
+		Role:     role,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(notBefore.Add(TokenLifetime())),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(notBefore),
+			ID:        hex.EncodeToString(jti),
             Issuer:    jwtIssuer,
             Audience:  jwt.ClaimStrings{jwtAudience},
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString(jwtSecret)
+	method, key, err := signingKey()
+	if err != nil {
+		return "", err
+	}
+	token := jwt.NewWithClaims(method, claims)
+	tokenString, err := token.SignedString(key)
 
 	if err != nil {
 		return "", err
@@ -52,17 +126,27 @@ func GenerateToken(userID, username string) (string, error) {
 	return tokenString, nil
 }
 
+// RefreshToken issues a new token for the same user from a still-valid, unrevoked one.
+// The new token becomes valid after RefreshTokenDelay and then lasts a full TokenLifetime.
+func RefreshToken(tokenString string) (string, error) {
+	claims, err := ValidateToken(tokenString)
+	if err != nil {
+		return "", err
+	}
+	if IsRevoked(tokenString, claims) {
+		return "", ErrTokenRevoked
+	}
+	return GenerateTokenWithDelay(claims.UserID, claims.Username, claims.Role, time.Now().Add(RefreshTokenDelay))
+}
+
 // ValidateToken validates a JWT token and returns the claims
 func ValidateToken(tokenString string) (*Claims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New("invalid signing method")
-		}
-
-		return jwtSecret, nil
-	})
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, verificationKey)
 
 	if err != nil {
+		if errors.Is(err, jwt.ErrTokenNotValidYet) {
+			return nil, notYetValidError(tokenString)
+		}
 		return nil, err
 	}
 
@@ -82,8 +166,64 @@ func ValidateToken(tokenString string) (*Claims, error) {
         if !audValid {
             return nil, errors.New("invalid token audience")
         }
+        if err := checkNotBefore(claims, time.Now()); err != nil {
+            return nil, err
+        }
         return claims, nil
     }
 
 	return nil, errors.New("invalid token")
 }
+
+// checkNotBefore explicitly enforces the nbf claim rather than relying on the parser defaults
+func checkNotBefore(claims *Claims, now time.Time) error {
+	if claims.NotBefore == nil || !claims.NotBefore.After(now) {
+		return nil
+	}
+	return fmt.Errorf("%w: usable from %s", ErrTokenNotYetValid, claims.NotBefore.UTC().Format(time.RFC3339))
+}
+
+// notYetValidError builds a descriptive nbf error from the unverified token claims
+func notYetValidError(tokenString string) error {
+	claims := &Claims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(tokenString, claims); err != nil {
+		return ErrTokenNotYetValid
+	}
+	if err := checkNotBefore(claims, time.Now()); err != nil {
+		return err
+	}
+	return ErrTokenNotYetValid
+}
+
+// Introspection is an RFC 7662 style token introspection result.
+// Inactive tokens carry no other fields.
+type Introspection struct {
+	Active   bool     `json:"active"`
+	Sub      string   `json:"sub,omitempty"`
+	Username string   `json:"username,omitempty"`
+	Role     string   `json:"role,omitempty"`
+	Exp      int64    `json:"exp,omitempty"`
+	Aud      []string `json:"aud,omitempty"`
+	Iss      string   `json:"iss,omitempty"`
+}
+
+// Introspect reports whether a token is currently valid and, if so, its claims.
+// Invalid, expired, not-yet-valid and revoked tokens are simply inactive.
+func Introspect(tokenString string) Introspection {
+	claims, err := ValidateToken(tokenString)
+	if err != nil || IsRevoked(tokenString, claims) {
+		return Introspection{Active: false}
+	}
+	result := Introspection{
+		Active:   true,
+		Sub:      claims.UserID,
+		Username: claims.Username,
+		Role:     claims.Role,
+		Aud:      claims.Audience,
+		Iss:      claims.Issuer,
+	}
+	if claims.ExpiresAt != nil {
+		result.Exp = claims.ExpiresAt.Unix()
+	}
+	return result
+}
@@ -2,12 +2,14 @@ package auth
 
 import (
 	"testing"
+	"time"
 
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/require"
 )
 
 func TestGenerateAndValidateToken(t *testing.T) {
-	token, err := GenerateToken("u-1", "alice")
+	token, err := GenerateToken("u-1", "alice", "member")
 	require.NoError(t, err)
 	require.NotEmpty(t, token)
 
@@ -24,3 +26,105 @@ func TestValidateToken_Invalid(t *testing.T) {
 	_, err := ValidateToken("invalid.token")
 	require.Error(t, err)
 }
+
+func TestValidateToken_RejectsBeforeNotBefore(t *testing.T) {
+	token, err := GenerateTokenWithDelay("u-1", "alice", "member", time.Now().Add(RefreshTokenDelay))
+	require.NoError(t, err)
+
+	_, err = ValidateToken(token)
+	require.ErrorIs(t, err, ErrTokenNotYetValid)
+	require.Contains(t, err.Error(), "usable from")
+}
+
+func TestCheckNotBefore_AcceptsOnceDelayElapsed(t *testing.T) {
+	token, err := GenerateTokenWithDelay("u-1", "alice", "member", time.Now().Add(RefreshTokenDelay))
+	require.NoError(t, err)
+
+	claims := &Claims{}
+	_, _, err = jwt.NewParser().ParseUnverified(token, claims)
+	require.NoError(t, err)
+	require.Error(t, checkNotBefore(claims, time.Now()))
+	require.NoError(t, checkNotBefore(claims, time.Now().Add(RefreshTokenDelay+time.Second)))
+}
+
+func TestRefreshToken(t *testing.T) {
+	token, err := GenerateToken("u-1", "alice", "member")
+	require.NoError(t, err)
+
+	refreshed, err := RefreshToken(token)
+	require.NoError(t, err)
+
+	claims := &Claims{}
+	_, _, err = jwt.NewParser().ParseUnverified(refreshed, claims)
+	require.NoError(t, err)
+	require.Equal(t, "u-1", claims.UserID)
+	require.Equal(t, "alice", claims.Username)
+	// A fresh window, starting once the refresh delay has passed
+	require.WithinDuration(t, time.Now().Add(RefreshTokenDelay+TokenLifetime()), claims.ExpiresAt.Time, 2*time.Second)
+	require.NoError(t, checkNotBefore(claims, time.Now().Add(RefreshTokenDelay+time.Second)))
+
+	expired, err := GenerateTokenWithDelay("u-1", "alice", "member", time.Now().Add(-TokenLifetime()-time.Hour))
+	require.NoError(t, err)
+	_, err = RefreshToken(expired)
+	require.ErrorIs(t, err, jwt.ErrTokenExpired)
+
+	revokedUser, err := GenerateToken("u-revoked", "mallory", "member")
+	require.NoError(t, err)
+	RevokeUserTokens("u-revoked", time.Now())
+	_, err = RefreshToken(revokedUser)
+	require.ErrorIs(t, err, ErrTokenRevoked)
+}
+
+func TestGenerateToken_CarriesRole(t *testing.T) {
+	token, err := GenerateToken("u-1", "alice", "admin")
+	require.NoError(t, err)
+	claims, err := ValidateToken(token)
+	require.NoError(t, err)
+	require.Equal(t, "admin", claims.Role)
+
+	// Refreshing keeps the role
+	refreshed, err := RefreshToken(token)
+	require.NoError(t, err)
+	parsed, _, err := jwt.NewParser().ParseUnverified(refreshed, &Claims{})
+	require.NoError(t, err)
+	require.Equal(t, "admin", parsed.Claims.(*Claims).Role)
+}
+
+func TestTokenLifetime_FromEnv(t *testing.T) {
+	t.Setenv("JWT_EXPIRY", "1s")
+	require.Equal(t, time.Second, TokenLifetime())
+
+	token, err := GenerateToken("u-1", "alice", "member")
+	require.NoError(t, err)
+	_, err = ValidateToken(token)
+	require.NoError(t, err)
+
+	time.Sleep(2 * time.Second)
+	_, err = ValidateToken(token)
+	require.ErrorIs(t, err, jwt.ErrTokenExpired)
+
+	t.Setenv("JWT_EXPIRY", "")
+	require.Equal(t, DefaultTokenLifetime, TokenLifetime())
+}
+
+func TestValidateConfig(t *testing.T) {
+	t.Setenv("GIN_MODE", "")
+	t.Setenv("JWT_SECRET", "")
+	t.Setenv("JWT_EXPIRY", "")
+	require.NoError(t, ValidateConfig())
+
+	t.Setenv("JWT_EXPIRY", "soon")
+	require.Error(t, ValidateConfig())
+	t.Setenv("JWT_EXPIRY", "-1h")
+	require.Error(t, ValidateConfig())
+	t.Setenv("JWT_EXPIRY", "12h")
+	require.NoError(t, ValidateConfig())
+
+	// The development fallback secret is refused in release mode
+	t.Setenv("GIN_MODE", "release")
+	require.Error(t, ValidateConfig())
+	t.Setenv("JWT_SECRET", insecureDefaultSecret)
+	require.Error(t, ValidateConfig())
+	t.Setenv("JWT_SECRET", "a-private-secret")
+	require.NoError(t, ValidateConfig())
+}
@@ -0,0 +1,96 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Signing algorithms selectable with JWT_ALGORITHM
+const (
+	// AlgorithmHS256 signs and verifies with the shared JWT_SECRET (the default)
+	AlgorithmHS256 = "HS256"
+	// AlgorithmRS256 signs with the RSA key at JWT_PRIVATE_KEY_PATH and verifies with the one at
+	// JWT_PUBLIC_KEY_PATH, so other services can verify tokens without being able to issue them
+	AlgorithmRS256 = "RS256"
+)
+
+// signingAlgorithm returns the configured JWT_ALGORITHM (HS256 or RS256); defaults to HS256.
+func signingAlgorithm() string {
+	return strings.ToUpper(strings.TrimSpace(getEnv("JWT_ALGORITHM", AlgorithmHS256)))
+}
+
+// rsaKeys caches parsed PEM keys by file path; rotating a key requires a restart
+var rsaKeys sync.Map
+
+// loadRSAPrivateKey reads and parses a PEM-encoded (PKCS#1 or PKCS#8) RSA private key
+func loadRSAPrivateKey(path string) (*rsa.PrivateKey, error) {
+	if key, ok := rsaKeys.Load("private:" + path); ok {
+		return key.(*rsa.PrivateKey), nil
+	}
+	if path == "" {
+		return nil, errors.New("JWT_PRIVATE_KEY_PATH is required for RS256")
+	}
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read JWT private key: %w", err)
+	}
+	key, err := jwt.ParseRSAPrivateKeyFromPEM(pem)
+	if err != nil {
+		return nil, fmt.Errorf("parse JWT private key %s: %w", path, err)
+	}
+	rsaKeys.Store("private:"+path, key)
+	return key, nil
+}
+
+// loadRSAPublicKey reads and parses a PEM-encoded RSA public key (PKIX, PKCS#1 or a certificate)
+func loadRSAPublicKey(path string) (*rsa.PublicKey, error) {
+	if key, ok := rsaKeys.Load("public:" + path); ok {
+		return key.(*rsa.PublicKey), nil
+	}
+	if path == "" {
+		return nil, errors.New("JWT_PUBLIC_KEY_PATH is required for RS256")
+	}
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read JWT public key: %w", err)
+	}
+	key, err := jwt.ParseRSAPublicKeyFromPEM(pem)
+	if err != nil {
+		return nil, fmt.Errorf("parse JWT public key %s: %w", path, err)
+	}
+	rsaKeys.Store("public:"+path, key)
+	return key, nil
+}
+
+// signingKey returns the method and key new tokens are signed with
+func signingKey() (jwt.SigningMethod, any, error) {
+	if signingAlgorithm() == AlgorithmRS256 {
+		key, err := loadRSAPrivateKey(os.Getenv("JWT_PRIVATE_KEY_PATH"))
+		if err != nil {
+			return nil, nil, err
+		}
+		return jwt.SigningMethodRS256, key, nil
+	}
+	return jwt.SigningMethodHS256, jwtSecret, nil
+}
+
+// verificationKey is the key function for ValidateToken. Only tokens signed with the configured
+// algorithm are accepted, so an HS256 token is rejected by an RS256 server and vice versa.
+func verificationKey(token *jwt.Token) (any, error) {
+	if signingAlgorithm() == AlgorithmRS256 {
+		if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
+			return nil, errors.New("invalid signing method")
+		}
+		return loadRSAPublicKey(os.Getenv("JWT_PUBLIC_KEY_PATH"))
+	}
+	if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+		return nil, errors.New("invalid signing method")
+	}
+	return jwtSecret, nil
+}
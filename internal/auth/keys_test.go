@@ -0,0 +1,80 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+)
+
+// useRS256 writes a fresh RSA key pair to temp files and points the JWT env vars at them.
+func useRS256(t *testing.T) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	pub, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	privPath := filepath.Join(dir, "jwt.key")
+	pubPath := filepath.Join(dir, "jwt.pub")
+	require.NoError(t, os.WriteFile(privPath, pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}), 0o600))
+	require.NoError(t, os.WriteFile(pubPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pub}), 0o644))
+
+	t.Setenv("JWT_ALGORITHM", AlgorithmRS256)
+	t.Setenv("JWT_PRIVATE_KEY_PATH", privPath)
+	t.Setenv("JWT_PUBLIC_KEY_PATH", pubPath)
+}
+
+func TestRS256_RoundTrip(t *testing.T) {
+	useRS256(t)
+	require.NoError(t, ValidateConfig())
+
+	token, err := GenerateToken("u-1", "alice", "member")
+	require.NoError(t, err)
+	parsed, _, err := jwt.NewParser().ParseUnverified(token, &Claims{})
+	require.NoError(t, err)
+	require.Equal(t, "RS256", parsed.Method.Alg())
+
+	claims, err := ValidateToken(token)
+	require.NoError(t, err)
+	require.Equal(t, "u-1", claims.UserID)
+	require.Equal(t, "alice", claims.Username)
+	require.Equal(t, "member", claims.Role)
+}
+
+func TestRS256_RejectsHS256Token(t *testing.T) {
+	t.Setenv("JWT_ALGORITHM", "")
+	hsToken, err := GenerateToken("u-1", "alice", "member")
+	require.NoError(t, err)
+
+	useRS256(t)
+	_, err = ValidateToken(hsToken)
+	require.Error(t, err)
+
+	// ...and an HS256 server rejects RS256 tokens
+	rsToken, err := GenerateToken("u-1", "alice", "member")
+	require.NoError(t, err)
+	t.Setenv("JWT_ALGORITHM", AlgorithmHS256)
+	_, err = ValidateToken(rsToken)
+	require.Error(t, err)
+}
+
+func TestValidateConfig_RS256Keys(t *testing.T) {
+	t.Setenv("JWT_ALGORITHM", AlgorithmRS256)
+	t.Setenv("JWT_PRIVATE_KEY_PATH", "")
+	t.Setenv("JWT_PUBLIC_KEY_PATH", "")
+	require.Error(t, ValidateConfig())
+
+	t.Setenv("JWT_PRIVATE_KEY_PATH", filepath.Join(t.TempDir(), "missing.key"))
+	require.Error(t, ValidateConfig())
+
+	t.Setenv("JWT_ALGORITHM", "none")
+	require.Error(t, ValidateConfig())
+}
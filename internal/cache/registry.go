@@ -0,0 +1,31 @@
+package cache
+
+import "sync"
+
+// Sized is the part of Cache needed to report how many entries it holds.
+type Sized interface {
+	Len() int
+}
+
+var (
+	registryMu sync.Mutex
+	registry   []Sized
+)
+
+// Register adds an application cache to the process-wide size report.
+func Register(c Sized) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry = append(registry, c)
+}
+
+// TotalLen sums the live entries across registered application caches.
+func TotalLen() int {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	total := 0
+	for _, c := range registry {
+		total += c.Len()
+	}
+	return total
+}
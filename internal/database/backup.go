@@ -0,0 +1,91 @@
+package database
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultBackupKeep is how many startup backups are retained when DB_BACKUP_KEEP is unset
+const defaultBackupKeep = 5
+
+// backupTimestampLayout sorts lexicographically in chronological order
+const backupTimestampLayout = "20060102-150405"
+
+// BackupDB copies the database file at srcPath to dstPath
+func BackupDB(srcPath, dstPath string) error {
+	src, err := os.Open(srcPath)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(dstPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		os.Remove(dstPath)
+		return err
+	}
+	if err := dst.Sync(); err != nil {
+		dst.Close()
+		return err
+	}
+	return dst.Close()
+}
+
+// backupOnStart reports whether InitDB backs up the existing database (DB_BACKUP_ON_START, default true)
+func backupOnStart() bool {
+	return !strings.EqualFold(os.Getenv("DB_BACKUP_ON_START"), "false")
+}
+
+// backupKeep returns how many backups to retain (DB_BACKUP_KEEP, default 5)
+func backupKeep() int {
+	if n, err := strconv.Atoi(os.Getenv("DB_BACKUP_KEEP")); err == nil && n >= 0 {
+		return n
+	}
+	return defaultBackupKeep
+}
+
+// backupPattern matches the backups created for dbPath
+func backupPattern(dbPath string) string {
+	return strings.TrimSuffix(dbPath, filepath.Ext(dbPath)) + "-backup-*.db"
+}
+
+// backupBeforeMigrate copies an existing database file to <dbname>-backup-<timestamp>.db and
+// prunes old backups beyond keep. Fresh installs (no file yet) are skipped.
+func backupBeforeMigrate(dbPath string, now time.Time, keep int) error {
+	if _, err := os.Stat(dbPath); os.IsNotExist(err) {
+		return nil
+	}
+	dst := strings.TrimSuffix(dbPath, filepath.Ext(dbPath)) + "-backup-" + now.Format(backupTimestampLayout) + ".db"
+	if err := BackupDB(dbPath, dst); err != nil {
+		return fmt.Errorf("failed to back up database: %w", err)
+	}
+	log.Println("Database backed up to", dst)
+	return pruneBackups(dbPath, keep)
+}
+
+// pruneBackups deletes the oldest backups of dbPath so that at most keep remain
+func pruneBackups(dbPath string, keep int) error {
+	backups, err := filepath.Glob(backupPattern(dbPath))
+	if err != nil {
+		return err
+	}
+	sort.Strings(backups)
+	for len(backups) > keep {
+		if err := os.Remove(backups[0]); err != nil {
+			return err
+		}
+		backups = backups[1:]
+	}
+	return nil
+}
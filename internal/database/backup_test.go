@@ -0,0 +1,46 @@
+package database
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackupDB_ByteIdenticalCopy(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "tasks.db")
+	dst := filepath.Join(dir, "tasks-copy.db")
+	content := []byte("SQLite format 3\x00\x01\x02 known payload")
+	require.NoError(t, os.WriteFile(src, content, 0o600))
+
+	require.NoError(t, BackupDB(src, dst))
+
+	got, err := os.ReadFile(dst)
+	require.NoError(t, err)
+	require.Equal(t, content, got)
+}
+
+func TestBackupBeforeMigrate_RotatesAndSkipsFreshInstall(t *testing.T) {
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "tasks.db")
+
+	// Fresh install: nothing to back up
+	require.NoError(t, backupBeforeMigrate(dbPath, time.Now(), 2))
+	matches, _ := filepath.Glob(backupPattern(dbPath))
+	require.Empty(t, matches)
+
+	require.NoError(t, os.WriteFile(dbPath, []byte("db"), 0o600))
+	start := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	for i := 0; i < 4; i++ {
+		require.NoError(t, backupBeforeMigrate(dbPath, start.Add(time.Duration(i)*time.Minute), 2))
+	}
+
+	matches, _ = filepath.Glob(backupPattern(dbPath))
+	require.Equal(t, []string{
+		filepath.Join(dir, "tasks-backup-20250101-090200.db"),
+		filepath.Join(dir, "tasks-backup-20250101-090300.db"),
+	}, matches)
+}
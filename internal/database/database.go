@@ -1,8 +1,12 @@
 package database
 
 import (
+	"database/sql"
+	"errors"
+	"fmt"
 	"log"
 	"task-management-api/internal/models"
+	"time"
 
 	"github.com/glebarez/sqlite"
 	"gorm.io/gorm"
@@ -13,32 +17,81 @@ var DB *gorm.DB
 
 // InitDB initializes the database connection and runs migrations
 func InitDB() {
+	const dbPath = "tasks-management.db"
+
+	// Keep a copy of the existing file in case the migration below is incompatible
+	if backupOnStart() {
+		if err := backupBeforeMigrate(dbPath, time.Now(), backupKeep()); err != nil {
+			log.Fatal(err)
+		}
+	}
+
 	var err error
+	DB, err = Open(dbPath, logger.Info)
+	if err != nil {
+		log.Fatal(err)
+	}
 
+	log.Println("Database connected and migrated successfully!!!")
+}
+
+// Open connects to the SQLite database at path and runs migrations.
+// Used by the server and by standalone tools under cmd/.
+func Open(path string, logLevel logger.LogLevel) (*gorm.DB, error) {
 	// Open SQLite database file (will be created if it doesn't exist initially)
 	// Using glebarez/sqlite which is a pure Go implementation (no CGO required)
-	DB, err = gorm.Open(sqlite.Open("tasks-management.db"), &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Info),
+	db, err := gorm.Open(sqlite.Open(path), &gorm.Config{
+		Logger: NewRequestLogger(logger.Default).LogMode(logLevel),
 	})
-
 	if err != nil {
-		log.Fatal("Failed to connect to database: ", err)
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
 
 	// Auto-migrate the schema (it will create tables if they don't exist)
-	err = DB.AutoMigrate(
+	err = db.AutoMigrate(
 		&models.User{},
 		&models.Task{},
+		&models.Board{},
+		&models.BoardColumn{},
+		&models.UserSetting{},
+		&models.TaskAudit{},
+		&models.APIKey{},
+		&models.LoginAttempt{},
+		&models.AuditEvent{},
+		&models.Comment{},
 	)
-
 	if err != nil {
-		log.Fatal("Failed to migrate database:", err)
+		return nil, fmt.Errorf("failed to migrate database: %w", err)
 	}
 
-	log.Println("Database connected and migrated successfully!!!")
+	return db, nil
 }
 
 // GetDB returns the database connection
 func GetDB() *gorm.DB {
 	return DB
 }
+
+// Stats returns connection pool statistics; zero values when the DB is not initialized
+func Stats() sql.DBStats {
+	if DB == nil {
+		return sql.DBStats{}
+	}
+	sqlDB, err := DB.DB()
+	if err != nil {
+		return sql.DBStats{}
+	}
+	return sqlDB.Stats()
+}
+
+// Ping checks that the database connection is alive
+func Ping() error {
+	if DB == nil {
+		return errors.New("database not initialized")
+	}
+	sqlDB, err := DB.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Ping()
+}
@@ -0,0 +1,51 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"task-management-api/internal/requestid"
+
+	"gorm.io/gorm/logger"
+)
+
+// requestLogger prefixes GORM log lines with the request ID found in the query context
+type requestLogger struct {
+	logger.Interface
+}
+
+// NewRequestLogger wraps base so that queries run with db.WithContext(ctx) are tagged
+// with the request ID carried by ctx
+func NewRequestLogger(base logger.Interface) logger.Interface {
+	return requestLogger{Interface: base}
+}
+
+func (l requestLogger) LogMode(level logger.LogLevel) logger.Interface {
+	return requestLogger{Interface: l.Interface.LogMode(level)}
+}
+
+func (l requestLogger) Info(ctx context.Context, msg string, args ...interface{}) {
+	l.Interface.Info(ctx, withRequestID(ctx, msg), args...)
+}
+
+func (l requestLogger) Warn(ctx context.Context, msg string, args ...interface{}) {
+	l.Interface.Warn(ctx, withRequestID(ctx, msg), args...)
+}
+
+func (l requestLogger) Error(ctx context.Context, msg string, args ...interface{}) {
+	l.Interface.Error(ctx, withRequestID(ctx, msg), args...)
+}
+
+func (l requestLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	l.Interface.Trace(ctx, begin, func() (string, int64) {
+		sql, rows := fc()
+		return withRequestID(ctx, sql), rows
+	}, err)
+}
+
+func withRequestID(ctx context.Context, s string) string {
+	if id := requestid.FromContext(ctx); id != "" {
+		return "[request_id=" + id + "] " + s
+	}
+	return s
+}
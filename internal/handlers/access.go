@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"net/http"
+	"task-management-api/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// isAdmin reports whether the caller's role (stored in context by the auth middleware) is admin
+func isAdmin(c *gin.Context) bool {
+	return c.GetString("role") == models.RoleAdmin
+}
+
+// requireAdmin writes a 403 and returns false when the caller is not an admin
+func requireAdmin(c *gin.Context) bool {
+	if !isAdmin(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Admin access required"})
+		return false
+	}
+	return true
+}
+
+// includeDeleted reads the admin-only ?includeDeleted=true flag.
+// For a non-admin caller setting it, a 403 is written and ok is false.
+func includeDeleted(c *gin.Context) (include, ok bool) {
+	if c.Query("includeDeleted") != "true" {
+		return false, true
+	}
+	if !requireAdmin(c) {
+		return false, false
+	}
+	return true, true
+}
+
+// taskPermissions describes what the caller may do with task, mirroring the checks of the write
+// handlers: only the owner edits or changes status (UpdateTask, UpdateTaskStatus), the owner or an
+// admin deletes (DeleteTask), and status moves follow models.AllowedTransitions. Soft-deleted tasks
+// allow nothing until restored.
+func taskPermissions(c *gin.Context, task models.Task) gin.H {
+	live := !task.DeletedAt.Valid
+	owner := live && task.UserID == c.GetString("user_id")
+	transitions := []models.TaskStatus{}
+	if owner {
+		if next, ok := models.AllowedTransitions[task.Status]; ok {
+			transitions = append(transitions, next...)
+		}
+	}
+	return gin.H{
+		"canEdit":           owner,
+		"canDelete":         owner || (live && isAdmin(c)),
+		"canChangeStatus":   len(transitions) > 0,
+		"statusTransitions": transitions,
+	}
+}
@@ -0,0 +1,241 @@
+package handlers
+
+import (
+	"net/http"
+	"runtime"
+	"strings"
+	"task-management-api/internal/cache"
+	"task-management-api/internal/database"
+	"task-management-api/internal/health"
+	"task-management-api/internal/models"
+	"task-management-api/internal/realtime"
+	"task-management-api/internal/response"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// GetMetricsSnapshot handles GET /api/admin/metrics/snapshot (admin only)
+// Returns a human-readable snapshot of runtime, cache, database and websocket metrics
+func GetMetricsSnapshot(c *gin.Context) {
+	if !requireAdmin(c) {
+		return
+	}
+
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	// PauseNs is a circular buffer; the most recent pause is at (NumGC+255)%256
+	lastPause := mem.PauseNs[(mem.NumGC+255)%256]
+
+	dbStats := database.Stats()
+
+	c.JSON(http.StatusOK, gin.H{
+		"goroutines": runtime.NumGoroutine(),
+		"memAllocMB": float64(mem.Alloc) / (1024 * 1024),
+		"gcPauseMs":  float64(lastPause) / float64(time.Millisecond),
+		"cacheSize":  cache.TotalLen(),
+		"dbStats": gin.H{
+			"maxOpenConnections": dbStats.MaxOpenConnections,
+			"openConnections":    dbStats.OpenConnections,
+			"inUse":              dbStats.InUse,
+			"idle":               dbStats.Idle,
+			"waitCount":          dbStats.WaitCount,
+			"waitDurationMs":     dbStats.WaitDuration.Milliseconds(),
+		},
+		"hubConnections": realtime.GetHub().TotalConnections(),
+		"uptime":         int64(time.Since(health.StartedAt()).Seconds()),
+	})
+}
+
+// SetRealtimePausedRequest toggles the realtime maintenance pause
+type SetRealtimePausedRequest struct {
+	Paused *bool `json:"paused" binding:"required"`
+	// Flush delivers the broadcasts buffered while paused when resuming
+	Flush bool `json:"flush"`
+}
+
+// SetRealtimePaused handles PUT /api/admin/realtime/pause (admin only)
+// Pauses realtime broadcasts during bulk maintenance, or resumes them (optionally flushing the buffer).
+func SetRealtimePaused(c *gin.Context) {
+	if !requireAdmin(c) {
+		return
+	}
+
+	var req SetRealtimePausedRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	hub := realtime.GetHub()
+	flushed := 0
+	switch {
+	case *req.Paused:
+		hub.SetPaused(true)
+	case req.Flush:
+		flushed = hub.ResumeAndFlush()
+	default:
+		hub.SetPaused(false)
+	}
+
+	paused, buffered, dropped := hub.PauseState()
+	c.JSON(http.StatusOK, gin.H{
+		"paused":   paused,
+		"buffered": buffered,
+		"dropped":  dropped,
+		"flushed":  flushed,
+	})
+}
+
+// GetAuthAuditLog handles GET /api/admin/auditlog (admin only)
+// Lists auth audit events (logins, logouts, password changes), newest first, with page/limit
+// pagination. Optional userId and eventType narrow the list.
+func GetAuthAuditLog(c *gin.Context) {
+	if !requireAdmin(c) {
+		return
+	}
+
+	page, limit, offset := parsePagination(c)
+	filterUserID := strings.TrimSpace(c.Query("userId"))
+	eventType := models.AuditEventType(strings.TrimSpace(c.Query("eventType")))
+	if eventType != "" && !eventType.IsValid() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid eventType; allowed: login_success, login_failure, logout, password_change"})
+		return
+	}
+	filters := func(query *gorm.DB) *gorm.DB {
+		if filterUserID != "" {
+			query = query.Where("user_id = ?", filterUserID)
+		}
+		if eventType != "" {
+			query = query.Where("event_type = ?", eventType)
+		}
+		return query
+	}
+
+	var total int64
+	var events []models.AuditEvent
+	err := requestDB(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.AuditEvent{}).Scopes(filters).Count(&total).Error; err != nil {
+			return err
+		}
+		return tx.Scopes(filters).Order("created_at desc, id desc").Limit(limit).Offset(offset).Find(&events).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch audit log"})
+		return
+	}
+
+	resp := paginationMeta(total, page, limit)
+	resp["events"] = events
+	resp["count"] = len(events)
+	c.JSON(http.StatusOK, resp)
+}
+
+// auditFeedEntry is one task audit entry in the global feed, with its task and user resolved
+type auditFeedEntry struct {
+	Action     models.AuditAction `json:"action"`
+	FromStatus models.TaskStatus  `json:"fromStatus,omitempty"`
+	ToStatus   models.TaskStatus  `json:"toStatus,omitempty"`
+	CreatedAt  time.Time          `json:"createdAt"`
+	Task       gin.H              `json:"task"`
+	User       gin.H              `json:"user"`
+}
+
+// GetAuditFeed handles GET /api/audit?from=&to=&userId= (admin only)
+// Returns every task audit entry, newest first, with page/limit pagination. from/to (ISO date or
+// RFC3339; a date-only to covers that day) bound the time window and userId keeps one actor's entries.
+// Each entry references its task (id, title; deleted tasks included) and user (id, username).
+func GetAuditFeed(c *gin.Context) {
+	if !requireAdmin(c) {
+		return
+	}
+
+	page, limit, offset := parsePagination(c)
+	filterUserID := strings.TrimSpace(c.Query("userId"))
+	var from, to time.Time
+	var err error
+	if raw := c.Query("from"); raw != "" {
+		if from, err = parseActivityBound(raw, false); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "from: " + err.Error()})
+			return
+		}
+	}
+	if raw := c.Query("to"); raw != "" {
+		if to, err = parseActivityBound(raw, true); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "to: " + err.Error()})
+			return
+		}
+	}
+	if !from.IsZero() && !to.IsZero() && !from.Before(to) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from must be before to"})
+		return
+	}
+	filters := func(query *gorm.DB) *gorm.DB {
+		if filterUserID != "" {
+			query = query.Where("user_id = ?", filterUserID)
+		}
+		if !from.IsZero() {
+			query = query.Where("created_at >= ?", from)
+		}
+		if !to.IsZero() {
+			query = query.Where("created_at < ?", to)
+		}
+		return query
+	}
+
+	db := requestDB(c)
+	var total int64
+	var entries []models.TaskAudit
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.TaskAudit{}).Scopes(filters).Count(&total).Error; err != nil {
+			return err
+		}
+		return tx.Scopes(filters).Order("created_at desc, id desc").Limit(limit).Offset(offset).Find(&entries).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch audit log"})
+		return
+	}
+
+	// Resolve references in two queries; tasks and users may since have been deleted
+	taskIDs := make([]string, 0, len(entries))
+	userIDs := make([]string, 0, len(entries))
+	for _, e := range entries {
+		taskIDs = append(taskIDs, e.TaskID)
+		userIDs = append(userIDs, e.UserID)
+	}
+	titles := map[string]string{}
+	usernames := map[string]string{}
+	if len(entries) > 0 {
+		var tasks []models.Task
+		if err := db.Unscoped().Select("id", "title").Where("id IN ?", taskIDs).Find(&tasks).Error; err == nil {
+			for _, t := range tasks {
+				titles[t.ID] = t.Title
+			}
+		}
+		var users []models.User
+		if err := db.Unscoped().Select("id", "username").Where("id IN ?", userIDs).Find(&users).Error; err == nil {
+			for _, u := range users {
+				usernames[u.ID] = u.Username
+			}
+		}
+	}
+
+	feed := make([]auditFeedEntry, 0, len(entries))
+	for _, e := range entries {
+		feed = append(feed, auditFeedEntry{
+			Action:     e.Action,
+			FromStatus: e.FromStatus,
+			ToStatus:   e.ToStatus,
+			CreatedAt:  e.CreatedAt,
+			Task:       gin.H{"id": response.ExternalID(e.TaskID), "title": titles[e.TaskID]},
+			User:       gin.H{"id": response.ExternalID(e.UserID), "username": usernames[e.UserID]},
+		})
+	}
+
+	resp := paginationMeta(total, page, limit)
+	resp["entries"] = feed
+	resp["count"] = len(feed)
+	c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,207 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"task-management-api/internal/database"
+	"task-management-api/internal/models"
+	"task-management-api/internal/realtime"
+	"task-management-api/internal/testutil"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetMetricsSnapshot(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := testutil.NewInMemoryDB()
+	require.NoError(t, err)
+	database.DB = db
+
+	r := gin.New()
+	r.GET("/api/admin/metrics/snapshot", func(c *gin.Context) {
+		c.Set("user_id", "u-1")
+		c.Set("role", models.RoleAdmin)
+	}, GetMetricsSnapshot)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/admin/metrics/snapshot", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var snap map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &snap))
+	for _, field := range []string{"goroutines", "memAllocMB", "gcPauseMs", "cacheSize", "hubConnections", "uptime"} {
+		v, ok := snap[field].(float64)
+		require.True(t, ok, "missing numeric field %s", field)
+		require.GreaterOrEqual(t, v, float64(0), field)
+	}
+	dbStats, ok := snap["dbStats"].(map[string]any)
+	require.True(t, ok)
+	require.GreaterOrEqual(t, dbStats["openConnections"].(float64), float64(0))
+}
+
+func TestSetRealtimePaused(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	hub := realtime.GetHub()
+	client := &recordingClient{}
+	hub.Register("u-pause", client)
+	t.Cleanup(func() {
+		hub.SetPaused(false)
+		hub.Unregister("u-pause", client)
+	})
+
+	role := models.RoleMember
+	r := gin.New()
+	r.PUT("/api/admin/realtime/pause", func(c *gin.Context) { c.Set("role", role) }, SetRealtimePaused)
+	put := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPut, "/api/admin/realtime/pause", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	require.Equal(t, http.StatusForbidden, put(`{"paused": true}`).Code)
+
+	role = models.RoleAdmin
+	require.Equal(t, http.StatusOK, put(`{"paused": true}`).Code)
+	hub.Broadcast("u-pause", []byte(`{"type":"task_created"}`))
+	require.Empty(t, client.events(t))
+
+	w := put(`{"paused": false, "flush": true}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.JSONEq(t, `{"paused":false,"buffered":0,"dropped":0,"flushed":1}`, w.Body.String())
+	require.Len(t, client.events(t), 1)
+
+	hub.Broadcast("u-pause", []byte(`{"type":"task_updated"}`))
+	require.Len(t, client.events(t), 2)
+}
+
+func TestGetAuthAuditLog_FiltersByUser(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := testutil.NewInMemoryDB()
+	require.NoError(t, err)
+	database.DB = db
+
+	for _, e := range []models.AuditEvent{
+		{UserID: "u-1", EventType: models.EventLoginSuccess},
+		{UserID: "u-2", EventType: models.EventLoginFailure},
+		{UserID: "u-1", EventType: models.EventLogout},
+		{UserID: "u-2", EventType: models.EventPasswordChange},
+	} {
+		require.NoError(t, db.Create(&e).Error)
+	}
+
+	role := models.RoleAdmin
+	r := gin.New()
+	r.GET("/api/admin/auditlog", func(c *gin.Context) {
+		c.Set("user_id", "admin-1")
+		c.Set("role", role)
+	}, GetAuthAuditLog)
+	get := func(query string) (int, []models.AuditEvent) {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/admin/auditlog?"+query, nil))
+		var resp struct {
+			Events []models.AuditEvent `json:"events"`
+		}
+		if w.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		}
+		return w.Code, resp.Events
+	}
+
+	code, events := get("userId=u-1&limit=10")
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, events, 2)
+	for _, e := range events {
+		require.Equal(t, "u-1", e.UserID)
+	}
+	require.Equal(t, models.EventLogout, events[0].EventType) // newest first
+
+	_, events = get("userId=u-2&eventType=password_change")
+	require.Len(t, events, 1)
+	require.Equal(t, models.EventPasswordChange, events[0].EventType)
+
+	code, _ = get("eventType=bogus")
+	require.Equal(t, http.StatusBadRequest, code)
+
+	role = models.RoleMember
+	code, _ = get("")
+	require.Equal(t, http.StatusForbidden, code)
+}
+
+func TestGetAuditFeed_FiltersByUserAndWindow(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := testutil.NewInMemoryDB()
+	require.NoError(t, err)
+	database.DB = db
+
+	require.NoError(t, db.Create(&models.User{ID: "u-1", Username: "alice", Password: "x"}).Error)
+	require.NoError(t, db.Create(&models.User{ID: "u-2", Username: "bob", Password: "x"}).Error)
+	require.NoError(t, db.Create(&models.Task{ID: "task-1", Title: "Alpha", TaskType: models.TypeStory, UserID: "u-1"}).Error)
+	require.NoError(t, db.Create(&models.Task{ID: "task-2", Title: "Beta", TaskType: models.TypeStory, UserID: "u-2"}).Error)
+	day := func(d int) time.Time { return time.Date(2025, 3, d, 12, 0, 0, 0, time.UTC) }
+	for _, e := range []models.TaskAudit{
+		{TaskID: "task-1", UserID: "u-1", Action: models.AuditCreated, CreatedAt: day(1)},
+		{TaskID: "task-2", UserID: "u-2", Action: models.AuditCreated, CreatedAt: day(2)},
+		{TaskID: "task-1", UserID: "u-2", Action: models.AuditStatusChanged, FromStatus: models.StatusTodo, ToStatus: models.StatusInProgress, CreatedAt: day(3)},
+		{TaskID: "task-1", UserID: "u-1", Action: models.AuditUpdated, CreatedAt: day(5)},
+	} {
+		require.NoError(t, db.Create(&e).Error)
+	}
+
+	r := gin.New()
+	r.GET("/api/audit", func(c *gin.Context) {
+		c.Set("user_id", "admin-1")
+		c.Set("role", models.RoleAdmin)
+	}, GetAuditFeed)
+	type entry struct {
+		Action   models.AuditAction `json:"action"`
+		ToStatus models.TaskStatus  `json:"toStatus"`
+		Task     struct{ ID, Title string }
+		User     struct{ ID, Username string }
+	}
+	get := func(query string) (int, []entry, int64) {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/audit?"+query, nil))
+		var resp struct {
+			Entries []entry `json:"entries"`
+			Total   int64   `json:"total"`
+		}
+		if w.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		}
+		return w.Code, resp.Entries, resp.Total
+	}
+
+	code, entries, total := get("limit=10")
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, int64(4), total)
+	require.Equal(t, models.AuditUpdated, entries[0].Action) // newest first
+
+	_, entries, _ = get("userId=u-2")
+	require.Len(t, entries, 2)
+	require.Equal(t, models.AuditStatusChanged, entries[0].Action)
+	require.Equal(t, models.StatusInProgress, entries[0].ToStatus)
+	require.Equal(t, "Alpha", entries[0].Task.Title)
+	require.Equal(t, "bob", entries[0].User.Username)
+	require.Equal(t, "Beta", entries[1].Task.Title)
+
+	// to is inclusive of a date-only day
+	_, entries, _ = get("from=2025-03-02&to=2025-03-03")
+	require.Len(t, entries, 2)
+	require.Equal(t, "task-1", entries[0].Task.ID)
+	require.Equal(t, "task-2", entries[1].Task.ID)
+
+	_, entries, _ = get("userId=u-1&from=2025-03-02")
+	require.Len(t, entries, 1)
+	require.Equal(t, models.AuditUpdated, entries[0].Action)
+
+	code, _, _ = get("from=2025-03-05&to=2025-03-01")
+	require.Equal(t, http.StatusBadRequest, code)
+}
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"task-management-api/internal/auth"
+	"task-management-api/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreateAPIKeyRequest names a new API key and optionally sets when it stops working
+type CreateAPIKeyRequest struct {
+	Name      string     `json:"name" binding:"required"`
+	ExpiresAt *time.Time `json:"expiresAt"`
+}
+
+// CreateAPIKey handles POST /api/apikeys
+// Issues an API key acting as the caller. The raw key is only returned in this response;
+// the server keeps just a bcrypt hash of it.
+func CreateAPIKey(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in token"})
+		return
+	}
+
+	var req CreateAPIKeyRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name is required"})
+		return
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "expiresAt must be in the future"})
+		return
+	}
+
+	id, rawKey, hash, err := auth.NewAPIKey()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate API key"})
+		return
+	}
+	key := models.APIKey{ID: id, UserID: userID, KeyHash: hash, Name: name, ExpiresAt: req.ExpiresAt}
+	if err := requestDB(c).Create(&key).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create API key"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"id":        key.ID,
+		"name":      key.Name,
+		"createdAt": key.CreatedAt,
+		"expiresAt": key.ExpiresAt,
+		"key":       rawKey,
+	})
+}
+
+// ListAPIKeys handles GET /api/apikeys
+// Returns the caller's API keys, newest first, without any key material
+func ListAPIKeys(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in token"})
+		return
+	}
+
+	keys := []models.APIKey{}
+	if err := requestDB(c).Where("user_id = ?", userID).Order("created_at desc").Find(&keys).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch API keys"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"apiKeys": keys})
+}
+
+// DeleteAPIKey handles DELETE /api/apikeys/:id
+// Revokes one of the caller's API keys; it stops working immediately
+func DeleteAPIKey(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in token"})
+		return
+	}
+
+	result := requestDB(c).Where("id = ? AND user_id = ?", c.Param("id"), userID).Delete(&models.APIKey{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete API key"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "API key not found"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "API key deleted", "id": c.Param("id")})
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"task-management-api/internal/auth"
+	"task-management-api/internal/database"
+	"task-management-api/internal/middleware"
+	"task-management-api/internal/models"
+	"task-management-api/internal/testutil"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIKeys_CreateUseListDelete(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := testutil.NewInMemoryDB()
+	require.NoError(t, err)
+	database.DB = db
+	require.NoError(t, db.Create(&models.User{ID: "u-1", Username: "alice", Password: "x"}).Error)
+
+	r := gin.New()
+	r.Use(middleware.JWTAuthMiddleware())
+	r.GET("/api/apikeys", ListAPIKeys)
+	r.POST("/api/apikeys", CreateAPIKey)
+	r.DELETE("/api/apikeys/:id", DeleteAPIKey)
+	r.GET("/api/tasks", GetTasks)
+	token, err := auth.GenerateToken("u-1", "alice", models.RoleMember)
+	require.NoError(t, err)
+	call := func(method, path, body string, header, value string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(header, value)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	withToken := func(method, path, body string) *httptest.ResponseRecorder {
+		return call(method, path, body, "Authorization", "Bearer "+token)
+	}
+
+	w := withToken(http.MethodPost, "/api/apikeys", `{"name":"ci pipeline"}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var created struct {
+		ID  string `json:"id"`
+		Key string `json:"key"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	require.NotEmpty(t, created.Key)
+
+	// Only the hash is stored
+	var stored models.APIKey
+	require.NoError(t, db.First(&stored, "id = ?", created.ID).Error)
+	require.NotContains(t, stored.KeyHash, created.Key)
+	require.Equal(t, "u-1", stored.UserID)
+
+	// The key authenticates as its owner
+	require.Equal(t, http.StatusOK, call(http.MethodGet, "/api/tasks", "", "X-API-Key", created.Key).Code)
+
+	// Listing never returns key material
+	w = withToken(http.MethodGet, "/api/apikeys", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Contains(t, w.Body.String(), "ci pipeline")
+	require.NotContains(t, w.Body.String(), created.Key)
+	require.NotContains(t, w.Body.String(), stored.KeyHash)
+
+	require.Equal(t, http.StatusBadRequest, withToken(http.MethodPost, "/api/apikeys", `{"name":"old","expiresAt":"2020-01-01T00:00:00Z"}`).Code)
+
+	// Other users cannot delete it; its owner can, and it stops working
+	other, err := auth.GenerateToken("u-2", "bob", models.RoleMember)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusNotFound, call(http.MethodDelete, "/api/apikeys/"+created.ID, "", "Authorization", "Bearer "+other).Code)
+	require.Equal(t, http.StatusOK, withToken(http.MethodDelete, "/api/apikeys/"+created.ID, "").Code)
+	require.Equal(t, http.StatusUnauthorized, call(http.MethodGet, "/api/tasks", "", "X-API-Key", created.Key).Code)
+}
@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"log"
+	"task-management-api/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// recordAudit appends an entry to the task audit trail. Failures are logged, not returned:
+// the audit trail must never fail the write it describes.
+func recordAudit(db *gorm.DB, taskID, userID string, action models.AuditAction) {
+	recordStatusAudit(db, taskID, userID, action, "", "")
+}
+
+// recordStatusAudit is recordAudit for changes that carry a status transition
+func recordStatusAudit(db *gorm.DB, taskID, userID string, action models.AuditAction, from, to models.TaskStatus) {
+	entry := models.TaskAudit{TaskID: taskID, UserID: userID, Action: action, FromStatus: from, ToStatus: to}
+	if err := db.Create(&entry).Error; err != nil {
+		log.Printf("audit: failed to record %s of task %s by %s: %v", action, taskID, userID, err)
+	}
+}
+
+// recordAuthEvent appends an entry to the auth audit log with the caller's IP and user agent.
+// Like recordAudit, failures are only logged.
+func recordAuthEvent(c *gin.Context, userID string, eventType models.AuditEventType) {
+	entry := models.AuditEvent{
+		UserID:    userID,
+		EventType: eventType,
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	}
+	if err := requestDB(c).Create(&entry).Error; err != nil {
+		log.Printf("audit: failed to record %s for user %q: %v", eventType, userID, err)
+	}
+}
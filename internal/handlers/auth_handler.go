@@ -1,10 +1,13 @@
 package handlers
 
 import (
+	"math"
 	"net/http"
+	"strconv"
+	"strings"
 	"task-management-api/internal/auth"
-	"task-management-api/internal/database"
 	"task-management-api/internal/models"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -25,9 +28,73 @@ type LoginResponse struct {
 	Message  string `json:"message"`
 }
 
+// RegisterRequest represents the registration request payload
+type RegisterRequest struct {
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
+	Email    string `json:"email" binding:"required,email"`
+}
+
+// Register handles POST /api/register
+// Creates an account and logs it in. The password is the FE's SHA-256 hash, stored as bcrypt(hashFromFE).
+// Usernames are unique, including those of deactivated accounts; taken ones get 409.
+func Register(c *gin.Context) {
+	var req RegisterRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request. Username, password and a valid email are required.",
+		})
+		return
+	}
+
+	db := requestDB(c)
+
+	var existing models.User
+	if err := db.Unscoped().Where("username = ?", req.Username).First(&existing).Error; err == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Username already exists"})
+		return
+	}
+
+	hashed, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process password"})
+		return
+	}
+
+	newUser := models.User{
+		ID:       uuid.NewString(),
+		Username: req.Username,
+		Password: string(hashed),
+		Email:    req.Email,
+		Role:     models.RoleMember,
+	}
+
+	if err := db.Create(&newUser).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user"})
+		return
+	}
+	cacheUser(newUser)
+
+	token, err := auth.GenerateToken(newUser.ID, newUser.Username, newUser.Role)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, LoginResponse{
+		Token:    token,
+		UserID:   newUser.ID,
+		Username: newUser.Username,
+		Message:  "Registration successful",
+	})
+}
+
 // Login handles the login endpoint with unique username and password verification
 // Password provided by FE is a SHA-256 hash of the original password.
-// We store and verify using bcrypt(hashFromFE).
+// We verify it against the stored bcrypt(hashFromFE). Unknown usernames get 401; accounts
+// are created through Register. After MAX_LOGIN_ATTEMPTS consecutive failures within
+// LOCKOUT_DURATION the account gets 423 with a Retry-After header until the window passes.
 // POST /api/login
 func Login(c *gin.Context) {
 	var req LoginRequest
@@ -39,62 +106,138 @@ func Login(c *gin.Context) {
 		return
 	}
 
-	db := database.GetDB()
+	db := requestDB(c)
 
 	// Find user by username
 	var user models.User
-	result := db.Where("username = ?", req.Username).First(&user)
-
-	if result.Error == nil {
-		// Username exists → verify password (bcrypt compare)
-		if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
-			return
+	if err := db.Where("username = ?", req.Username).First(&user).Error; err != nil {
+		// Deactivated (soft-deleted) accounts keep their username and cannot log in. They get the
+		// same 401 as unknown usernames so the response doesn't reveal which accounts exist.
+		failedID := ""
+		var deleted models.User
+		if err := db.Unscoped().Where("username = ? AND deleted_at IS NOT NULL", req.Username).First(&deleted).Error; err == nil {
+			failedID = deleted.ID
 		}
+		recordAuthEvent(c, failedID, models.EventLoginFailure)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
+		return
+	}
 
-		token, err := auth.GenerateToken(user.ID, user.Username)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
-			return
-		}
+	// Too many recent failures → locked until the oldest of them ages out
+	lockedUntil, err := loginLockedUntil(db, user.Username, time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check login attempts"})
+		return
+	}
+	if !lockedUntil.IsZero() {
+		recordAuthEvent(c, user.ID, models.EventLoginFailure)
+		retryAfter := time.Until(lockedUntil)
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+		c.JSON(http.StatusLocked, gin.H{"error": "Account is temporarily locked after too many failed login attempts"})
+		return
+	}
 
-		c.JSON(http.StatusOK, LoginResponse{
-			Token:    token,
-			UserID:   user.ID,
-			Username: user.Username,
-			Message:  "Login successful",
-		})
+	// Username exists → verify password (bcrypt compare)
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
+		recordLoginAttempt(db, user.Username, c.ClientIP(), false)
+		recordAuthEvent(c, user.ID, models.EventLoginFailure)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
 		return
 	}
+	recordLoginAttempt(db, user.Username, c.ClientIP(), true)
+	recordAuthEvent(c, user.ID, models.EventLoginSuccess)
 
-	// Username not found → create new user with bcrypt-hashed FE password
-	hashed, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	cacheUser(user)
+
+	token, err := auth.GenerateToken(user.ID, user.Username, user.Role)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process password"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
 		return
 	}
 
-    newUser := models.User{
-        ID:       uuid.NewString(),
-		Username: req.Username,
-		Password: string(hashed),
+	c.JSON(http.StatusOK, LoginResponse{
+		Token:    token,
+		UserID:   user.ID,
+		Username: user.Username,
+		Message:  "Login successful",
+	})
+}
+
+// IntrospectRequest is the token introspection payload (JSON or form-encoded)
+type IntrospectRequest struct {
+	Token string `json:"token" form:"token" binding:"required"`
+}
+
+// IntrospectToken handles POST /api/introspect (admin only)
+// Lets resource servers validate a token without holding the signing secret. Always answers 200
+// with an RFC 7662 style body; invalid or expired tokens yield {"active": false}.
+func IntrospectToken(c *gin.Context) {
+	if !requireAdmin(c) {
+		return
 	}
 
-	if err := db.Create(&newUser).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user"})
+	var req IntrospectRequest
+	var err error
+	if c.ContentType() == gin.MIMEPOSTForm {
+		err = c.ShouldBind(&req)
+	} else {
+		err = bindJSON(c, &req)
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	token, err := auth.GenerateToken(newUser.ID, newUser.Username)
+	c.JSON(http.StatusOK, auth.Introspect(req.Token))
+}
+
+// Refresh handles POST /api/refresh
+// Exchanges a still-valid Bearer token for a new one with a fresh 24-hour window, so sessions
+// can be extended without logging in again. The new token becomes usable after auth.RefreshTokenDelay.
+// Expired, revoked or deactivated-account tokens get 401.
+func Refresh(c *gin.Context) {
+	tokenString := ""
+	if parts := strings.Split(c.GetHeader("Authorization"), " "); len(parts) == 2 && parts[0] == "Bearer" {
+		tokenString = parts[1]
+	}
+	if tokenString == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization token is required"})
+		return
+	}
+
+	claims, err := auth.ValidateToken(tokenString)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+		return
+	}
+	// Deactivated (soft-deleted) accounts cannot extend their sessions
+	var user models.User
+	if err := requestDB(c).Where("id = ?", claims.UserID).First(&user).Error; err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+		return
+	}
+
+	token, err := auth.RefreshToken(tokenString)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
 		return
 	}
 
 	c.JSON(http.StatusOK, LoginResponse{
 		Token:    token,
-		UserID:   newUser.ID,
-		Username: newUser.Username,
-		Message:  "Signup & login successful",
+		UserID:   user.ID,
+		Username: user.Username,
+		Message:  "Token refreshed",
 	})
 }
+
+// Logout handles POST /api/logout
+// Revokes the token used for this request, so it is rejected from the next request on.
+func Logout(c *gin.Context) {
+	if err := auth.RevokeToken(c.GetString("token")); err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+		return
+	}
+	recordAuthEvent(c, c.GetString("user_id"), models.EventLogout)
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out"})
+}
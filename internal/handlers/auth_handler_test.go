@@ -5,37 +5,289 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"testing"
+	"time"
 
+	"task-management-api/internal/auth"
 	"task-management-api/internal/database"
+	"task-management-api/internal/middleware"
+	"task-management-api/internal/models"
 	"task-management-api/internal/testutil"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/require"
 )
 
-func TestLogin_CreatesUserIfNotExists(t *testing.T) {
+func TestRegister_CreatesUserAndLogsIn(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	db, err := testutil.NewInMemoryDB()
 	require.NoError(t, err)
 	database.DB = db
 
 	r := gin.New()
+	r.POST("/api/register", Register)
 	r.POST("/api/login", Login)
+	post := func(path string, payload map[string]string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(payload)
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
 
-	body, _ := json.Marshal(map[string]string{
-		"username": "newuser",
-		"password": "sha256-from-fe",
-	})
+	// Login no longer signs up unknown usernames
+	require.Equal(t, http.StatusUnauthorized, post("/api/login", map[string]string{"username": "newuser", "password": "sha256-from-fe"}).Code)
 
-	req := httptest.NewRequest(http.MethodPost, "/api/login", bytes.NewReader(body))
-	req.Header.Set("Content-Type", "application/json")
-	w := httptest.NewRecorder()
+	w := post("/api/register", map[string]string{"username": "newuser", "password": "sha256-from-fe", "email": "new@example.com"})
+	require.Equal(t, http.StatusCreated, w.Code)
+	var resp LoginResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.NotEmpty(t, resp.Token)
+	require.NotEmpty(t, resp.UserID)
+	require.Equal(t, "newuser", resp.Username)
+
+	var user models.User
+	require.NoError(t, db.First(&user, "id = ?", resp.UserID).Error)
+	require.Equal(t, "new@example.com", user.Email)
+	require.NotEqual(t, "sha256-from-fe", user.Password)
+
+	require.Equal(t, http.StatusOK, post("/api/login", map[string]string{"username": "newuser", "password": "sha256-from-fe"}).Code)
+	require.Equal(t, http.StatusUnauthorized, post("/api/login", map[string]string{"username": "newuser", "password": "typo"}).Code)
+}
+
+func TestRegister_DuplicateUsernameAndMissingEmail(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := testutil.NewInMemoryDB()
+	require.NoError(t, err)
+	database.DB = db
+	require.NoError(t, db.Create(&models.User{ID: "u-1", Username: "alice", Password: "x"}).Error)
 
-	r.ServeHTTP(w, req)
+	r := gin.New()
+	r.POST("/api/register", Register)
+	register := func(payload map[string]string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(payload)
+		req := httptest.NewRequest(http.MethodPost, "/api/register", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	require.Equal(t, http.StatusConflict, register(map[string]string{"username": "alice", "password": "hash", "email": "alice@example.com"}).Code)
+
+	// Deactivated accounts keep their username
+	require.NoError(t, db.Create(&models.User{ID: "u-2", Username: "gone", Password: "x"}).Error)
+	require.NoError(t, db.Delete(&models.User{ID: "u-2"}).Error)
+	require.Equal(t, http.StatusConflict, register(map[string]string{"username": "gone", "password": "hash", "email": "gone@example.com"}).Code)
+
+	require.Equal(t, http.StatusBadRequest, register(map[string]string{"username": "bob", "password": "hash"}).Code)
+	require.Equal(t, http.StatusBadRequest, register(map[string]string{"username": "bob", "password": "hash", "email": "not-an-email"}).Code)
+
+	var count int64
+	require.NoError(t, db.Model(&models.User{}).Where("username = ?", "bob").Count(&count).Error)
+	require.Zero(t, count)
+}
+
+func TestIntrospectToken_ActiveAndExpired(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var role string
+	r := gin.New()
+	r.POST("/api/introspect", func(c *gin.Context) {
+		c.Set("user_id", "u-admin")
+		c.Set("role", role)
+	}, IntrospectToken)
+	introspect := func(token string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(map[string]string{"token": token})
+		req := httptest.NewRequest(http.MethodPost, "/api/introspect", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	active, err := auth.GenerateToken("u-1", "alice", models.RoleMember)
+	require.NoError(t, err)
+	// Valid from 25h ago with a 24h lifetime, so expired an hour ago
+	expired, err := auth.GenerateTokenWithDelay("u-1", "alice", models.RoleMember, time.Now().Add(-25*time.Hour))
+	require.NoError(t, err)
+
+	require.Equal(t, http.StatusForbidden, introspect(active).Code)
+	role = models.RoleAdmin
+
+	w := introspect(active)
 	require.Equal(t, http.StatusOK, w.Code)
+	var got map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+	require.Equal(t, true, got["active"])
+	require.Equal(t, "u-1", got["sub"])
+	require.Equal(t, "alice", got["username"])
+	require.NotEmpty(t, got["iss"])
+	require.NotEmpty(t, got["aud"])
+	require.Greater(t, got["exp"], float64(time.Now().Unix()))
+
+	for _, token := range []string{expired, "not-a-jwt"} {
+		w = introspect(token)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.JSONEq(t, `{"active":false}`, w.Body.String())
+	}
+}
+
+func TestRefresh_FreshAndExpiredTokens(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := testutil.NewInMemoryDB()
+	require.NoError(t, err)
+	database.DB = db
+	require.NoError(t, db.Create(&models.User{ID: "u-1", Username: "alice", Password: "x"}).Error)
+
+	r := gin.New()
+	r.POST("/api/refresh", Refresh)
+	refresh := func(token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/refresh", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
 
-	var resp struct{ Token string }
-	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	fresh, err := auth.GenerateToken("u-1", "alice", models.RoleMember)
+	require.NoError(t, err)
+	w := refresh(fresh)
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp LoginResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
 	require.NotEmpty(t, resp.Token)
+	require.NotEqual(t, fresh, resp.Token)
+	require.Equal(t, "u-1", resp.UserID)
+	require.Equal(t, "alice", resp.Username)
+
+	expired, err := auth.GenerateTokenWithDelay("u-1", "alice", models.RoleMember, time.Now().Add(-25*time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusUnauthorized, refresh(expired).Code)
+
+	// Deactivated accounts cannot refresh
+	require.NoError(t, db.Delete(&models.User{ID: "u-1"}).Error)
+	require.Equal(t, http.StatusUnauthorized, refresh(fresh).Code)
+}
+
+func TestLogout_RevokesTokenImmediately(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := testutil.NewInMemoryDB()
+	require.NoError(t, err)
+	database.DB = db
+
+	r := gin.New()
+	protected := r.Group("/api", middleware.JWTAuthMiddleware())
+	protected.POST("/logout", Logout)
+	protected.GET("/tasks", GetTasks)
+	call := func(method, path, token string) int {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	token, err := auth.GenerateToken("u-1", "alice", models.RoleMember)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, call(http.MethodGet, "/api/tasks", token))
+
+	require.Equal(t, http.StatusOK, call(http.MethodPost, "/api/logout", token))
+	require.Equal(t, http.StatusUnauthorized, call(http.MethodGet, "/api/tasks", token))
+	require.Equal(t, http.StatusUnauthorized, call(http.MethodPost, "/api/logout", token))
+}
+
+func TestLogin_LocksAccountAfterRepeatedFailures(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := testutil.NewInMemoryDB()
+	require.NoError(t, err)
+	database.DB = db
+	t.Setenv("MAX_LOGIN_ATTEMPTS", "3")
+	t.Setenv("LOCKOUT_DURATION", "10m")
+
+	r := gin.New()
+	r.POST("/api/register", Register)
+	r.POST("/api/login", Login)
+	post := func(path string, payload map[string]string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(payload)
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	login := func(username, password string) *httptest.ResponseRecorder {
+		return post("/api/login", map[string]string{"username": username, "password": password})
+	}
+	for _, name := range []string{"alice", "bob"} {
+		require.Equal(t, http.StatusCreated, post("/api/register", map[string]string{"username": name, "password": "right", "email": name + "@example.com"}).Code)
+	}
+
+	// A success resets the count, so two failures on either side of it don't lock
+	require.Equal(t, http.StatusUnauthorized, login("alice", "wrong").Code)
+	require.Equal(t, http.StatusUnauthorized, login("alice", "wrong").Code)
+	require.Equal(t, http.StatusOK, login("alice", "right").Code)
+	require.Equal(t, http.StatusUnauthorized, login("alice", "wrong").Code)
+	require.Equal(t, http.StatusUnauthorized, login("alice", "wrong").Code)
+	require.Equal(t, http.StatusOK, login("alice", "right").Code)
+
+	// The third consecutive failure locks the account, even for the right password
+	for i := 0; i < 3; i++ {
+		require.Equal(t, http.StatusUnauthorized, login("alice", "wrong").Code)
+	}
+	w := login("alice", "right")
+	require.Equal(t, http.StatusLocked, w.Code)
+	retryAfter, err := strconv.Atoi(w.Header().Get("Retry-After"))
+	require.NoError(t, err)
+	require.InDelta(t, 600, retryAfter, 5)
+
+	// Other accounts are unaffected
+	require.Equal(t, http.StatusOK, login("bob", "right").Code)
+
+	// Once the failures leave the window the account unlocks
+	require.NoError(t, db.Model(&models.LoginAttempt{}).Where("username = ?", "alice").
+		UpdateColumn("attempted_at", time.Now().Add(-11*time.Minute)).Error)
+	require.Equal(t, http.StatusOK, login("alice", "right").Code)
+}
+
+func TestLogin_RecordsAuthEvents(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := testutil.NewInMemoryDB()
+	require.NoError(t, err)
+	database.DB = db
+
+	r := gin.New()
+	r.POST("/api/register", Register)
+	r.POST("/api/login", Login)
+	post := func(path string, payload map[string]string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(payload)
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("User-Agent", "audit-test/1.0")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	w := post("/api/register", map[string]string{"username": "alice", "password": "right", "email": "alice@example.com"})
+	require.Equal(t, http.StatusCreated, w.Code)
+	var reg LoginResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &reg))
+
+	require.Equal(t, http.StatusOK, post("/api/login", map[string]string{"username": "alice", "password": "right"}).Code)
+	require.Equal(t, http.StatusUnauthorized, post("/api/login", map[string]string{"username": "alice", "password": "wrong"}).Code)
+	require.Equal(t, http.StatusUnauthorized, post("/api/login", map[string]string{"username": "nobody", "password": "x"}).Code)
+
+	var events []models.AuditEvent
+	require.NoError(t, db.Order("id asc").Find(&events).Error)
+	require.Len(t, events, 3)
+	require.Equal(t, models.EventLoginSuccess, events[0].EventType)
+	require.Equal(t, reg.UserID, events[0].UserID)
+	require.Equal(t, "audit-test/1.0", events[0].UserAgent)
+	require.NotEmpty(t, events[0].IPAddress)
+	require.Equal(t, models.EventLoginFailure, events[1].EventType)
+	require.Equal(t, reg.UserID, events[1].UserID)
+	require.Equal(t, models.EventLoginFailure, events[2].EventType)
+	require.Empty(t, events[2].UserID)
 }
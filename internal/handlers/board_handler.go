@@ -0,0 +1,204 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"task-management-api/internal/models"
+	"task-management-api/internal/response"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// CreateBoardRequest represents the request payload for creating a board
+type CreateBoardRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+// ReorderColumnsRequest lists every column id of a board in its new order
+type ReorderColumnsRequest struct {
+	ColumnIDs []string `json:"columnIds" binding:"required"`
+}
+
+// defaultBoardStatuses are the columns every new board starts with, in order
+var defaultBoardStatuses = []models.TaskStatus{
+	models.StatusTodo,
+	models.StatusInProgress,
+	models.StatusDone,
+}
+
+// findOwnedBoard loads a board owned by userID with its columns ordered by position
+func findOwnedBoard(c *gin.Context, boardID, userID string) (models.Board, bool) {
+	var board models.Board
+	err := requestDB(c).
+		Preload("Columns", func(db *gorm.DB) *gorm.DB { return db.Order("position asc") }).
+		Where("id = ? AND user_id = ?", boardID, userID).
+		First(&board).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Board not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch board"})
+		}
+		return board, false
+	}
+	return board, true
+}
+
+// CreateBoard handles POST /api/boards
+// Creates a board for the authenticated user with todo, inProgress and done columns
+func CreateBoard(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in token"})
+		return
+	}
+
+	var req CreateBoardRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name is required"})
+		return
+	}
+
+	board := models.Board{
+		ID:     "board-" + uuid.NewString(),
+		Name:   name,
+		UserID: userID,
+	}
+	for i, status := range defaultBoardStatuses {
+		board.Columns = append(board.Columns, models.BoardColumn{
+			ID:       "column-" + uuid.NewString(),
+			BoardID:  board.ID,
+			Status:   status,
+			Position: i,
+		})
+	}
+
+	// Board and its columns are created together
+	if err := requestDB(c).Create(&board).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create board"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, board)
+}
+
+// GetBoardColumns handles GET /api/boards/:boardId/columns
+// Returns the board's columns by position, each with its tasks (matching the column status) by task position
+func GetBoardColumns(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in token"})
+		return
+	}
+
+	board, ok := findOwnedBoard(c, c.Param("boardId"), userID)
+	if !ok {
+		return
+	}
+
+	statuses := make([]models.TaskStatus, 0, len(board.Columns))
+	for _, col := range board.Columns {
+		statuses = append(statuses, col.Status)
+	}
+
+	// Team-wide, non-archived tasks, like GetTasks
+	var tasks []models.Task
+	if err := requestDB(c).
+		Scopes(models.NotArchived).
+		Where("status IN ?", statuses).
+		Order("position asc, created_at asc").
+		Find(&tasks).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tasks"})
+		return
+	}
+
+	// Enrich assignee field for response
+	enrichAssignees(requestDB(c), tasks)
+
+	tasksByStatus := make(map[models.TaskStatus][]models.Task)
+	for _, t := range tasks {
+		tasksByStatus[t.Status] = append(tasksByStatus[t.Status], t)
+	}
+
+	role := c.GetString("role")
+	columns := make([]gin.H, 0, len(board.Columns))
+	for _, col := range board.Columns {
+		columns = append(columns, gin.H{
+			"id":       col.ID,
+			"status":   col.Status,
+			"position": col.Position,
+			"tasks":    response.TasksView(tasksByStatus[col.Status], role),
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"boardId": board.ID,
+		"name":    board.Name,
+		"columns": columns,
+	})
+}
+
+// ReorderBoardColumns handles PATCH /api/boards/:boardId/columns/reorder
+// Accepts every column id of the board in the desired order and rewrites their positions
+func ReorderBoardColumns(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in token"})
+		return
+	}
+
+	var req ReorderColumnsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	board, ok := findOwnedBoard(c, c.Param("boardId"), userID)
+	if !ok {
+		return
+	}
+
+	// The new order must be a permutation of the board's columns
+	known := make(map[string]bool, len(board.Columns))
+	for _, col := range board.Columns {
+		known[col.ID] = true
+	}
+	if len(req.ColumnIDs) != len(known) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "columnIds must list every column of the board exactly once"})
+		return
+	}
+	for _, id := range req.ColumnIDs {
+		if !known[id] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "columnIds must list every column of the board exactly once"})
+			return
+		}
+		delete(known, id)
+	}
+
+	err := requestDB(c).Transaction(func(tx *gorm.DB) error {
+		for pos, id := range req.ColumnIDs {
+			if err := tx.Model(&models.BoardColumn{}).Where("id = ?", id).Update("position", pos).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reorder columns"})
+		return
+	}
+
+	board, ok = findOwnedBoard(c, board.ID, userID)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, board)
+}
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"task-management-api/internal/auth"
+	"task-management-api/internal/database"
+	"task-management-api/internal/middleware"
+	"task-management-api/internal/models"
+	"task-management-api/internal/testutil"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBoardColumns_GroupsTasksByColumn(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := testutil.NewInMemoryDB()
+	require.NoError(t, err)
+	database.DB = db
+
+	r := gin.New()
+	r.Use(middleware.JWTAuthMiddleware())
+	r.POST("/api/boards", CreateBoard)
+	r.GET("/api/boards/:boardId/columns", GetBoardColumns)
+	r.PATCH("/api/boards/:boardId/columns/reorder", ReorderBoardColumns)
+	token, err := auth.GenerateToken("u-1", "alice", models.RoleMember)
+	require.NoError(t, err)
+
+	do := func(method, url string, payload any) *httptest.ResponseRecorder {
+		var body bytes.Buffer
+		if payload != nil {
+			require.NoError(t, json.NewEncoder(&body).Encode(payload))
+		}
+		req := httptest.NewRequest(method, url, &body)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do(http.MethodPost, "/api/boards", map[string]string{"name": "Sprint"})
+	require.Equal(t, http.StatusCreated, w.Code)
+	var board models.Board
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &board))
+	require.Len(t, board.Columns, 3)
+
+	seed := []models.Task{
+		{ID: "task-b", Status: models.StatusTodo, Position: 2},
+		{ID: "task-a", Status: models.StatusTodo, Position: 1},
+		{ID: "task-c", Status: models.StatusDone, Position: 0},
+		{ID: "task-archived", Status: models.StatusTodo, Position: 0},
+	}
+	for _, task := range seed {
+		task.Title = task.ID
+		task.TaskType = models.TypeStory
+		task.UserID = "u-1"
+		require.NoError(t, db.Create(&task).Error)
+	}
+	require.NoError(t, db.Model(&models.Task{}).Where("id = ?", "task-archived").Update("archived_at", time.Now()).Error)
+
+	type columnsResp struct {
+		Columns []struct {
+			ID     string            `json:"id"`
+			Status models.TaskStatus `json:"status"`
+			Tasks  []models.Task     `json:"tasks"`
+		} `json:"columns"`
+	}
+	w = do(http.MethodGet, "/api/boards/"+board.ID+"/columns", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var cols columnsResp
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &cols))
+	require.Len(t, cols.Columns, 3)
+	require.Equal(t, models.StatusTodo, cols.Columns[0].Status)
+	require.Len(t, cols.Columns[0].Tasks, 2)
+	require.Equal(t, "task-a", cols.Columns[0].Tasks[0].ID) // ordered by task position
+	require.Equal(t, "task-b", cols.Columns[0].Tasks[1].ID)
+	require.Empty(t, cols.Columns[1].Tasks)
+	require.Equal(t, "task-c", cols.Columns[2].Tasks[0].ID)
+
+	// Reverse the column order
+	ids := []string{cols.Columns[2].ID, cols.Columns[1].ID, cols.Columns[0].ID}
+	require.Equal(t, http.StatusBadRequest, do(http.MethodPatch, "/api/boards/"+board.ID+"/columns/reorder", map[string]any{"columnIds": ids[:2]}).Code)
+	require.Equal(t, http.StatusOK, do(http.MethodPatch, "/api/boards/"+board.ID+"/columns/reorder", map[string]any{"columnIds": ids}).Code)
+
+	w = do(http.MethodGet, "/api/boards/"+board.ID+"/columns", nil)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &cols))
+	require.Equal(t, models.StatusDone, cols.Columns[0].Status)
+	require.Equal(t, models.StatusTodo, cols.Columns[2].Status)
+
+	// Other users cannot see the board
+	other, err := auth.GenerateToken("u-2", "bob", models.RoleMember)
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodGet, "/api/boards/"+board.ID+"/columns", nil)
+	req.Header.Set("Authorization", "Bearer "+other)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusNotFound, w.Code)
+}
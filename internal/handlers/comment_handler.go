@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"task-management-api/internal/apperr"
+	"task-management-api/internal/models"
+	"task-management-api/internal/realtime"
+	"task-management-api/internal/response"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// CreateCommentRequest represents the request payload for commenting on a task
+type CreateCommentRequest struct {
+	Body string `json:"body" binding:"required"`
+}
+
+// commentView builds the JSON object for a comment, mapping ids like task responses do
+func commentView(cm models.Comment) gin.H {
+	return gin.H{
+		"id":        cm.ID,
+		"taskId":    response.ExternalID(cm.TaskID),
+		"authorId":  response.ExternalID(cm.UserID),
+		"body":      cm.Body,
+		"createdAt": cm.CreatedAt,
+		"updatedAt": cm.UpdatedAt,
+	}
+}
+
+// CreateComment handles POST /api/tasks/:id/comments
+// Adds a comment by the caller to any live task; tasks are discussed team-wide, like they are listed.
+// The body is trimmed and limited to MAX_DESCRIPTION_LENGTH characters. The task owner gets a
+// task_comment_added event.
+func CreateComment(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in token"})
+		return
+	}
+
+	taskID := response.InternalTaskID(c.Param("id"))
+	if taskID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Task ID is required"})
+		return
+	}
+
+	var req CreateCommentRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	body := strings.TrimSpace(req.Body)
+	if body == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Comment body must not be blank"})
+		return
+	}
+	if limit := maxDescriptionLength(); utf8.RuneCountInString(body) > limit {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Comment body must be at most %d characters", limit)})
+		return
+	}
+
+	task, err := findTask(requestDB(c), taskID)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	comment := models.Comment{
+		ID:     "comment-" + uuid.NewString(),
+		TaskID: task.ID,
+		UserID: userID,
+		Body:   body,
+	}
+	if err := requestDB(c).Create(&comment).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create comment"})
+		return
+	}
+
+	evt := map[string]any{
+		"type":      "task_comment_added",
+		"taskId":    response.ExternalID(task.ID),
+		"commentId": comment.ID,
+		"userId":    response.ExternalID(userID),
+		"version":   1,
+	}
+	if bytes, err := json.Marshal(evt); err == nil {
+		realtime.GetHub().Broadcast(task.UserID, bytes)
+	}
+
+	c.JSON(http.StatusCreated, commentView(comment))
+}
+
+// GetComments handles GET /api/tasks/:id/comments
+// Lists a task's comments oldest first. Supports page and limit like GetTasks.
+func GetComments(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in token"})
+		return
+	}
+
+	taskID := response.InternalTaskID(c.Param("id"))
+	if taskID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Task ID is required"})
+		return
+	}
+	if _, err := findTask(requestDB(c), taskID); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	page, limit, offset := parsePagination(c)
+	var total int64
+	var comments []models.Comment
+	err := requestDB(c).Transaction(func(tx *gorm.DB) error {
+		query := tx.Model(&models.Comment{}).Where("task_id = ?", taskID)
+		if err := query.Count(&total).Error; err != nil {
+			return err
+		}
+		return query.Order("created_at asc, id asc").Limit(limit).Offset(offset).Find(&comments).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch comments"})
+		return
+	}
+
+	views := make([]gin.H, 0, len(comments))
+	for _, cm := range comments {
+		views = append(views, commentView(cm))
+	}
+	resp := paginationMeta(total, page, limit)
+	resp["comments"] = views
+	resp["count"] = len(views)
+	c.JSON(http.StatusOK, resp)
+}
+
+// DeleteComment handles DELETE /api/tasks/:id/comments/:commentId
+// Only the comment's author may delete it; others get 403.
+func DeleteComment(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in token"})
+		return
+	}
+
+	taskID := response.InternalTaskID(c.Param("id"))
+	commentID := c.Param("commentId")
+	var comment models.Comment
+	if err := requestDB(c).Where("id = ? AND task_id = ?", commentID, taskID).First(&comment).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			_ = c.Error(apperr.NotFoundError{Resource: "Comment", ID: commentID})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete comment"})
+		return
+	}
+	if comment.UserID != userID {
+		_ = c.Error(apperr.ForbiddenError{})
+		return
+	}
+
+	if err := requestDB(c).Delete(&comment).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete comment"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Comment deleted successfully", "id": comment.ID})
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"task-management-api/internal/auth"
+	"task-management-api/internal/database"
+	"task-management-api/internal/middleware"
+	"task-management-api/internal/models"
+	"task-management-api/internal/realtime"
+	"task-management-api/internal/testutil"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComments_CreateListDelete(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := testutil.NewInMemoryDB()
+	require.NoError(t, err)
+	database.DB = db
+	require.NoError(t, db.Create(&models.Task{ID: "task-1", Title: "T", TaskType: models.TypeStory, UserID: "u-owner"}).Error)
+
+	owner := &recordingClient{}
+	realtime.GetHub().Register("u-owner", owner)
+	defer realtime.GetHub().Unregister("u-owner", owner)
+
+	r := gin.New()
+	r.Use(middleware.ErrorHandler())
+	r.Use(middleware.JWTAuthMiddleware())
+	r.POST("/api/tasks/:id/comments", CreateComment)
+	r.GET("/api/tasks/:id/comments", GetComments)
+	r.DELETE("/api/tasks/:id/comments/:commentId", DeleteComment)
+	call := func(userID, method, path, body string) *httptest.ResponseRecorder {
+		token, err := auth.GenerateToken(userID, userID, models.RoleMember)
+		require.NoError(t, err)
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	// Teammates may comment on a task they don't own
+	w := call("u-alice", http.MethodPost, "/api/tasks/task-1/comments", `{"body":"  Looks good  "}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var first map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &first))
+	require.Equal(t, "Looks good", first["body"])
+	require.Equal(t, "u-alice", first["authorId"])
+	require.Equal(t, "task-1", first["taskId"])
+	require.Equal(t, http.StatusCreated, call("u-bob", http.MethodPost, "/api/tasks/task-1/comments", `{"body":"Agreed"}`).Code)
+
+	events := owner.events(t)
+	require.Len(t, events, 2)
+	require.Equal(t, "task_comment_added", events[0]["type"])
+	require.Equal(t, first["id"], events[0]["commentId"])
+
+	require.Equal(t, http.StatusNotFound, call("u-alice", http.MethodPost, "/api/tasks/task-missing/comments", `{"body":"Hello?"}`).Code)
+	require.Equal(t, http.StatusBadRequest, call("u-alice", http.MethodPost, "/api/tasks/task-1/comments", `{"body":"   "}`).Code)
+
+	// Oldest first, paginated
+	w = call("u-owner", http.MethodGet, "/api/tasks/task-1/comments?limit=1&page=2", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var list struct {
+		Comments []map[string]any `json:"comments"`
+		Total    int64            `json:"total"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	require.Equal(t, int64(2), list.Total)
+	require.Len(t, list.Comments, 1)
+	require.Equal(t, "Agreed", list.Comments[0]["body"])
+	require.Equal(t, http.StatusNotFound, call("u-owner", http.MethodGet, "/api/tasks/task-missing/comments", "").Code)
+
+	// Only the author may delete
+	path := "/api/tasks/task-1/comments/" + first["id"].(string)
+	require.Equal(t, http.StatusForbidden, call("u-owner", http.MethodDelete, path, "").Code)
+	require.Equal(t, http.StatusOK, call("u-alice", http.MethodDelete, path, "").Code)
+	require.Equal(t, http.StatusNotFound, call("u-alice", http.MethodDelete, path, "").Code)
+
+	var remaining int64
+	require.NoError(t, db.Model(&models.Comment{}).Count(&remaining).Error)
+	require.Equal(t, int64(1), remaining)
+}
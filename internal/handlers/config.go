@@ -0,0 +1,216 @@
+package handlers
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"task-management-api/internal/models"
+	"time"
+)
+
+// defaultSortDirection returns the created_at sort direction used when the request has no sort param.
+// Configured via DEFAULT_SORT (asc|desc); defaults to desc (newest first).
+func defaultSortDirection() string {
+	if v := strings.ToLower(strings.TrimSpace(os.Getenv("DEFAULT_SORT"))); v != "" {
+		return v
+	}
+	return "desc"
+}
+
+// defaultTaskStatus is the status given to new tasks that don't specify one.
+// Configured via DEFAULT_TASK_STATUS; defaults to todo.
+func defaultTaskStatus() models.TaskStatus {
+	if v := strings.TrimSpace(os.Getenv("DEFAULT_TASK_STATUS")); v != "" {
+		return models.TaskStatus(v)
+	}
+	return models.StatusTodo
+}
+
+// defaultTaskPriority is the priority given to new tasks that don't specify one.
+// Configured via DEFAULT_TASK_PRIORITY; defaults to medium.
+func defaultTaskPriority() models.TaskPriority {
+	if v := strings.ToLower(strings.TrimSpace(os.Getenv("DEFAULT_TASK_PRIORITY"))); v != "" {
+		return models.TaskPriority(v)
+	}
+	return models.PriorityMedium
+}
+
+// blockSubtaskOnDoneStory reports whether creating subtasks/defects under a done story is rejected.
+// Enabled with BLOCK_SUBTASK_ON_DONE_STORY=true; off by default.
+func blockSubtaskOnDoneStory() bool {
+	return strings.EqualFold(os.Getenv("BLOCK_SUBTASK_ON_DONE_STORY"), "true")
+}
+
+// strictJSON reports whether request bodies with unknown fields are rejected.
+// Enabled with STRICT_JSON=true; lenient binding is the default.
+func strictJSON() bool {
+	return strings.EqualFold(os.Getenv("STRICT_JSON"), "true")
+}
+
+// defaultDeleteGracePeriod is how long soft-deleted tasks stay recoverable when DELETE_GRACE_PERIOD is unset
+const defaultDeleteGracePeriod = 7 * 24 * time.Hour
+
+// deleteGracePeriod returns how long a deleted task is kept before the purge job hard-deletes it.
+// Configured via DELETE_GRACE_PERIOD as a Go duration (e.g. 72h); defaults to 7 days.
+func deleteGracePeriod() time.Duration {
+	if d, err := time.ParseDuration(strings.TrimSpace(os.Getenv("DELETE_GRACE_PERIOD"))); err == nil && d >= 0 {
+		return d
+	}
+	return defaultDeleteGracePeriod
+}
+
+// Login lockout defaults used when MAX_LOGIN_ATTEMPTS / LOCKOUT_DURATION are unset
+const (
+	defaultMaxLoginAttempts = 5
+	defaultLockoutDuration  = 15 * time.Minute
+)
+
+// maxLoginAttempts is how many consecutive failed logins lock an account (MAX_LOGIN_ATTEMPTS, default 5).
+func maxLoginAttempts() int {
+	return positiveIntEnv("MAX_LOGIN_ATTEMPTS", defaultMaxLoginAttempts)
+}
+
+// lockoutDuration is how far back failed logins are counted, and so how long a lockout lasts.
+// Configured via LOCKOUT_DURATION as a Go duration (e.g. 30m); defaults to 15 minutes.
+func lockoutDuration() time.Duration {
+	if d, err := time.ParseDuration(strings.TrimSpace(os.Getenv("LOCKOUT_DURATION"))); err == nil && d > 0 {
+		return d
+	}
+	return defaultLockoutDuration
+}
+
+// adminUsername is the user PromoteAdmin grants the admin role at startup (ADMIN_USERNAME, unset by default)
+func adminUsername() string {
+	return strings.TrimSpace(os.Getenv("ADMIN_USERNAME"))
+}
+
+// scoreWeightEnv lists the env vars that override the priority score weights
+var scoreWeightEnv = []string{
+	"SCORE_WEIGHT_HIGH", "SCORE_WEIGHT_MEDIUM", "SCORE_WEIGHT_LOW",
+	"SCORE_WEIGHT_AGE", "SCORE_WEIGHT_OVERDUE", "SCORE_WEIGHT_EFFORT",
+}
+
+// nonNegativeFloatEnv returns the non-negative number in env var key, or fallback when unset or invalid.
+func nonNegativeFloatEnv(key string, fallback float64) float64 {
+	if f, err := strconv.ParseFloat(strings.TrimSpace(os.Getenv(key)), 64); err == nil && f >= 0 {
+		return f
+	}
+	return fallback
+}
+
+// scoreWeights returns the weights for the computed priorityScore (see models.Task.Score).
+// Each default can be overridden via the matching SCORE_WEIGHT_* env var.
+func scoreWeights() models.ScoreWeights {
+	w := models.DefaultScoreWeights()
+	for key, p := range map[string]models.TaskPriority{
+		"SCORE_WEIGHT_HIGH":   models.PriorityHigh,
+		"SCORE_WEIGHT_MEDIUM": models.PriorityMedium,
+		"SCORE_WEIGHT_LOW":    models.PriorityLow,
+	} {
+		w.Priority[p] = nonNegativeFloatEnv(key, w.Priority[p])
+	}
+	w.Age = nonNegativeFloatEnv("SCORE_WEIGHT_AGE", w.Age)
+	w.Overdue = nonNegativeFloatEnv("SCORE_WEIGHT_OVERDUE", w.Overdue)
+	w.Effort = nonNegativeFloatEnv("SCORE_WEIGHT_EFFORT", w.Effort)
+	return w
+}
+
+// defaultStatsCacheTTL is how long per-user stats are cached when STATS_CACHE_TTL is unset
+const defaultStatsCacheTTL = 30 * time.Second
+
+// statsCacheTTL returns how long GetStatsByUser results are cached.
+// Configured via STATS_CACHE_TTL as a Go duration (e.g. 1m); 0 disables caching. Defaults to 30s.
+func statsCacheTTL() time.Duration {
+	if d, err := time.ParseDuration(strings.TrimSpace(os.Getenv("STATS_CACHE_TTL"))); err == nil && d >= 0 {
+		return d
+	}
+	return defaultStatsCacheTTL
+}
+
+// Default text limits, in characters (runes)
+const (
+	defaultMaxTitleLength       = 200
+	defaultMaxDescriptionLength = 5000
+)
+
+// positiveIntEnv returns the positive integer in env var key, or fallback when unset or invalid.
+func positiveIntEnv(key string, fallback int) int {
+	if n, err := strconv.Atoi(strings.TrimSpace(os.Getenv(key))); err == nil && n > 0 {
+		return n
+	}
+	return fallback
+}
+
+// maxTitleLength is the maximum task title length in characters (MAX_TITLE_LENGTH, default 200).
+func maxTitleLength() int {
+	return positiveIntEnv("MAX_TITLE_LENGTH", defaultMaxTitleLength)
+}
+
+// maxDescriptionLength is the maximum task description length in characters (MAX_DESCRIPTION_LENGTH, default 5000).
+func maxDescriptionLength() int {
+	return positiveIntEnv("MAX_DESCRIPTION_LENGTH", defaultMaxDescriptionLength)
+}
+
+// User enrichment modes for USER_ENRICHMENT
+const (
+	// enrichEager keeps every user in a warmed in-memory cache
+	enrichEager = "eager"
+	// enrichLazy queries only the users referenced by the current response
+	enrichLazy = "lazy"
+)
+
+// userEnrichmentMode returns how assignee/creator users are loaded for task responses.
+// Configured via USER_ENRICHMENT (eager|lazy); defaults to eager. Lazy suits very large user tables.
+func userEnrichmentMode() string {
+	if v := strings.ToLower(strings.TrimSpace(os.Getenv("USER_ENRICHMENT"))); v != "" {
+		return v
+	}
+	return enrichEager
+}
+
+// ValidateConfig checks the handler-related environment variables; call it once at startup.
+func ValidateConfig() error {
+	if sort := defaultSortDirection(); sort != "asc" && sort != "desc" {
+		return fmt.Errorf("DEFAULT_SORT must be asc or desc, got %q", os.Getenv("DEFAULT_SORT"))
+	}
+	for _, key := range []string{"MAX_TITLE_LENGTH", "MAX_DESCRIPTION_LENGTH", "MAX_LOGIN_ATTEMPTS"} {
+		if raw := strings.TrimSpace(os.Getenv(key)); raw != "" {
+			if n, err := strconv.Atoi(raw); err != nil || n <= 0 {
+				return fmt.Errorf("%s must be a positive integer, got %q", key, raw)
+			}
+		}
+	}
+	if status := defaultTaskStatus(); !status.IsValid() {
+		return fmt.Errorf("DEFAULT_TASK_STATUS must be todo, inProgress or done, got %q", os.Getenv("DEFAULT_TASK_STATUS"))
+	}
+	if priority := defaultTaskPriority(); !priority.IsValid() {
+		return fmt.Errorf("DEFAULT_TASK_PRIORITY must be high, medium or low, got %q", os.Getenv("DEFAULT_TASK_PRIORITY"))
+	}
+	if mode := userEnrichmentMode(); mode != enrichEager && mode != enrichLazy {
+		return fmt.Errorf("USER_ENRICHMENT must be eager or lazy, got %q", os.Getenv("USER_ENRICHMENT"))
+	}
+	if raw := strings.TrimSpace(os.Getenv("DELETE_GRACE_PERIOD")); raw != "" {
+		if d, err := time.ParseDuration(raw); err != nil || d < 0 {
+			return fmt.Errorf("DELETE_GRACE_PERIOD must be a non-negative duration such as 72h, got %q", raw)
+		}
+	}
+	for _, key := range scoreWeightEnv {
+		if raw := strings.TrimSpace(os.Getenv(key)); raw != "" {
+			if f, err := strconv.ParseFloat(raw, 64); err != nil || f < 0 {
+				return fmt.Errorf("%s must be a non-negative number, got %q", key, raw)
+			}
+		}
+	}
+	if raw := strings.TrimSpace(os.Getenv("STATS_CACHE_TTL")); raw != "" {
+		if d, err := time.ParseDuration(raw); err != nil || d < 0 {
+			return fmt.Errorf("STATS_CACHE_TTL must be a non-negative duration such as 30s, got %q", raw)
+		}
+	}
+	if raw := strings.TrimSpace(os.Getenv("LOCKOUT_DURATION")); raw != "" {
+		if d, err := time.ParseDuration(raw); err != nil || d <= 0 {
+			return fmt.Errorf("LOCKOUT_DURATION must be a positive duration such as 15m, got %q", raw)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,14 @@
+package handlers
+
+import (
+	"task-management-api/internal/database"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// requestDB returns the shared connection bound to the request context,
+// so queries are logged with the request's correlation ID
+func requestDB(c *gin.Context) *gorm.DB {
+	return database.GetDB().WithContext(c.Request.Context())
+}
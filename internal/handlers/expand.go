@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"task-management-api/internal/models"
+	"task-management-api/internal/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// expandableRelations are the task relations ?expand= can embed as full user objects
+var expandableRelations = []string{"assignee", "creator"}
+
+// parseExpand reads the comma-separated ?expand= param.
+// Unknown relations get a 400 and ok=false; callers should just return.
+func parseExpand(c *gin.Context) (relations []string, ok bool) {
+	raw := strings.TrimSpace(c.Query("expand"))
+	if raw == "" {
+		return nil, true
+	}
+	for _, rel := range strings.Split(raw, ",") {
+		rel = strings.TrimSpace(rel)
+		if !slices.Contains(expandableRelations, rel) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": fmt.Sprintf("Unknown expand value %q; allowed: %s", rel, strings.Join(expandableRelations, ", ")),
+			})
+			return nil, false
+		}
+		if !slices.Contains(relations, rel) {
+			relations = append(relations, rel)
+		}
+	}
+	return relations, true
+}
+
+// expandTaskViews embeds the requested relations of tasks[i] into views[i] under "expanded".
+// A relation whose user is unknown (e.g. unassigned) is null.
+func expandTaskViews(views []map[string]any, tasks []models.Task, relations []string, userByID map[string]models.User, role string) {
+	if len(relations) == 0 {
+		return
+	}
+	for i, task := range tasks {
+		expanded := make(map[string]any, len(relations))
+		for _, rel := range relations {
+			id := task.AssigneeID
+			if rel == "creator" {
+				id = task.UserID
+			}
+			if u, ok := userByID[id]; ok {
+				expanded[rel] = response.UserView(u, role)
+			} else {
+				expanded[rel] = nil
+			}
+		}
+		views[i]["expanded"] = expanded
+	}
+}
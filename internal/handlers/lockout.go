@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"task-management-api/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// loginLockedUntil reports when username's lockout ends, or the zero time when it is not locked.
+// An account is locked once it has MAX_LOGIN_ATTEMPTS failures within LOCKOUT_DURATION and
+// no successful login since; it unlocks when the oldest of those failures leaves the window.
+func loginLockedUntil(db *gorm.DB, username string, now time.Time) (time.Time, error) {
+	limit := maxLoginAttempts()
+	since := now.Add(-lockoutDuration())
+
+	// A successful login resets the count
+	var lastSuccess models.LoginAttempt
+	err := db.Where("username = ? AND succeeded = ? AND attempted_at > ?", username, true, since).
+		Order("attempted_at desc").Limit(1).Find(&lastSuccess).Error
+	if err != nil {
+		return time.Time{}, err
+	}
+	if lastSuccess.ID != 0 {
+		since = lastSuccess.AttemptedAt
+	}
+
+	var failures []models.LoginAttempt
+	err = db.Where("username = ? AND succeeded = ? AND attempted_at > ?", username, false, since).
+		Order("attempted_at desc").Limit(limit).Find(&failures).Error
+	if err != nil {
+		return time.Time{}, err
+	}
+	if len(failures) < limit {
+		return time.Time{}, nil
+	}
+	return failures[limit-1].AttemptedAt.Add(lockoutDuration()), nil
+}
+
+// clearLoginFailures forgets username's failed logins, lifting any lockout (e.g. after an admin
+// password reset)
+func clearLoginFailures(db *gorm.DB, username string) error {
+	return db.Where("username = ? AND succeeded = ?", username, false).Delete(&models.LoginAttempt{}).Error
+}
+
+// recordLoginAttempt stores the outcome of a password check; failures to write are ignored
+// so they never block a login
+func recordLoginAttempt(db *gorm.DB, username, ip string, succeeded bool) {
+	_ = db.Create(&models.LoginAttempt{
+		Username:    username,
+		IPAddress:   ip,
+		AttemptedAt: time.Now(),
+		Succeeded:   succeeded,
+	}).Error
+}
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"net/http"
+	"task-management-api/internal/models"
+	"task-management-api/internal/response"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// orphanedTasksQuery selects subtasks/defects whose project_id is empty or does not reference a story.
+// Stories in the trash still count as parents, since RestoreTask can bring them back.
+func orphanedTasksQuery(db *gorm.DB) *gorm.DB {
+	stories := db.Unscoped().Model(&models.Task{}).Select("id").Where("task_type = ?", models.TypeStory)
+	return db.Model(&models.Task{}).
+		Where("task_type IN ?", []models.TaskType{models.TypeSubtask, models.TypeDefect}).
+		Where("project_id IS NULL OR project_id = '' OR project_id NOT IN (?)", stories)
+}
+
+// GetOrphanedTasks handles GET /api/maintenance/orphans (admin only)
+// Lists subtasks/defects whose parent story is missing or not a story. It never changes data;
+// see DetachOrphanedTasks to fix them.
+func GetOrphanedTasks(c *gin.Context) {
+	if !requireAdmin(c) {
+		return
+	}
+
+	var orphans []models.Task
+	if err := orphanedTasksQuery(requestDB(c)).Order("created_at asc").Find(&orphans).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to find orphaned tasks"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"orphans": response.TasksView(orphans, c.GetString("role")),
+		"count":   len(orphans),
+	})
+}
+
+// DetachOrphanedTasks handles POST /api/maintenance/orphans/detach (admin only)
+// Converts every orphaned subtask/defect (see GetOrphanedTasks) into a story with an empty
+// projectId, and returns the detached tasks as they are afterwards.
+func DetachOrphanedTasks(c *gin.Context) {
+	if !requireAdmin(c) {
+		return
+	}
+
+	var detached []models.Task
+	err := requestDB(c).Transaction(func(tx *gorm.DB) error {
+		var ids []string
+		if err := orphanedTasksQuery(tx).Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+		if err := tx.Model(&models.Task{}).Where("id IN ?", ids).Updates(map[string]any{
+			"project_id": "",
+			"task_type":  models.TypeStory,
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id IN ?", ids).Order("created_at asc").Find(&detached).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to detach orphaned tasks"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"detached": response.TasksView(detached, c.GetString("role")),
+		"count":    len(detached),
+	})
+}
@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"task-management-api/internal/auth"
+	"task-management-api/internal/database"
+	"task-management-api/internal/middleware"
+	"task-management-api/internal/models"
+	"task-management-api/internal/testutil"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetOrphanedTasks(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := testutil.NewInMemoryDB()
+	require.NoError(t, err)
+	database.DB = db
+
+	require.NoError(t, db.Create(&models.Task{ID: "task-story", Title: "Story", TaskType: models.TypeStory, UserID: "u-1"}).Error)
+	require.NoError(t, db.Create(&models.Task{ID: "task-ok", Title: "Linked", TaskType: models.TypeSubtask, ProjectID: "task-story", UserID: "u-1"}).Error)
+	require.NoError(t, db.Create(&models.Task{ID: "task-orphan", Title: "Orphan", TaskType: models.TypeDefect, ProjectID: "task-missing", UserID: "u-1"}).Error)
+	// Children of a story in the trash are not orphans: restoring the story brings them back
+	trashed := models.Task{ID: "task-trashed", Title: "Trashed", TaskType: models.TypeStory, UserID: "u-1"}
+	require.NoError(t, db.Create(&trashed).Error)
+	require.NoError(t, db.Create(&models.Task{ID: "task-waiting", Title: "Waiting", TaskType: models.TypeSubtask, ProjectID: "task-trashed", UserID: "u-1"}).Error)
+	require.NoError(t, db.Delete(&trashed).Error)
+
+	token, err := auth.GenerateToken("u-1", "alice", models.RoleMember)
+	require.NoError(t, err)
+
+	newRouter := func(role string) *gin.Engine {
+		r := gin.New()
+		r.Use(middleware.JWTAuthMiddleware())
+		r.Use(func(c *gin.Context) { c.Set("role", role) })
+		r.GET("/api/maintenance/orphans", GetOrphanedTasks)
+		r.POST("/api/maintenance/orphans/detach", DetachOrphanedTasks)
+		return r
+	}
+	call := func(r *gin.Engine, method, url string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, url, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	// Members are forbidden
+	require.Equal(t, http.StatusForbidden, call(newRouter(models.RoleMember), http.MethodGet, "/api/maintenance/orphans").Code)
+
+	admin := newRouter(models.RoleAdmin)
+	w := call(admin, http.MethodGet, "/api/maintenance/orphans")
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp struct {
+		Orphans []models.Task `json:"orphans"`
+		Count   int           `json:"count"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Equal(t, 1, resp.Count)
+	require.Equal(t, "task-orphan", resp.Orphans[0].ID)
+
+	// Listing never changes data
+	require.Equal(t, http.StatusOK, call(admin, http.MethodGet, "/api/maintenance/orphans?fix=detach").Code)
+	var untouched models.Task
+	require.NoError(t, db.First(&untouched, "id = ?", "task-orphan").Error)
+	require.Equal(t, models.TypeDefect, untouched.TaskType)
+
+	// Detach converts the orphan into a standalone story and returns it as it is now
+	require.Equal(t, http.StatusForbidden, call(newRouter(models.RoleMember), http.MethodPost, "/api/maintenance/orphans/detach").Code)
+	w = call(admin, http.MethodPost, "/api/maintenance/orphans/detach")
+	require.Equal(t, http.StatusOK, w.Code)
+	var detachResp struct {
+		Detached []map[string]any `json:"detached"`
+		Count    int              `json:"count"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &detachResp))
+	require.Equal(t, 1, detachResp.Count)
+	require.Equal(t, "task-orphan", detachResp.Detached[0]["id"])
+	require.Equal(t, string(models.TypeStory), detachResp.Detached[0]["taskType"])
+	var fixed models.Task
+	require.NoError(t, db.First(&fixed, "id = ?", "task-orphan").Error)
+	require.Equal(t, models.TypeStory, fixed.TaskType)
+	require.Empty(t, fixed.ProjectID)
+
+	w = call(admin, http.MethodGet, "/api/maintenance/orphans")
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Equal(t, 0, resp.Count)
+}
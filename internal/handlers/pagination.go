@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"task-management-api/internal/models"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Pagination defaults and caps shared by list endpoints
+const (
+	defaultPageLimit = 5
+	maxPageLimit     = 100
+)
+
+// parsePagination reads ?page (default 1) and ?limit (default 5, max 100) and derives the offset.
+// Invalid values fall back to the defaults rather than failing the request.
+func parsePagination(c *gin.Context) (page, limit, offset int) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	limit, err = strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultPageLimit)))
+	if err != nil || limit < 1 {
+		limit = defaultPageLimit
+	}
+	if limit > maxPageLimit {
+		limit = maxPageLimit
+	}
+	return page, limit, (page - 1) * limit
+}
+
+// paginationMeta returns the pagination fields merged into list responses
+func paginationMeta(total int64, page, limit int) gin.H {
+	totalPages := 0
+	if limit > 0 {
+		totalPages = int((total + int64(limit) - 1) / int64(limit))
+	}
+	return gin.H{
+		"total":      total, // total items (all pages) for current filter
+		"page":       page,
+		"limit":      limit,
+		"totalPages": totalPages,
+		"hasNext":    page < totalPages,
+	}
+}
+
+// cursorMeta returns the pagination fields for cursor mode; nextCursor is null on the last page
+func cursorMeta(tasks []models.Task, limit int, hasNext bool) gin.H {
+	meta := gin.H{
+		"limit":      limit,
+		"hasNext":    hasNext,
+		"nextCursor": nil,
+	}
+	if hasNext && len(tasks) > 0 {
+		meta["nextCursor"] = encodeTaskCursor(tasks[len(tasks)-1])
+	}
+	return meta
+}
+
+// taskCursor marks a position in a task list ordered by (created_at, id).
+//
+// On the wire it is opaque to clients: unpadded URL-safe base64 of "<createdAt>|<id>", where
+// createdAt is the last returned task's creation time in RFC 3339 with nanoseconds and id its
+// stored task id. The id breaks ties between tasks created in the same instant, so iteration
+// neither skips nor repeats rows when tasks are inserted between pages.
+type taskCursor struct {
+	CreatedAt time.Time
+	ID        string
+}
+
+// errInvalidCursor is returned by decodeTaskCursor for malformed cursors
+var errInvalidCursor = errors.New("invalid cursor")
+
+// encodeTaskCursor returns the cursor pointing just after task
+func encodeTaskCursor(task models.Task) string {
+	raw := task.CreatedAt.Format(time.RFC3339Nano) + "|" + task.ID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeTaskCursor parses a cursor produced by encodeTaskCursor
+func decodeTaskCursor(s string) (taskCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return taskCursor{}, errInvalidCursor
+	}
+	createdAt, id, ok := strings.Cut(string(raw), "|")
+	if !ok || id == "" {
+		return taskCursor{}, errInvalidCursor
+	}
+	t, err := time.Parse(time.RFC3339Nano, createdAt)
+	if err != nil {
+		return taskCursor{}, errInvalidCursor
+	}
+	return taskCursor{CreatedAt: t, ID: id}, nil
+}
+
+// after scopes a query to the rows that come after the cursor in (created_at, id) order,
+// descending when desc is set
+func (cur taskCursor) after(desc bool) func(*gorm.DB) *gorm.DB {
+	op := ">"
+	if desc {
+		op = "<"
+	}
+	return func(query *gorm.DB) *gorm.DB {
+		return query.Where("(created_at "+op+" ? OR (created_at = ? AND id "+op+" ?))", cur.CreatedAt, cur.CreatedAt, cur.ID)
+	}
+}
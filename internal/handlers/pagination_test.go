@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePagination(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cases := []struct {
+		query               string
+		page, limit, offset int
+	}{
+		{"", 1, 5, 0},                       // defaults
+		{"?page=3&limit=10", 3, 10, 20},     // explicit
+		{"?page=0&limit=-4", 1, 5, 0},       // non-positive falls back
+		{"?page=abc&limit=xyz", 1, 5, 0},    // unparseable falls back
+		{"?page=2&limit=1000", 2, 100, 100}, // limit clamped
+	}
+	for _, tc := range cases {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest("GET", "/items"+tc.query, nil)
+		page, limit, offset := parsePagination(c)
+		require.Equal(t, tc.page, page, tc.query)
+		require.Equal(t, tc.limit, limit, tc.query)
+		require.Equal(t, tc.offset, offset, tc.query)
+	}
+}
+
+func TestPaginationMeta(t *testing.T) {
+	meta := paginationMeta(11, 2, 5)
+	require.Equal(t, int64(11), meta["total"])
+	require.Equal(t, 3, meta["totalPages"])
+	require.Equal(t, true, meta["hasNext"])
+
+	meta = paginationMeta(10, 2, 5)
+	require.Equal(t, 2, meta["totalPages"])
+	require.Equal(t, false, meta["hasNext"])
+
+	meta = paginationMeta(0, 1, 5)
+	require.Equal(t, 0, meta["totalPages"])
+	require.Equal(t, false, meta["hasNext"])
+}
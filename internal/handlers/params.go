@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// parseAgeParam reads the positive age query param name as whole days ("14d") or a Go duration
+// ("36h"), falling back to def when absent. On failure it writes a 400 and returns false.
+func parseAgeParam(c *gin.Context, name string, def time.Duration) (time.Duration, bool) {
+	raw := strings.TrimSpace(c.Query(name))
+	if raw == "" {
+		return def, true
+	}
+	var age time.Duration
+	var err error
+	if days, ok := strings.CutSuffix(raw, "d"); ok {
+		var n int
+		n, err = strconv.Atoi(days)
+		age = time.Duration(n) * 24 * time.Hour
+	} else {
+		age, err = time.ParseDuration(raw)
+	}
+	if err != nil || age <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Query parameter %q must be a positive age such as 14d or 36h, got %q", name, raw)})
+		return 0, false
+	}
+	return age, true
+}
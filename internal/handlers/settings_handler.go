@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"task-management-api/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// settingValidators lists the known preference keys and how their JSON value is validated
+var settingValidators = map[string]func(json.RawMessage) error{
+	// pageSize is the preferred list page size
+	"pageSize": func(v json.RawMessage) error {
+		var n int
+		if err := json.Unmarshal(v, &n); err != nil || n < 1 || n > maxPageLimit {
+			return fmt.Errorf("pageSize must be an integer between 1 and %d", maxPageLimit)
+		}
+		return nil
+	},
+	// defaultBoardFilters is a free-form filter object applied when opening a board
+	"defaultBoardFilters": func(v json.RawMessage) error {
+		var filters map[string]any
+		if err := json.Unmarshal(v, &filters); err != nil || filters == nil {
+			return errors.New("defaultBoardFilters must be a JSON object")
+		}
+		return nil
+	},
+	// defaultSort is the preferred created_at sort direction for task lists
+	"defaultSort": func(v json.RawMessage) error {
+		var sort string
+		if err := json.Unmarshal(v, &sort); err != nil || (sort != "asc" && sort != "desc") {
+			return errors.New("defaultSort must be \"asc\" or \"desc\"")
+		}
+		return nil
+	},
+}
+
+// PutSettingRequest carries the JSON value of a preference
+type PutSettingRequest struct {
+	Value json.RawMessage `json:"value" binding:"required"`
+}
+
+// GetMySettings handles GET /api/me/settings
+// Returns the caller's stored preferences as a key -> value object
+func GetMySettings(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in token"})
+		return
+	}
+
+	var rows []models.UserSetting
+	if err := requestDB(c).Where("user_id = ?", userID).Order("key").Find(&rows).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch settings"})
+		return
+	}
+
+	settings := make(map[string]json.RawMessage, len(rows))
+	for _, s := range rows {
+		settings[s.Key] = json.RawMessage(s.Value)
+	}
+	c.JSON(http.StatusOK, gin.H{"settings": settings})
+}
+
+// PutMySetting handles PUT /api/me/settings/:key
+// Creates or replaces one of the caller's preferences; unknown keys and invalid values are rejected
+func PutMySetting(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in token"})
+		return
+	}
+
+	key := c.Param("key")
+	validate, known := settingValidators[key]
+	if !known {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Unknown setting %q", key)})
+		return
+	}
+
+	var req PutSettingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := validate(req.Value); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, req.Value); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var setting models.UserSetting
+	err := requestDB(c).Where("user_id = ? AND key = ?", userID, key).First(&setting).Error
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		setting = models.UserSetting{ID: "setting-" + uuid.NewString(), UserID: userID, Key: key, Value: compact.String()}
+		err = requestDB(c).Create(&setting).Error
+	case err == nil:
+		err = requestDB(c).Model(&setting).Update("value", compact.String()).Error
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save setting"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"key": key, "value": json.RawMessage(compact.String())})
+}
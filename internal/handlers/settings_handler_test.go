@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"task-management-api/internal/auth"
+	"task-management-api/internal/database"
+	"task-management-api/internal/middleware"
+	"task-management-api/internal/models"
+	"task-management-api/internal/testutil"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMySettings_StoreAndRetrieve(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := testutil.NewInMemoryDB()
+	require.NoError(t, err)
+	database.DB = db
+
+	r := gin.New()
+	r.Use(middleware.JWTAuthMiddleware())
+	r.GET("/api/me/settings", GetMySettings)
+	r.PUT("/api/me/settings/:key", PutMySetting)
+
+	do := func(token, method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	alice, err := auth.GenerateToken("u-1", "alice", models.RoleMember)
+	require.NoError(t, err)
+	bob, err := auth.GenerateToken("u-2", "bob", models.RoleMember)
+	require.NoError(t, err)
+
+	require.Equal(t, http.StatusOK, do(alice, http.MethodPut, "/api/me/settings/pageSize", `{"value": 10}`).Code)
+	require.Equal(t, http.StatusOK, do(alice, http.MethodPut, "/api/me/settings/pageSize", `{"value": 25}`).Code)
+	require.Equal(t, http.StatusOK, do(alice, http.MethodPut, "/api/me/settings/defaultBoardFilters", `{"value": {"assignee": "u-1", "priority": ["high"]}}`).Code)
+
+	w := do(alice, http.MethodGet, "/api/me/settings", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp struct {
+		Settings map[string]json.RawMessage `json:"settings"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp.Settings, 2)
+	require.JSONEq(t, `25`, string(resp.Settings["pageSize"]))
+	require.JSONEq(t, `{"assignee":"u-1","priority":["high"]}`, string(resp.Settings["defaultBoardFilters"]))
+
+	// Settings are per user
+	w = do(bob, http.MethodGet, "/api/me/settings", "")
+	require.JSONEq(t, `{"settings":{}}`, w.Body.String())
+}
+
+func TestMySettings_RejectsUnknownKeyAndInvalidValue(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := testutil.NewInMemoryDB()
+	require.NoError(t, err)
+	database.DB = db
+
+	r := gin.New()
+	r.Use(middleware.JWTAuthMiddleware())
+	r.PUT("/api/me/settings/:key", PutMySetting)
+	token, err := auth.GenerateToken("u-1", "alice", models.RoleMember)
+	require.NoError(t, err)
+
+	put := func(key, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPut, "/api/me/settings/"+key, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := put("theme", `{"value": "dark"}`)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Contains(t, w.Body.String(), "Unknown setting")
+
+	require.Equal(t, http.StatusBadRequest, put("pageSize", `{"value": 0}`).Code)
+	require.Equal(t, http.StatusBadRequest, put("defaultSort", `{"value": "sideways"}`).Code)
+
+	var count int64
+	require.NoError(t, db.Table("user_settings").Count(&count).Error)
+	require.Zero(t, count)
+}
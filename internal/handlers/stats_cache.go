@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"task-management-api/internal/cache"
+
+	"gorm.io/gorm"
+)
+
+// statsCache memoizes GetStatsByUser results per assignee for STATS_CACHE_TTL, so polling
+// dashboards don't rerun the grouped query. Task writes invalidate the affected assignees.
+var statsCache = cache.NewSimpleCache[string, statusStats](cache.Options{ConcurrencySafe: true})
+
+func init() {
+	cache.Register(statsCache)
+}
+
+// cachedStatusStats returns the stats for one assignee, from the cache when fresh
+func cachedStatusStats(db *gorm.DB, userID string) (statusStats, error) {
+	if s, ok := statsCache.Get(userID); ok {
+		return s, nil
+	}
+	stats, err := statusStatsByAssignee(db, []string{userID})
+	if err != nil {
+		return statusStats{}, err
+	}
+	s := *stats[userID]
+	if ttl := statsCacheTTL(); ttl > 0 {
+		statsCache.Set(userID, s, ttl)
+	}
+	return s, nil
+}
+
+// invalidateStats drops the cached stats of the given assignees after their tasks changed
+func invalidateStats(assigneeIDs ...string) {
+	for _, id := range assigneeIDs {
+		if id != "" {
+			statsCache.Delete(id)
+		}
+	}
+}
+
+// invalidateAllStats drops every cached entry, for bulk writes whose assignees aren't loaded
+func invalidateAllStats() {
+	statsCache.Clear()
+}
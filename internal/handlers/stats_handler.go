@@ -0,0 +1,377 @@
+package handlers
+
+import (
+	"cmp"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"task-management-api/internal/apperr"
+	"task-management-api/internal/models"
+	"task-management-api/internal/response"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// digestTopContributors is the number of contributors listed in the weekly digest
+const digestTopContributors = 5
+
+// contributor is a user's completed work over a stats window
+type contributor struct {
+	UserID          string `json:"userId"`
+	Username        string `json:"username"`
+	CompletedTasks  int64  `json:"completedTasks"`
+	CompletedEffort int64  `json:"completedEffort"`
+}
+
+// completedByAssignee aggregates tasks completed since the given time per (non-deleted) assignee,
+// ordered by the given SQL order; limit <= 0 returns every contributor.
+// A done task counts as completed at its last update.
+func completedByAssignee(db *gorm.DB, since time.Time, order string, limit int) ([]contributor, error) {
+	query := db.Model(&models.Task{}).
+		Select("tasks.assignee_id as user_id, users.username as username, COUNT(*) as completed_tasks, COALESCE(SUM(tasks.effort), 0) as completed_effort").
+		Joins("JOIN users ON users.id = tasks.assignee_id AND users.deleted_at IS NULL").
+		Where("tasks.status = ? AND tasks.updated_at >= ?", models.StatusDone, since).
+		Group("tasks.assignee_id, users.username").
+		Order(order)
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	contributors := []contributor{}
+	if err := query.Scan(&contributors).Error; err != nil {
+		return nil, err
+	}
+	return contributors, nil
+}
+
+// statusStats counts a user's assigned tasks per status, plus their priority-weighted effort
+type statusStats struct {
+	Todo           int64   `json:"todo"`
+	InProgress     int64   `json:"inProgress"`
+	Done           int64   `json:"done"`
+	Total          int64   `json:"total"`
+	WeightedEffort float64 `json:"weightedEffort"`
+}
+
+// statusStatsByAssignee computes statusStats for each given assignee with a single grouped query.
+// Every requested ID is present in the result, zeroed when the user has no tasks.
+func statusStatsByAssignee(db *gorm.DB, userIDs []string) (map[string]*statusStats, error) {
+	type row struct {
+		AssigneeID     string
+		Status         models.TaskStatus
+		Count          int64
+		WeightedEffort float64
+	}
+	var rows []row
+	if err := db.Model(&models.Task{}).
+		Select("assignee_id, status, COUNT(*) as count, COALESCE(SUM(effort * "+priorityWeightExpr+"), 0) as weighted_effort").
+		Where("assignee_id IN ?", userIDs).
+		Group("assignee_id, status").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	stats := make(map[string]*statusStats, len(userIDs))
+	for _, id := range userIDs {
+		stats[id] = &statusStats{}
+	}
+	for _, r := range rows {
+		s := stats[r.AssigneeID]
+		switch r.Status {
+		case models.StatusTodo:
+			s.Todo = r.Count
+		case models.StatusInProgress:
+			s.InProgress = r.Count
+		case models.StatusDone:
+			s.Done = r.Count
+		}
+		s.Total += r.Count
+		s.WeightedEffort += r.WeightedEffort
+	}
+	return stats, nil
+}
+
+// maxBatchStatsUsers caps the number of users accepted by GetStatsByUsers
+const maxBatchStatsUsers = 100
+
+// StatsByUsersRequest is the payload for POST /api/stats/by-users
+type StatsByUsersRequest struct {
+	UserIDs []string `json:"userIds" binding:"required"`
+}
+
+// GetStatsByUsers handles POST /api/stats/by-users
+// Returns the GetStatsByUser counts for several assignees at once, keyed by user ID.
+func GetStatsByUsers(c *gin.Context) {
+	var req StatsByUsersRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	userIDs := make([]string, 0, len(req.UserIDs))
+	for _, id := range req.UserIDs {
+		if id = strings.TrimSpace(id); id != "" && !slices.Contains(userIDs, id) {
+			userIDs = append(userIDs, id)
+		}
+	}
+	if len(userIDs) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "userIds must contain at least one user ID"})
+		return
+	}
+	if len(userIDs) > maxBatchStatsUsers {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("at most %d userIds are allowed", maxBatchStatsUsers)})
+		return
+	}
+
+	stats, err := statusStatsByAssignee(requestDB(c), userIDs)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute stats"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"stats": stats})
+}
+
+// GetWeeklyDigest handles GET /api/stats/weekly-digest
+// Summarizes the past 7 days team-wide: tasks created, tasks completed, tasks currently overdue,
+// and the top contributors by completed effort. Archived tasks are left out.
+func GetWeeklyDigest(c *gin.Context) {
+	now := time.Now()
+	since := now.AddDate(0, 0, -7)
+	db := requestDB(c)
+
+	var created int64
+	if err := db.Model(&models.Task{}).Scopes(models.NotArchived).Where("created_at >= ?", since).Count(&created).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute digest"})
+		return
+	}
+
+	var completed int64
+	if err := db.Model(&models.Task{}).Scopes(models.NotArchived).
+		Where("status = ? AND updated_at >= ?", models.StatusDone, since).
+		Count(&completed).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute digest"})
+		return
+	}
+
+	// End dates are free-form strings, so overdue-ness is computed in Go
+	var open []models.Task
+	if err := db.Scopes(models.NotArchived).Select("id", "end_date").Where("status <> ?", models.StatusDone).Find(&open).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute digest"})
+		return
+	}
+	overdue := 0
+	for _, t := range open {
+		if deadline, ok := t.Deadline(); ok && deadline.Before(now) {
+			overdue++
+		}
+	}
+
+	contributors, err := completedByAssignee(db.Scopes(models.NotArchived), since, "completed_effort desc, completed_tasks desc", digestTopContributors)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute digest"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"from":            since,
+		"to":              now,
+		"tasksCreated":    created,
+		"tasksCompleted":  completed,
+		"tasksOverdue":    overdue,
+		"topContributors": contributors,
+	})
+}
+
+// leaderboardPeriods maps the accepted ?period= values to their look-back window
+var leaderboardPeriods = map[string]time.Duration{
+	"day":   24 * time.Hour,
+	"week":  7 * 24 * time.Hour,
+	"month": 30 * 24 * time.Hour,
+}
+
+// GetLeaderboard handles GET /api/stats/leaderboard?period=week
+// Ranks assignees by tasks completed within the period (day, week or month; default week),
+// then by completed effort and username. Deleted users are excluded.
+func GetLeaderboard(c *gin.Context) {
+	period := c.DefaultQuery("period", "week")
+	window, ok := leaderboardPeriods[period]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "period must be one of day, week, month"})
+		return
+	}
+	now := time.Now()
+	since := now.Add(-window)
+
+	ranking, err := completedByAssignee(requestDB(c), since, "completed_tasks desc, completed_effort desc, users.username asc", 0)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute leaderboard"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"period":      period,
+		"from":        since,
+		"to":          now,
+		"leaderboard": ranking,
+	})
+}
+
+// GetEffortDistribution handles GET /api/stats/effort-distribution?groupBy=assignee
+// Returns total effort (days) per status team-wide; with groupBy=assignee also the per-assignee
+// breakdown for stacked-bar charts. Unassigned tasks are reported under an empty assigneeId.
+func GetEffortDistribution(c *gin.Context) {
+	db := requestDB(c)
+
+	type statusEffort struct {
+		Status models.TaskStatus
+		Effort int64
+	}
+	var rows []statusEffort
+	if err := db.Model(&models.Task{}).
+		Select("status, COALESCE(SUM(effort), 0) as effort").
+		Group("status").
+		Scan(&rows).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute effort distribution"})
+		return
+	}
+
+	byStatus := emptyStatusEffort()
+	var total int64
+	for _, r := range rows {
+		byStatus[r.Status] = r.Effort
+		total += r.Effort
+	}
+	resp := gin.H{
+		"byStatus": byStatus,
+		"total":    total,
+	}
+
+	if c.Query("groupBy") == "assignee" {
+		type assigneeStatusEffort struct {
+			AssigneeID string
+			Username   string
+			Status     models.TaskStatus
+			Effort     int64
+		}
+		var cells []assigneeStatusEffort
+		if err := db.Model(&models.Task{}).
+			Select("tasks.assignee_id, COALESCE(users.username, '') as username, tasks.status, COALESCE(SUM(tasks.effort), 0) as effort").
+			Joins("LEFT JOIN users ON users.id = tasks.assignee_id").
+			Group("tasks.assignee_id, users.username, tasks.status").
+			Order("tasks.assignee_id").
+			Scan(&cells).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute effort distribution"})
+			return
+		}
+
+		type assigneeEffort struct {
+			AssigneeID string                      `json:"assigneeId"`
+			Username   string                      `json:"username"`
+			ByStatus   map[models.TaskStatus]int64 `json:"byStatus"`
+			Total      int64                       `json:"total"`
+		}
+		byAssignee := []*assigneeEffort{}
+		index := map[string]*assigneeEffort{}
+		for _, cell := range cells {
+			entry, ok := index[cell.AssigneeID]
+			if !ok {
+				entry = &assigneeEffort{AssigneeID: cell.AssigneeID, Username: cell.Username, ByStatus: emptyStatusEffort()}
+				index[cell.AssigneeID] = entry
+				byAssignee = append(byAssignee, entry)
+			}
+			entry.ByStatus[cell.Status] = cell.Effort
+			entry.Total += cell.Effort
+		}
+		resp["byAssignee"] = byAssignee
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// assigneeWorkload is one assignee's row in the workload summary
+type assigneeWorkload struct {
+	AssigneeID     string                      `json:"assigneeId"`
+	Username       string                      `json:"username"`
+	OpenEffort     int64                       `json:"openEffort"`     // effort of tasks not done
+	WeightedEffort float64                     `json:"weightedEffort"` // OpenEffort weighted by priority, like GetUserWorkload
+	ByStatus       map[models.TaskStatus]int64 `json:"byStatus"`       // task counts
+	TotalTasks     int64                       `json:"totalTasks"`
+}
+
+// GetWorkloadSummary handles GET /api/stats/workload?projectId=
+// Returns, per assignee, the effort of their open (not done) tasks (raw and priority-weighted) and
+// their task counts by status, heaviest-loaded first. projectId scopes the summary to one story and its subtasks/defects.
+// Unassigned tasks are left out.
+func GetWorkloadSummary(c *gin.Context) {
+	db := requestDB(c)
+
+	query := db.Model(&models.Task{}).Where("tasks.assignee_id <> ''")
+	if projectID := response.InternalTaskID(strings.TrimSpace(c.Query("projectId"))); projectID != "" {
+		if _, err := findParentStory(db, projectID); err != nil {
+			if isInvalidParent(err) {
+				_ = c.Error(apperr.NotFoundError{Resource: "Story", ID: projectID})
+			} else {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute workload"})
+			}
+			return
+		}
+		query = query.Where("(tasks.id = ? OR tasks.project_id = ?)", projectID, projectID)
+	}
+
+	type cell struct {
+		AssigneeID     string
+		Username       string
+		Status         models.TaskStatus
+		Count          int64
+		Effort         int64
+		WeightedEffort float64
+	}
+	var cells []cell
+	if err := query.
+		Select("tasks.assignee_id, COALESCE(users.username, '') as username, tasks.status, COUNT(*) as count, COALESCE(SUM(tasks.effort), 0) as effort, " +
+			"COALESCE(SUM(tasks.effort * " + priorityWeightExpr + "), 0) as weighted_effort").
+		Joins("LEFT JOIN users ON users.id = tasks.assignee_id").
+		Group("tasks.assignee_id, users.username, tasks.status").
+		Scan(&cells).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute workload"})
+		return
+	}
+
+	workloads := []*assigneeWorkload{}
+	index := map[string]*assigneeWorkload{}
+	for _, cell := range cells {
+		entry, ok := index[cell.AssigneeID]
+		if !ok {
+			entry = &assigneeWorkload{AssigneeID: cell.AssigneeID, Username: cell.Username, ByStatus: emptyStatusEffort()}
+			index[cell.AssigneeID] = entry
+			workloads = append(workloads, entry)
+		}
+		entry.ByStatus[cell.Status] += cell.Count
+		entry.TotalTasks += cell.Count
+		if cell.Status != models.StatusDone {
+			entry.OpenEffort += cell.Effort
+			entry.WeightedEffort += cell.WeightedEffort
+		}
+	}
+	slices.SortFunc(workloads, func(a, b *assigneeWorkload) int {
+		if a.OpenEffort != b.OpenEffort {
+			return cmp.Compare(b.OpenEffort, a.OpenEffort)
+		}
+		return strings.Compare(a.AssigneeID, b.AssigneeID)
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"assignees": workloads,
+		"count":     len(workloads),
+	})
+}
+
+// emptyStatusEffort returns a zeroed effort-by-status map so every status is always present
+func emptyStatusEffort() map[models.TaskStatus]int64 {
+	return map[models.TaskStatus]int64{
+		models.StatusTodo:       0,
+		models.StatusInProgress: 0,
+		models.StatusDone:       0,
+	}
+}
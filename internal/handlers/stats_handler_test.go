@@ -0,0 +1,430 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"task-management-api/internal/auth"
+	"task-management-api/internal/database"
+	"task-management-api/internal/middleware"
+	"task-management-api/internal/models"
+	"task-management-api/internal/testutil"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetWeeklyDigest(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := testutil.NewInMemoryDB()
+	require.NoError(t, err)
+	database.DB = db
+
+	require.NoError(t, db.Create(&models.User{ID: "u-1", Username: "alice", Password: "x"}).Error)
+	require.NoError(t, db.Create(&models.User{ID: "u-2", Username: "bob", Password: "x"}).Error)
+
+	now := time.Now()
+	day := 24 * time.Hour
+	past := now.AddDate(0, 0, -3).Format("2006-01-02")
+	future := now.AddDate(0, 0, 10).Format("2006-01-02")
+	seed := []struct {
+		task models.Task
+		at   time.Time // created_at and updated_at
+	}{
+		{models.Task{ID: "task-1", Status: models.StatusDone, AssigneeID: "u-1", Effort: 2, EndDate: past}, now.Add(-1 * day)},
+		{models.Task{ID: "task-2", Status: models.StatusDone, AssigneeID: "u-2", Effort: 5, EndDate: past}, now.Add(-2 * day)},
+		{models.Task{ID: "task-3", Status: models.StatusTodo, AssigneeID: "u-1", Effort: 1, EndDate: past}, now.Add(-3 * day)},     // overdue
+		{models.Task{ID: "task-4", Status: models.StatusInProgress, AssigneeID: "u-2", Effort: 1, EndDate: future}, now.Add(-day)}, // on track
+		{models.Task{ID: "task-5", Status: models.StatusDone, AssigneeID: "u-1", Effort: 8, EndDate: past}, now.Add(-20 * day)},    // outside window
+		{models.Task{ID: "task-6", Status: models.StatusTodo, AssigneeID: "u-2", Effort: 1, EndDate: past}, now.Add(-30 * day)},    // old but overdue
+		// Archived tasks count nowhere
+		{models.Task{ID: "task-7", Status: models.StatusDone, AssigneeID: "u-1", Effort: 50, EndDate: past, ArchivedAt: &now}, now.Add(-day)},
+		{models.Task{ID: "task-8", Status: models.StatusTodo, AssigneeID: "u-1", Effort: 1, EndDate: past, ArchivedAt: &now}, now.Add(-day)},
+	}
+	for _, s := range seed {
+		s.task.Title = s.task.ID
+		s.task.TaskType = models.TypeStory
+		s.task.UserID = "u-1"
+		s.task.CreatedAt = s.at
+		s.task.UpdatedAt = s.at
+		require.NoError(t, db.Create(&s.task).Error)
+	}
+
+	r := gin.New()
+	r.GET("/api/stats/weekly-digest", GetWeeklyDigest)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/stats/weekly-digest", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var digest struct {
+		TasksCreated    int64 `json:"tasksCreated"`
+		TasksCompleted  int64 `json:"tasksCompleted"`
+		TasksOverdue    int   `json:"tasksOverdue"`
+		TopContributors []struct {
+			UserID          string `json:"userId"`
+			Username        string `json:"username"`
+			CompletedEffort int64  `json:"completedEffort"`
+		} `json:"topContributors"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &digest))
+	require.Equal(t, int64(4), digest.TasksCreated)
+	require.Equal(t, int64(2), digest.TasksCompleted)
+	require.Equal(t, 2, digest.TasksOverdue)
+	require.Len(t, digest.TopContributors, 2)
+	require.Equal(t, "bob", digest.TopContributors[0].Username)
+	require.Equal(t, int64(5), digest.TopContributors[0].CompletedEffort)
+	require.Equal(t, "u-1", digest.TopContributors[1].UserID)
+}
+
+func TestGetEffortDistribution(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := testutil.NewInMemoryDB()
+	require.NoError(t, err)
+	database.DB = db
+
+	require.NoError(t, db.Create(&models.User{ID: "u-1", Username: "alice", Password: "x"}).Error)
+	require.NoError(t, db.Create(&models.User{ID: "u-2", Username: "bob", Password: "x"}).Error)
+
+	seed := []models.Task{
+		{ID: "task-1", Status: models.StatusTodo, AssigneeID: "u-1", Effort: 3},
+		{ID: "task-2", Status: models.StatusTodo, AssigneeID: "u-2", Effort: 2},
+		{ID: "task-3", Status: models.StatusInProgress, AssigneeID: "u-1", Effort: 5},
+		{ID: "task-4", Status: models.StatusDone, AssigneeID: "u-2", Effort: 8},
+		{ID: "task-5", Status: models.StatusDone, AssigneeID: "u-2", Effort: 1},
+	}
+	for _, task := range seed {
+		task.Title = task.ID
+		task.TaskType = models.TypeStory
+		task.UserID = "u-1"
+		require.NoError(t, db.Create(&task).Error)
+	}
+
+	r := gin.New()
+	r.GET("/api/stats/effort-distribution", GetEffortDistribution)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/stats/effort-distribution?groupBy=assignee", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp struct {
+		ByStatus   map[string]int64 `json:"byStatus"`
+		Total      int64            `json:"total"`
+		ByAssignee []struct {
+			AssigneeID string           `json:"assigneeId"`
+			Username   string           `json:"username"`
+			ByStatus   map[string]int64 `json:"byStatus"`
+			Total      int64            `json:"total"`
+		} `json:"byAssignee"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Equal(t, map[string]int64{"todo": 5, "inProgress": 5, "done": 9}, resp.ByStatus)
+	require.Equal(t, int64(19), resp.Total)
+
+	require.Len(t, resp.ByAssignee, 2)
+	require.Equal(t, "alice", resp.ByAssignee[0].Username)
+	require.Equal(t, map[string]int64{"todo": 3, "inProgress": 5, "done": 0}, resp.ByAssignee[0].ByStatus)
+	require.Equal(t, int64(8), resp.ByAssignee[0].Total)
+	require.Equal(t, map[string]int64{"todo": 2, "inProgress": 0, "done": 9}, resp.ByAssignee[1].ByStatus)
+	require.Equal(t, int64(11), resp.ByAssignee[1].Total)
+}
+
+func TestGetLeaderboard_RanksByCompletedTasks(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := testutil.NewInMemoryDB()
+	require.NoError(t, err)
+	database.DB = db
+
+	for _, u := range []models.User{
+		{ID: "u-1", Username: "alice", Password: "x"},
+		{ID: "u-2", Username: "bob", Password: "x"},
+		{ID: "u-3", Username: "carol", Password: "x"},
+		{ID: "u-4", Username: "dave", Password: "x"},
+	} {
+		require.NoError(t, db.Create(&u).Error)
+	}
+
+	now := time.Now()
+	seed := []struct {
+		assignee string
+		status   models.TaskStatus
+		effort   int
+		at       time.Time
+	}{
+		{"u-1", models.StatusDone, 1, now.Add(-time.Hour)},
+		{"u-2", models.StatusDone, 3, now.Add(-time.Hour)},
+		{"u-2", models.StatusDone, 2, now.AddDate(0, 0, -2)},
+		{"u-3", models.StatusDone, 5, now.AddDate(0, 0, -3)},
+		{"u-1", models.StatusDone, 9, now.AddDate(0, 0, -20)},    // outside the week
+		{"u-1", models.StatusInProgress, 9, now.Add(-time.Hour)}, // not completed
+		{"u-4", models.StatusDone, 4, now.Add(-time.Hour)},       // deleted below
+	}
+	for i, s := range seed {
+		task := models.Task{ID: fmt.Sprintf("task-%d", i), Title: "T", TaskType: models.TypeStory, UserID: "u-1",
+			AssigneeID: s.assignee, Status: s.status, Effort: s.effort}
+		task.CreatedAt, task.UpdatedAt = s.at, s.at
+		require.NoError(t, db.Create(&task).Error)
+	}
+	require.NoError(t, db.Delete(&models.User{ID: "u-4"}).Error)
+
+	r := gin.New()
+	r.GET("/api/stats/leaderboard", GetLeaderboard)
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	w := get("/api/stats/leaderboard?period=week")
+	require.Equal(t, http.StatusOK, w.Code)
+	var body struct {
+		Leaderboard []struct {
+			Username        string `json:"username"`
+			CompletedTasks  int64  `json:"completedTasks"`
+			CompletedEffort int64  `json:"completedEffort"`
+		} `json:"leaderboard"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	require.Len(t, body.Leaderboard, 3)
+	// bob has the most completions; carol beats alice on effort at one completion each
+	require.Equal(t, "bob", body.Leaderboard[0].Username)
+	require.Equal(t, int64(2), body.Leaderboard[0].CompletedTasks)
+	require.Equal(t, int64(5), body.Leaderboard[0].CompletedEffort)
+	require.Equal(t, "carol", body.Leaderboard[1].Username)
+	require.Equal(t, "alice", body.Leaderboard[2].Username)
+	require.Equal(t, int64(1), body.Leaderboard[2].CompletedEffort)
+
+	require.Equal(t, http.StatusBadRequest, get("/api/stats/leaderboard?period=year").Code)
+}
+
+func TestGetStatsByUsers_MatchesPerUserStats(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := testutil.NewInMemoryDB()
+	require.NoError(t, err)
+	database.DB = db
+
+	seed := []struct {
+		assignee string
+		status   models.TaskStatus
+		priority models.TaskPriority
+	}{
+		{"u-1", models.StatusTodo, models.PriorityHigh},
+		{"u-1", models.StatusTodo, models.PriorityLow},
+		{"u-1", models.StatusDone, models.PriorityMedium},
+		{"u-2", models.StatusInProgress, models.PriorityHigh},
+		{"u-3", models.StatusDone, models.PriorityLow}, // not requested
+	}
+	for i, s := range seed {
+		require.NoError(t, db.Create(&models.Task{ID: fmt.Sprintf("task-%d", i), Title: "T", TaskType: models.TypeStory,
+			UserID: "u-1", AssigneeID: s.assignee, Status: s.status, Priority: s.priority, Effort: 2}).Error)
+	}
+
+	r := gin.New()
+	r.Use(func(c *gin.Context) { c.Set("user_id", "u-1") })
+	r.GET("/api/stats/:userid", GetStatsByUser)
+	r.POST("/api/stats/by-users", GetStatsByUsers)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/stats/by-users", strings.NewReader(`{"userIds":["u-1","u-2","u-9","u-1"]}`)))
+	require.Equal(t, http.StatusOK, w.Code)
+	var batch struct {
+		Stats map[string]map[string]any `json:"stats"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &batch))
+	require.Len(t, batch.Stats, 3)
+	require.Equal(t, float64(2), batch.Stats["u-1"]["todo"])
+	require.Equal(t, float64(3), batch.Stats["u-1"]["total"])
+	require.Equal(t, float64(1), batch.Stats["u-2"]["inProgress"])
+	require.Equal(t, float64(0), batch.Stats["u-9"]["total"])
+
+	for _, id := range []string{"u-1", "u-2", "u-9"} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/stats/"+id, nil))
+		require.Equal(t, http.StatusOK, w.Code)
+		var single map[string]any
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &single))
+		require.Equal(t, single, batch.Stats[id], id)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/stats/by-users", strings.NewReader(`{"userIds":[]}`)))
+	require.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestGetStatsByUser_CachedUntilTaskChanges(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := testutil.NewInMemoryDB()
+	require.NoError(t, err)
+	database.DB = db
+	invalidateAllStats()
+	t.Cleanup(invalidateAllStats)
+
+	require.NoError(t, db.Create(&models.Task{ID: "task-1", Title: "T", TaskType: models.TypeStory,
+		UserID: "u-1", AssigneeID: "u-5", Status: models.StatusTodo}).Error)
+
+	r := gin.New()
+	r.Use(middleware.JWTAuthMiddleware())
+	r.GET("/api/stats/:userid", GetStatsByUser)
+	r.PATCH("/api/tasks/:id/status", UpdateTaskStatus)
+	token, err := auth.GenerateToken("u-1", "alice", models.RoleMember)
+	require.NoError(t, err)
+	call := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	stats := func() statusStats {
+		w := call(http.MethodGet, "/api/stats/u-5", "")
+		require.Equal(t, http.StatusOK, w.Code)
+		var s statusStats
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &s))
+		return s
+	}
+
+	require.Equal(t, int64(1), stats().Todo)
+
+	// A row written behind the handlers' back is not seen while the entry is cached
+	require.NoError(t, db.Create(&models.Task{ID: "task-2", Title: "T", TaskType: models.TypeStory,
+		UserID: "u-1", AssigneeID: "u-5", Status: models.StatusTodo}).Error)
+	require.Equal(t, int64(1), stats().Todo)
+
+	// Changing one of the user's tasks through the API busts the entry
+	require.Equal(t, http.StatusOK, call(http.MethodPatch, "/api/tasks/task-1/status", `{"status":"inProgress"}`).Code)
+	s := stats()
+	require.Equal(t, int64(1), s.Todo)
+	require.Equal(t, int64(1), s.InProgress)
+	require.Equal(t, int64(2), s.Total)
+
+	// STATS_CACHE_TTL=0 turns caching off
+	invalidateAllStats()
+	t.Setenv("STATS_CACHE_TTL", "0")
+	require.Equal(t, int64(2), stats().Total)
+	require.NoError(t, db.Create(&models.Task{ID: "task-3", Title: "T", TaskType: models.TypeStory,
+		UserID: "u-1", AssigneeID: "u-5", Status: models.StatusDone}).Error)
+	require.Equal(t, int64(3), stats().Total)
+}
+
+func TestGetWorkloadSummary_SortedByOpenEffort(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := testutil.NewInMemoryDB()
+	require.NoError(t, err)
+	database.DB = db
+
+	require.NoError(t, db.Create(&models.User{ID: "u-1", Username: "alice", Password: "x"}).Error)
+	require.NoError(t, db.Create(&models.User{ID: "u-2", Username: "bob", Password: "x"}).Error)
+	for _, task := range []models.Task{
+		{ID: "task-s1", TaskType: models.TypeStory, AssigneeID: "u-1", Status: models.StatusInProgress, Effort: 2},
+		{ID: "task-s1-a", TaskType: models.TypeSubtask, ProjectID: "task-s1", AssigneeID: "u-2", Status: models.StatusTodo, Effort: 3},
+		{ID: "task-s1-b", TaskType: models.TypeSubtask, ProjectID: "task-s1", AssigneeID: "u-2", Status: models.StatusDone, Effort: 8},
+		{ID: "task-s2", TaskType: models.TypeStory, AssigneeID: "u-1", Status: models.StatusTodo, Effort: 5, Priority: models.PriorityHigh},
+		{ID: "task-s2-a", TaskType: models.TypeSubtask, ProjectID: "task-s2", AssigneeID: "u-2", Status: models.StatusTodo, Effort: 1},
+		{ID: "task-free", TaskType: models.TypeStory, Status: models.StatusTodo, Effort: 9}, // unassigned
+	} {
+		task.Title = task.ID
+		task.UserID = "u-1"
+		require.NoError(t, db.Create(&task).Error)
+	}
+
+	r := gin.New()
+	r.Use(middleware.ErrorHandler())
+	r.GET("/api/stats/workload", GetWorkloadSummary)
+	get := func(query string) (int, []assigneeWorkload) {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/stats/workload?"+query, nil))
+		var resp struct {
+			Assignees []assigneeWorkload `json:"assignees"`
+		}
+		if w.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		}
+		return w.Code, resp.Assignees
+	}
+
+	code, rows := get("")
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, rows, 2)
+	require.Equal(t, "u-1", rows[0].AssigneeID)
+	require.Equal(t, "alice", rows[0].Username)
+	require.Equal(t, int64(7), rows[0].OpenEffort)
+	require.Equal(t, 19.0, rows[0].WeightedEffort) // 2 medium (x2) + 5 high (x3)
+	require.Equal(t, "bob", rows[1].Username)
+	require.Equal(t, int64(4), rows[1].OpenEffort) // the done subtask's effort doesn't count
+	require.Equal(t, 8.0, rows[1].WeightedEffort)
+	require.Equal(t, int64(1), rows[1].ByStatus[models.StatusDone])
+	require.Equal(t, int64(2), rows[1].ByStatus[models.StatusTodo])
+	require.Equal(t, int64(3), rows[1].TotalTasks)
+
+	// Scoped to one story's subtree, bob carries more open effort than alice
+	code, rows = get("projectId=task-s1")
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, rows, 2)
+	require.Equal(t, "u-2", rows[0].AssigneeID)
+	require.Equal(t, int64(3), rows[0].OpenEffort)
+	require.Equal(t, int64(2), rows[0].TotalTasks)
+	require.Equal(t, "u-1", rows[1].AssigneeID)
+	require.Equal(t, int64(2), rows[1].OpenEffort)
+
+	code, _ = get("projectId=task-s1-a")
+	require.Equal(t, http.StatusNotFound, code)
+}
+
+func TestGetStatsByUser_ConditionalRequests(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := testutil.NewInMemoryDB()
+	require.NoError(t, err)
+	database.DB = db
+	invalidateAllStats()
+	t.Cleanup(invalidateAllStats)
+
+	task := models.Task{ID: "task-1", Title: "T", TaskType: models.TypeStory,
+		UserID: "u-1", AssigneeID: "u-5", Status: models.StatusTodo}
+	task.UpdatedAt = time.Now().Add(-time.Hour)
+	require.NoError(t, db.Create(&task).Error)
+
+	r := gin.New()
+	r.Use(func(c *gin.Context) { c.Set("user_id", "u-1") })
+	r.GET("/api/stats/:userid", GetStatsByUser)
+	get := func(ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/stats/u-5", nil)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get("")
+	require.Equal(t, http.StatusOK, w.Code)
+	etag := w.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+
+	// Unchanged: 304 with no body, also for weak and listed tags
+	w = get(etag)
+	require.Equal(t, http.StatusNotModified, w.Code)
+	require.Empty(t, w.Body.String())
+	require.Equal(t, http.StatusNotModified, get(`"other", W/`+etag).Code)
+
+	// Changed: the old tag no longer matches
+	require.NoError(t, db.Model(&models.Task{}).Where("id = ?", "task-1").Update("status", models.StatusDone).Error)
+	invalidateStats("u-5")
+	w = get(etag)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NotEqual(t, etag, w.Header().Get("ETag"))
+	var stats map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &stats))
+	require.Equal(t, float64(1), stats["done"])
+
+	// A new task changes the tag as well
+	etag = w.Header().Get("ETag")
+	require.NoError(t, db.Create(&models.Task{ID: "task-2", Title: "T", TaskType: models.TypeStory,
+		UserID: "u-1", AssigneeID: "u-5", Status: models.StatusTodo}).Error)
+	invalidateStats("u-5")
+	require.Equal(t, http.StatusOK, get(etag).Code)
+}
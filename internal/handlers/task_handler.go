@@ -1,23 +1,37 @@
 package handlers
 
 import (
+	"cmp"
+	"crypto/sha256"
+	"database/sql"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"log"
+	"math"
 	"net/http"
+	"os"
+	"slices"
 	"strconv"
 	"strings"
-	"task-management-api/internal/database"
+	"task-management-api/internal/apperr"
+	"task-management-api/internal/jsonapi"
 	"task-management-api/internal/models"
 	"task-management-api/internal/realtime"
+	"task-management-api/internal/response"
 	"time"
+	"unicode/utf8"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // CreateTaskRequest represents the request payload for creating a task
 type CreateTaskRequest struct {
+	// ID is optional; when omitted the server generates one
+	ID          string              `json:"id"`
 	Title       string              `json:"title" binding:"required"`
 	Description string              `json:"description" binding:"required"`
 	Status      models.TaskStatus   `json:"status"`
@@ -28,20 +42,34 @@ type CreateTaskRequest struct {
 	Effort      int                 `json:"effort"`
 	Priority    models.TaskPriority `json:"priority"`
 	TaskType    models.TaskType     `json:"taskType" binding:"required"`
+	// Source is the creating client (web, mobile, import); falls back to the X-Client-Source header
+	Source models.TaskSource `json:"source"`
+	Labels []string          `json:"labels"`
 }
 
+// BulkCreateTasksRequest creates several tasks in one request
+type BulkCreateTasksRequest struct {
+	Tasks []CreateTaskRequest `json:"tasks" binding:"required,min=1"`
+}
+
+// maxBulkCreateTasks caps the number of tasks a single bulk create may insert
+const maxBulkCreateTasks = 50
+
 // UpdateTaskRequest represents the request payload for updating a task
 type UpdateTaskRequest struct {
-	Title       *string              `json:"title"`
-	Description *string              `json:"description"`
-	Status      *models.TaskStatus   `json:"status"`
-	ProjectID   *string              `json:"projectId"`
-	Assignee    *models.Assignee     `json:"assignee"`
-	StartDate   *string              `json:"startDate"`
-	EndDate     *string              `json:"endDate"`
-	Effort      *int                 `json:"effort"`
-	Priority    *models.TaskPriority `json:"priority"`
-	TaskType    *models.TaskType     `json:"taskType"`
+	Title        *string              `json:"title"`
+	Description  *string              `json:"description"`
+	Status       *models.TaskStatus   `json:"status"`
+	ProjectID    *string              `json:"projectId"`
+	Assignee     *models.Assignee     `json:"assignee"`
+	StartDate    *string              `json:"startDate"`
+	EndDate      *string              `json:"endDate"`
+	Effort       *int                 `json:"effort"`
+	ActualEffort *int                 `json:"actualEffort"`
+	Priority     *models.TaskPriority `json:"priority"`
+	TaskType     *models.TaskType     `json:"taskType"`
+	Position     *int                 `json:"position"`
+	Labels       *[]string            `json:"labels"` // replaces the whole set; [] clears it
 }
 
 // UpdateTaskStatusRequest represents a minimal request to change status
@@ -49,30 +77,141 @@ type UpdateTaskStatusRequest struct {
 	Status models.TaskStatus `json:"status" binding:"required"`
 }
 
-func parseDateFlexible(dateStr string) (time.Time, bool) {
-	if dateStr == "" {
-		return time.Time{}, false
+// MoveTaskStatusRequest moves every matching task from one status to another
+type MoveTaskStatusRequest struct {
+	From       models.TaskStatus `json:"from" binding:"required"`
+	To         models.TaskStatus `json:"to" binding:"required"`
+	AssigneeID string            `json:"assigneeId"`
+}
+
+// BulkUpdateStatusRequest sets the status of several tasks at once
+type BulkUpdateStatusRequest struct {
+	IDs    []string          `json:"ids" binding:"required,min=1"`
+	Status models.TaskStatus `json:"status" binding:"required"`
+}
+
+// maxBulkStatusIDs caps the number of tasks a single bulk status update may touch
+const maxBulkStatusIDs = 100
+
+// BulkDeleteTasksRequest lists the tasks to delete at once
+type BulkDeleteTasksRequest struct {
+	IDs []string `json:"ids" binding:"required,min=1"`
+}
+
+// maxBulkDeleteIDs caps the number of tasks a single bulk delete may remove
+const maxBulkDeleteIDs = 100
+
+// ValidateStoriesRequest lists candidate parent story ids to check
+type ValidateStoriesRequest struct {
+	IDs []string `json:"ids" binding:"required,min=1"`
+}
+
+// MergeTaskRequest names the task a duplicate is merged into
+type MergeTaskRequest struct {
+	Into string `json:"into" binding:"required"`
+}
+
+// maxValidateStoryIDs caps the number of ids a single story validation may check
+const maxValidateStoryIDs = 100
+
+// ReassignTasksRequest moves tasks from one assignee to another.
+// fromUserId/toUserId are accepted as aliases of fromAssigneeId/toAssigneeId.
+type ReassignTasksRequest struct {
+	FromAssigneeID string              `json:"fromAssigneeId"`
+	ToAssigneeID   string              `json:"toAssigneeId"`
+	FromUserID     string              `json:"fromUserId"`
+	ToUserID       string              `json:"toUserId"`
+	Statuses       []models.TaskStatus `json:"statuses"`
+}
+
+// taskRequestError is a task payload rejected by validation, with the status and body to answer
+type taskRequestError struct {
+	status int
+	body   gin.H
+}
+
+func (e taskRequestError) Error() string {
+	return fmt.Sprint(e.body["error"])
+}
+
+// badTaskRequest returns a taskRequestError answering status with {"error": msg}
+func badTaskRequest(status int, msg string) taskRequestError {
+	return taskRequestError{status: status, body: gin.H{"error": msg}}
+}
+
+// checkTextLimits writes a 400 naming the field and its limit when the title or description
+// is longer than allowed. Lengths are counted in runes so multibyte text is measured correctly.
+func checkTextLimits(c *gin.Context, title, description string) bool {
+	if err := textLimitError(title, description); err != nil {
+		c.JSON(err.status, err.body)
+		return false
+	}
+	return true
+}
+
+// Label limits per task
+const (
+	maxTaskLabels  = 20
+	maxLabelLength = 50
+)
+
+// normalizeLabels trims and lowercases labels, dropping blanks and duplicates, so "Backend " and
+// "backend" are the same label. It returns a 400 taskRequestError when a limit is exceeded.
+func normalizeLabels(raw []string) (models.Labels, *taskRequestError) {
+	labels := models.Labels{}
+	for _, l := range raw {
+		l = strings.ToLower(strings.TrimSpace(l))
+		if l == "" || slices.Contains(labels, l) {
+			continue
+		}
+		if utf8.RuneCountInString(l) > maxLabelLength {
+			return nil, &taskRequestError{status: http.StatusBadRequest, body: gin.H{
+				"error": fmt.Sprintf("labels must be at most %d characters each", maxLabelLength),
+				"field": "labels",
+			}}
+		}
+		labels = append(labels, l)
 	}
-	layouts := []string{
-		"2006-01-02",  // ISO date
-		"2 Jan 2006",  // e.g., 30 Oct 2025
-		time.RFC3339,  // full RFC3339
-		"02 Jan 2006", // zero-padded day
+	if len(labels) > maxTaskLabels {
+		return nil, &taskRequestError{status: http.StatusBadRequest, body: gin.H{
+			"error": fmt.Sprintf("At most %d labels per task", maxTaskLabels),
+			"field": "labels",
+		}}
 	}
-	for _, layout := range layouts {
-		if t, err := time.Parse(layout, dateStr); err == nil {
-			return t, true
+	return labels, nil
+}
+
+// textLimitError is checkTextLimits without writing the response; nil when within the limits
+func textLimitError(title, description string) *taskRequestError {
+	for _, f := range []struct {
+		name  string
+		value string
+		limit int
+	}{
+		{"title", title, maxTitleLength()},
+		{"description", description, maxDescriptionLength()},
+	} {
+		if utf8.RuneCountInString(f.value) > f.limit {
+			return &taskRequestError{status: http.StatusBadRequest, body: gin.H{
+				"error": fmt.Sprintf("%s must be at most %d characters", f.name, f.limit),
+				"field": f.name,
+				"limit": f.limit,
+			}}
 		}
 	}
-	return time.Time{}, false
+	return nil
+}
+
+func parseDateFlexible(dateStr string) (time.Time, bool) {
+	return models.ParseTaskDate(dateStr)
 }
 
 func calculateEffortDays(startDateStr, endDateStr string) int {
 	start, okStart := parseDateFlexible(startDateStr)
 	end, okEnd := parseDateFlexible(endDateStr)
 	if !okStart || !okEnd {
-		// Fallback to minimum effort 1 when dates invalid/missing
-		return 1
+		// Fallback to minimum effort when dates invalid/missing
+		return minEffortDays
 	}
 	// Normalize to midnight to avoid partial-day rounding issues
 	start = time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, start.Location())
@@ -81,17 +220,235 @@ func calculateEffortDays(startDateStr, endDateStr string) int {
 		start, end = end, start
 	}
 	days := int(end.Sub(start).Hours() / 24)
-	if days < 1 {
-		return 1
+	if days < minEffortDays {
+		return minEffortDays
 	}
 	return days
 }
 
+// minEffortDays is the effort of a task whose dates span less than a day (or are missing)
+const minEffortDays = 1
+
+// GetTaskDefaults handles GET /api/tasks/defaults
+// Returns the values the server fills in for fields a new task leaves out (status, priority, and
+// the effort of a task without a date range), including env overrides, so create forms stay in sync.
+func GetTaskDefaults(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"status":   defaultTaskStatus(),
+		"priority": defaultTaskPriority(),
+		"effort":   minEffortDays,
+	})
+}
+
+// realtimeFullPayload reports whether create/update broadcasts should embed the full task.
+// Controlled by REALTIME_FULL_PAYLOAD=true; off by default to keep frames small.
+func realtimeFullPayload() bool {
+	return strings.EqualFold(os.Getenv("REALTIME_FULL_PAYLOAD"), "true")
+}
+
+// Forecast ratio bounds; keeps a few wildly overrun tasks from skewing predictions
+const (
+	minForecastRatio = 0.5
+	maxForecastRatio = 3.0
+)
+
+// priorityWeightExpr is a SQL expression mapping the priority column to models.PriorityWeight
+var priorityWeightExpr = fmt.Sprintf(
+	"(CASE priority WHEN '%s' THEN %g WHEN '%s' THEN %g ELSE %g END)",
+	models.PriorityHigh, models.PriorityWeight(models.PriorityHigh),
+	models.PriorityLow, models.PriorityWeight(models.PriorityLow),
+	models.PriorityWeight(models.PriorityMedium),
+)
+
+// effortOverrunRatio returns the average actual/estimated effort ratio of the user's done tasks,
+// weighted by priority so overruns on high-priority work count more.
+// The second return value is false when the user has no usable history.
+func effortOverrunRatio(userID string, db *gorm.DB) (float64, bool) {
+	var ratio sql.NullFloat64
+	err := db.Model(&models.Task{}).
+		Select("SUM("+priorityWeightExpr+" * CAST(actual_effort AS REAL) / effort) / SUM("+priorityWeightExpr+")").
+		Where("assignee_id = ? AND status = ? AND actual_effort > 0 AND effort > 0", userID, models.StatusDone).
+		Scan(&ratio).Error
+	if err != nil || !ratio.Valid {
+		return 0, false
+	}
+	return ratio.Float64, true
+}
+
+// ForecastEffort adjusts an estimated effort (in days) by the user's historical overrun ratio.
+// Without history the estimate is returned unchanged.
+func ForecastEffort(userID string, estimatedDays int, db *gorm.DB) int {
+	ratio, ok := effortOverrunRatio(userID, db)
+	if !ok {
+		return estimatedDays
+	}
+	return applyOverrunRatio(estimatedDays, ratio)
+}
+
+// applyOverrunRatio scales an estimate by ratio, clamped to [minForecastRatio, maxForecastRatio]
+func applyOverrunRatio(estimatedDays int, ratio float64) int {
+	ratio = math.Max(minForecastRatio, math.Min(maxForecastRatio, ratio))
+	return int(math.Round(float64(estimatedDays) * ratio))
+}
+
+// findOwnedTask loads a task owned by userID; it returns an apperr.NotFoundError when missing
+func findOwnedTask(db *gorm.DB, taskID, userID string) (models.Task, error) {
+	return findTask(db.Where("user_id = ?", userID), taskID)
+}
+
+// findTask loads a task whatever its owner; it returns an apperr.NotFoundError when missing
+func findTask(db *gorm.DB, taskID string) (models.Task, error) {
+	var task models.Task
+	if err := db.Where("id = ?", taskID).First(&task).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return task, apperr.NotFoundError{Resource: "Task", ID: taskID}
+		}
+		return task, fmt.Errorf("fetch task %s: %w", taskID, err)
+	}
+	return task, nil
+}
+
+// Reasons findParentStory rejects a projectId
+var (
+	errParentNotFound = errors.New("parent task not found")
+	errParentNotStory = errors.New("parent task is not a story")
+)
+
+// findParentStory loads the story a subtask/defect references through projectId. It returns
+// errParentNotFound or errParentNotStory when the id cannot be used as a parent.
+func findParentStory(db *gorm.DB, projectID string) (models.Task, error) {
+	var parent models.Task
+	if err := db.Where("id = ?", projectID).First(&parent).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return parent, errParentNotFound
+		}
+		return parent, fmt.Errorf("fetch parent %s: %w", projectID, err)
+	}
+	if parent.TaskType != models.TypeStory {
+		return parent, errParentNotStory
+	}
+	return parent, nil
+}
+
+// isInvalidParent reports whether err from findParentStory means the projectId is unusable
+// (as opposed to a database failure)
+func isInvalidParent(err error) bool {
+	return errors.Is(err, errParentNotFound) || errors.Is(err, errParentNotStory)
+}
+
+// enumFilter parses a comma-separated enum query param (e.g. status=todo,inProgress).
+// It writes a 400 and returns false when any value is not valid.
+func enumFilter[T ~string](c *gin.Context, param string, isValid func(T) bool, allowed string) ([]T, bool) {
+	raw := strings.TrimSpace(c.Query(param))
+	if raw == "" {
+		return nil, true
+	}
+	var values []T
+	for _, part := range strings.Split(raw, ",") {
+		v := T(strings.TrimSpace(part))
+		if !isValid(v) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid %s %q; allowed: %s", param, part, allowed)})
+			return nil, false
+		}
+		values = append(values, v)
+	}
+	return values, true
+}
+
+// taskListFilters parses the task list filters shared by GetTasks and StreamTasks: userId (creator),
+// status, priority, taskType and source (comma-separated values allowed), q (case-insensitive text match on
+// title or description), includeDeleted (admins only) and sort (asc|desc on created_at). It returns them as a scope for a tasks query, plus the order clause;
+// ok is false when an error response was already written.
+func taskListFilters(c *gin.Context) (scope func(*gorm.DB) *gorm.DB, order string, ok bool) {
+	sortParam := strings.ToLower(c.DefaultQuery("sort", defaultSortDirection()))
+	filterUserID := c.Query("userId")         // optional: filter by creator
+	search := strings.TrimSpace(c.Query("q")) // optional: text search; blank is ignored
+	withDeleted, ok := includeDeleted(c)      // optional (admins): include soft-deleted tasks
+	if !ok {
+		return nil, "", false
+	}
+	withArchived := c.Query("archived") == "true"                 // optional: include archived tasks
+	label := strings.ToLower(strings.TrimSpace(c.Query("label"))) // optional: tasks carrying this label
+
+	order = "created_at desc"
+	if sortParam == "asc" {
+		order = "created_at asc"
+	}
+
+	statuses, ok := enumFilter(c, "status", models.TaskStatus.IsValid, "todo, inProgress, done")
+	if !ok {
+		return nil, "", false
+	}
+	priorities, ok := enumFilter(c, "priority", models.TaskPriority.IsValid, "high, medium, low")
+	if !ok {
+		return nil, "", false
+	}
+	taskTypes, ok := enumFilter(c, "taskType", models.TaskType.IsValid, "story, defect, subtask")
+	if !ok {
+		return nil, "", false
+	}
+	sources, ok := enumFilter(c, "source", models.TaskSource.IsValid, "web, mobile, import")
+	if !ok {
+		return nil, "", false
+	}
+
+	// Team-wide by default; each supplied filter narrows the set
+	scope = func(query *gorm.DB) *gorm.DB {
+		if withDeleted {
+			query = query.Unscoped()
+		}
+		if !withArchived {
+			query = query.Scopes(models.NotArchived)
+		}
+		if filterUserID != "" {
+			query = query.Where("user_id = ?", filterUserID)
+		}
+		if len(statuses) > 0 {
+			query = query.Where("status IN ?", statuses)
+		}
+		if len(priorities) > 0 {
+			query = query.Where("priority IN ?", priorities)
+		}
+		if len(taskTypes) > 0 {
+			query = query.Where("task_type IN ?", taskTypes)
+		}
+		if len(sources) > 0 {
+			query = query.Where("source IN ?", sources)
+		}
+		if label != "" {
+			// Labels are a JSON array; the quoted element only matches a whole label
+			quoted, _ := json.Marshal(label)
+			query = query.Where(`labels LIKE ? ESCAPE '\'`, "%"+escapeLike(string(quoted))+"%")
+		}
+		if search != "" {
+			// SQLite's LIKE is case-insensitive for ASCII
+			pattern := "%" + escapeLike(search) + "%"
+			query = query.Where(`(title LIKE ? ESCAPE '\' OR description LIKE ? ESCAPE '\')`, pattern, pattern)
+		}
+		return query
+	}
+	return scope, order, true
+}
+
 /*
 *
 GetTasks handles GET /api/tasks
 Returns all tasks (team-wide) for authenticated users.
-Optional query param: userId to filter tasks created by a specific user.
+Optional query params: userId to filter tasks created by a specific user; status, priority,
+taskType and source to filter on those columns (comma-separated for several values); q to search title and description;
+label to keep tasks carrying that label.
+Admins may pass includeDeleted=true to include soft-deleted tasks (marked with deletedAt).
+Archived tasks are left out unless archived=true is passed.
+expand=assignee,creator embeds the related user objects under "expanded".
+Pagination is by page/limit, or by cursor when ?cursor= is given (empty for the first page): each
+response then carries nextCursor (null on the last page) to pass as the next cursor, and no total.
+Every task carries a computed priorityScore; sortBy=priorityScore orders by it (highest first)
+instead of by creation time (sortBy=createdAt, the default).
+overdue=true keeps only open tasks whose endDate has passed; like priorityScore sorting it is
+evaluated in Go, so it can't be combined with cursor. Both consider at most maxInMemoryCandidates
+matching tasks (the highest-priority ones when sorting by score); the response then carries
+truncated: true.
+stream=true hands the request to StreamTasks, which writes every match as NDJSON.
 */
 func GetTasks(c *gin.Context) {
 	userID := c.GetString("user_id")
@@ -102,83 +459,254 @@ func GetTasks(c *gin.Context) {
 		return
 	}
 
-	// Query params: page (default 1), limit (default 5), sort (asc|desc on created_at, default desc)
-	pageStr := c.DefaultQuery("page", "1")
-	limitStr := c.DefaultQuery("limit", "5")
-	sortParam := strings.ToLower(c.DefaultQuery("sort", "desc"))
-	filterUserID := c.Query("userId") // optional: filter by creator
+	// Streaming mode (?stream=true) is served by StreamTasks
+	if c.Query("stream") == "true" {
+		StreamTasks(c)
+		return
+	}
 
-	page, err := strconv.Atoi(pageStr)
-	if err != nil || page < 1 {
-		page = 1
+	// Query params: page (default 1), limit (default 5), sort (asc|desc on created_at, default DEFAULT_SORT or desc)
+	page, limit, offset := parsePagination(c)
+	sortParam := strings.ToLower(c.DefaultQuery("sort", defaultSortDirection()))
+	filters, order, ok := taskListFilters(c)
+	if !ok {
+		return
 	}
-	limit, err := strconv.Atoi(limitStr)
-	if err != nil || limit < 1 {
-		limit = 5
+	expand, ok := parseExpand(c) // optional: embed assignee/creator user objects
+	if !ok {
+		return
 	}
-	if limit > 100 {
-		limit = 100
+	sortBy := c.DefaultQuery("sortBy", sortByCreatedAt)
+	if sortBy != sortByCreatedAt && sortBy != sortByPriorityScore {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid sortBy; allowed: createdAt, priorityScore"})
+		return
 	}
-
-	offset := (page - 1) * limit
-
-	order := "created_at desc"
-	if sortParam == "asc" {
-		order = "created_at asc"
+	overdueOnly := c.Query("overdue") == "true"
+	// Cursor mode (?cursor=, empty for the first page) pages on (created_at, id) instead of offsets
+	cursorParam, cursorMode := c.GetQuery("cursor")
+	var cursor *taskCursor
+	if cursorMode {
+		if sortBy != sortByCreatedAt {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "cursor pagination only supports sortBy=createdAt"})
+			return
+		}
+		if overdueOnly {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "cursor pagination can't be combined with overdue=true"})
+			return
+		}
+		if cursorParam != "" {
+			cur, err := decodeTaskCursor(cursorParam)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cursor"})
+				return
+			}
+			cursor = &cur
+		}
 	}
 
-	// Build base query (team-wide); optionally filter by specified userId
-	db := database.GetDB()
-	query := db.Model(&models.Task{})
-	if filterUserID != "" {
-		query = query.Where("user_id = ?", filterUserID)
+	// Overdue-ness depends on free-form end dates; SQL narrows to open tasks with an end date
+	// and the rest is checked in Go below
+	if overdueOnly {
+		base := filters
+		filters = func(query *gorm.DB) *gorm.DB {
+			return base(query).Where("status <> ? AND end_date <> ''", models.StatusDone)
+		}
 	}
+	pageInGo := sortBy == sortByPriorityScore || overdueOnly
 
-	// Total count (without pagination)
+	// Count and page are read in one transaction, so under concurrent writes the total
+	// still describes the same snapshot as the returned page
 	var total int64
-	if err := query.Count(&total).Error; err != nil {
+	var tasks []models.Task
+	hasNext := false
+	err := requestDB(c).Transaction(func(tx *gorm.DB) error {
+		if cursorMode {
+			// One extra row tells whether another page follows; no total is counted
+			desc := strings.HasSuffix(order, "desc")
+			query := tx.Model(&models.Task{}).Scopes(filters)
+			if cursor != nil {
+				query = query.Scopes(cursor.after(desc))
+			}
+			tieBreak := "id asc"
+			if desc {
+				tieBreak = "id desc"
+			}
+			if err := query.Order(order + ", " + tieBreak).Limit(limit + 1).Find(&tasks).Error; err != nil {
+				return err
+			}
+			if len(tasks) > limit {
+				hasNext = true
+				tasks = tasks[:limit]
+			}
+			return nil
+		}
+		if err := tx.Model(&models.Task{}).Scopes(filters).Count(&total).Error; err != nil {
+			return err
+		}
+		query := tx.Model(&models.Task{}).Scopes(filters)
+		if !pageInGo {
+			return query.Order(order).Limit(limit).Offset(offset).Find(&tasks).Error
+		}
+		// Bound what is loaded for Go-side filtering and sorting; for score sorting keep the
+		// highest-priority candidates, as priority dominates the score
+		if sortBy == sortByPriorityScore {
+			query = query.Order(priorityWeightExpr + " desc")
+		}
+		return query.Order(order).Limit(maxInMemoryCandidates).Find(&tasks).Error
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to count tasks",
+			"error": "Failed to fetch tasks",
 		})
 		return
 	}
 
-	// Fetch paginated tasks with sorting
-	var tasks []models.Task
-	result := query.Session(&gorm.Session{}).Order(order).Limit(limit).Offset(offset).Find(&tasks)
-	if result.Error != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to fetch tasks",
+	// Scores and overdue-ness depend on free-form end dates, so that filtering, score sorting
+	// and paging happen in Go
+	weights, now := scoreWeights(), time.Now()
+	truncated := pageInGo && total > int64(maxInMemoryCandidates)
+	if overdueOnly {
+		tasks = slices.DeleteFunc(tasks, func(t models.Task) bool { return !t.IsOverdue(now) })
+		total = int64(len(tasks))
+	}
+	for i := range tasks {
+		tasks[i].PriorityScore = tasks[i].Score(weights, now)
+	}
+	if sortBy == sortByPriorityScore {
+		slices.SortStableFunc(tasks, func(a, b models.Task) int {
+			return cmp.Compare(b.PriorityScore, a.PriorityScore)
+		})
+	}
+	if pageInGo {
+		tasks = tasks[min(offset, len(tasks)):min(offset+limit, len(tasks))]
+	}
+
+	// Enrich assignee field for response (plus creators when expanded)
+	var creatorIDs []string
+	if slices.Contains(expand, "creator") {
+		for _, t := range tasks {
+			creatorIDs = append(creatorIDs, t.UserID)
+		}
+	}
+	userByID := enrichAssignees(requestDB(c), tasks, creatorIDs...)
+	users := make([]models.User, 0, len(userByID))
+	for _, u := range userByID {
+		users = append(users, u)
+	}
+
+	meta := paginationMeta(total, page, limit)
+	if cursorMode {
+		meta = cursorMeta(tasks, limit, hasNext)
+	}
+	if truncated {
+		meta["truncated"] = true
+	}
+
+	// JSON:API mode: ?format=jsonapi or Accept: application/vnd.api+json
+	if c.Query("format") == "jsonapi" || strings.Contains(c.GetHeader("Accept"), jsonapi.MediaType) {
+		doc := jsonapi.Marshal(tasks, users)
+		meta["sort"] = sortParam
+		doc["meta"] = meta
+		c.Header("Content-Type", jsonapi.MediaType)
+		c.JSON(http.StatusOK, doc)
+		return
+	}
+
+	views := response.TasksView(tasks, c.GetString("role"))
+	expandTaskViews(views, tasks, expand, userByID, c.GetString("role"))
+
+	resp := meta
+	resp["tasks"] = views
+	resp["count"] = len(tasks) // number of items in this page
+	resp["sort"] = sortParam
+	resp["sortBy"] = sortBy
+	c.JSON(http.StatusOK, resp)
+}
+
+// maxInMemoryCandidates caps the rows GetTasks loads when it has to filter or sort in Go
+// (sortBy=priorityScore, overdue=true); a var so tests can lower it
+var maxInMemoryCandidates = 5000
+
+// GetTasks sortBy values
+const (
+	sortByCreatedAt     = "createdAt"
+	sortByPriorityScore = "priorityScore"
+)
+
+// streamBatchSize is the number of rows loaded per batch when streaming tasks; a var so tests can lower it
+var streamBatchSize = 500
+
+// StreamTasks handles GET /api/tasks/stream and GET /api/tasks?stream=true
+// Streams every task matching the GetTasks filters (userId, includeDeleted, sort) as NDJSON,
+// without pagination, for bulk consumers such as data pipelines.
+func StreamTasks(c *gin.Context) {
+	if c.GetString("user_id") == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found in token",
 		})
 		return
 	}
+	filters, order, ok := taskListFilters(c)
+	if !ok {
+		return
+	}
+	streamTasksNDJSON(c, filters, order)
+}
 
-	// Enrich assignee field for response
-	var users []models.User
-	if err := database.GetDB().Find(&users).Error; err == nil {
-		userByID := make(map[string]models.User, len(users))
-		for _, u := range users {
-			userByID[u.ID] = u
+// streamTasksNDJSON writes every task matched by filters as newline-delimited JSON (one task per line).
+// Tasks are read in keyset-paged batches of streamBatchSize in (order, id) order; each batch is
+// fully read before its users are loaded and it is written and flushed, so no database cursor or
+// connection is held while streaming and memory stays bounded.
+func streamTasksNDJSON(c *gin.Context, filters func(*gorm.DB) *gorm.DB, order string) {
+	desc := strings.HasSuffix(order, "desc")
+	tieBreak := "id asc"
+	if desc {
+		tieBreak = "id desc"
+	}
+
+	role := c.GetString("role")
+	encoder := json.NewEncoder(c.Writer)
+	started := false
+	var cursor *taskCursor
+	for {
+		query := requestDB(c).Model(&models.Task{}).Scopes(filters)
+		if cursor != nil {
+			query = query.Scopes(cursor.after(desc))
+		}
+		var batch []models.Task
+		if err := query.Order(order + ", " + tieBreak).Limit(streamBatchSize).Find(&batch).Error; err != nil {
+			if !started {
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error": "Failed to fetch tasks",
+				})
+				return
+			}
+			// Status is already committed; all we can do is log and stop
+			log.Println("task stream aborted:", err)
+			return
 		}
 
-		for i := range tasks {
-			if u, ok := userByID[tasks[i].AssigneeID]; ok {
-				tasks[i].Assignee = models.Assignee{
-					ID:   u.ID,
-					Name: u.Username,
-				}
+		if !started {
+			// Headers must be set before the first write
+			c.Header("Content-Type", "application/x-ndjson")
+			c.Status(http.StatusOK)
+			started = true
+		}
+		enrichAssignees(requestDB(c), batch)
+		for i := range batch {
+			if err := encoder.Encode(response.TaskView(batch[i], role)); err != nil {
+				log.Println("task stream aborted:", err)
+				return
 			}
 		}
-	}
+		c.Writer.Flush()
 
-	c.JSON(http.StatusOK, gin.H{
-		"tasks": tasks,
-		"count": len(tasks), // number of items in this page
-		"total": total,      // total tasks (all pages) for current filter
-		"page":  page,
-		"limit": limit,
-		"sort":  sortParam,
-	})
+		if len(batch) < streamBatchSize {
+			return
+		}
+		last := batch[len(batch)-1]
+		cursor = &taskCursor{CreatedAt: last.CreatedAt, ID: last.ID}
+	}
 }
 
 /*
@@ -196,29 +724,94 @@ func CreateTask(c *gin.Context) {
 	}
 
 	var req CreateTaskRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if err := bindJSON(c, &req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error": err.Error(),
 		})
 		return
 	}
+	task, err := newTaskFromRequest(requestDB(c), req, userID, c.GetHeader("X-Client-Source"))
+	if err != nil {
+		writeTaskRequestError(c, err)
+		return
+	}
+
+	// No avatar handling
+
+	result := requestDB(c).Create(&task)
+	if errors.As(result.Error, new(apperr.ValidationError)) {
+		// Rejected by the model's BeforeSave invariant
+		_ = c.Error(result.Error)
+		return
+	}
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to create task",
+		})
+		return
+	}
+	recordAudit(requestDB(c), task.ID, userID, models.AuditCreated)
+	invalidateStats(task.AssigneeID)
+
+	// Forecast effort from the user's history (response only, not stored)
+	if ratio, ok := effortOverrunRatio(userID, requestDB(c)); ok {
+		task.ForecastedEffort = applyOverrunRatio(task.Effort, ratio)
+	}
+
+	// Broadcast event to the authenticated user's channels
+	evt := map[string]any{
+		"type":    "task_created",
+		"taskId":  response.ExternalID(task.ID),
+		"userId":  response.ExternalID(userID),
+		"version": 1,
+	}
+	if realtimeFullPayload() {
+		evt["task"] = response.TaskView(task, c.GetString("role"))
+	}
+	if bytes, err := json.Marshal(evt); err == nil {
+		realtime.GetHub().Broadcast(userID, bytes)
+	}
+
+	c.JSON(http.StatusCreated, response.TaskView(task, c.GetString("role")))
+}
+
+// newTaskFromRequest validates req and builds the task it describes for userID, without saving it.
+// headerSource is the X-Client-Source header, used when the body has no source. Invalid payloads
+// yield a taskRequestError, a taken client-supplied id an apperr.ConflictError.
+func newTaskFromRequest(db *gorm.DB, req CreateTaskRequest, userID, headerSource string) (models.Task, error) {
+	if err := textLimitError(req.Title, req.Description); err != nil {
+		return models.Task{}, *err
+	}
+	labels, labelErr := normalizeLabels(req.Labels)
+	if labelErr != nil {
+		return models.Task{}, *labelErr
+	}
 
 	// Set default values if not provided
 	status := req.Status
 	if status == "" {
-		status = models.StatusTodo
+		status = defaultTaskStatus()
 	}
 
 	priority := req.Priority
 	if priority == "" {
-		priority = models.PriorityMedium
+		priority = defaultTaskPriority()
+	}
+
+	// Creation source: body field first, then header; optional but must be a known client
+	source := req.Source
+	if source == "" {
+		source = models.TaskSource(strings.ToLower(strings.TrimSpace(headerSource)))
+	}
+	if source != "" && !source.IsValid() {
+		return models.Task{}, badTaskRequest(http.StatusBadRequest, fmt.Sprintf("Invalid source %q; allowed: web, mobile, import", source))
 	}
 
 	// Compute effort based on dates; ignore client-provided effort
 	effort := calculateEffortDays(req.StartDate, req.EndDate)
 
 	// Validate and normalize project linkage based on task type
-	projectID := strings.TrimSpace(req.ProjectID)
+	projectID := response.InternalTaskID(strings.TrimSpace(req.ProjectID))
 	switch req.TaskType {
 	case models.TypeStory:
 		// Level 1: must NOT be linked; treat empty as null and enforce empty
@@ -226,30 +819,33 @@ func CreateTask(c *gin.Context) {
 	case models.TypeDefect, models.TypeSubtask:
 		// Level 2: must reference an existing Story as parent via projectId
 		if projectID == "" {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "projectId is required for subtask/defect and must reference a story id"})
-			return
+			return models.Task{}, badTaskRequest(http.StatusBadRequest, "projectId is required for subtask/defect and must reference a story id")
 		}
 		// Validate parent exists and is a story owned by the same team (no user ownership requirement for parent beyond visibility)
-		var parent models.Task
-		if err := database.GetDB().Where("id = ? AND task_type = ?", projectID, models.TypeStory).First(&parent).Error; err != nil {
-			if errors.Is(err, gorm.ErrRecordNotFound) {
-				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid projectId: parent story not found"})
-			} else {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to validate projectId"})
+		parent, err := findParentStory(db, projectID)
+		if err != nil {
+			if isInvalidParent(err) {
+				return models.Task{}, badTaskRequest(http.StatusBadRequest, "Invalid projectId: parent story not found")
 			}
-			return
+			return models.Task{}, err
+		}
+		if blockSubtaskOnDoneStory() && parent.Status == models.StatusDone {
+			return models.Task{}, badTaskRequest(http.StatusUnprocessableEntity, "Cannot add a subtask/defect to a story that is already done")
 		}
 	default:
 		// Unknown type guard
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid taskType"})
-		return
+		return models.Task{}, badTaskRequest(http.StatusBadRequest, "Invalid taskType")
 	}
 
-	// Generate task ID (simple format: task-{timestamp})
-	taskID := fmt.Sprintf("task-%d", time.Now().UnixNano())
+	// Generate task ID (simple format: task-{timestamp}) unless the client supplied one
+	taskID := response.InternalTaskID(strings.TrimSpace(req.ID))
+	if taskID == "" {
+		taskID = fmt.Sprintf("task-%d", time.Now().UnixNano())
+	} else if err := ensureTaskIDFree(db, taskID); err != nil {
+		return models.Task{}, err
+	}
 
-	// Create task
-	task := models.Task{
+	return models.Task{
 		ID:          taskID,
 		Title:       req.Title,
 		Description: req.Description,
@@ -262,31 +858,133 @@ func CreateTask(c *gin.Context) {
 		Effort:      effort,
 		Priority:    priority,
 		TaskType:    req.TaskType,
+		Source:      source,
+		Labels:      labels,
 		UserID:      userID,
+	}, nil
+}
+
+// writeTaskRequestError answers with an error from newTaskFromRequest
+func writeTaskRequestError(c *gin.Context, err error) {
+	var reqErr taskRequestError
+	switch {
+	case errors.As(err, &reqErr):
+		c.JSON(reqErr.status, reqErr.body)
+	case errors.As(err, new(apperr.ConflictError)):
+		_ = c.Error(err)
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create task"})
 	}
+}
 
-	// No avatar handling
+// BulkCreateTasks handles POST /api/tasks/bulk
+// Creates up to 50 tasks for the authenticated user. Every item is validated like CreateTask first;
+// if any fails, nothing is written and the answer is 422 with the errors keyed by item index.
+// Otherwise all tasks are inserted in one transaction and returned in request order.
+func BulkCreateTasks(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found in token",
+		})
+		return
+	}
 
-	result := database.GetDB().Create(&task)
-	if result.Error != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to create task",
+	var req BulkCreateTasksRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(req.Tasks) > maxBulkCreateTasks {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("At most %d tasks per request", maxBulkCreateTasks)})
+		return
+	}
+
+	// Validate every item before writing anything; generated IDs share one timestamp base
+	base := time.Now().UnixNano()
+	tasks := make([]models.Task, 0, len(req.Tasks))
+	itemErrors := map[string]string{}
+	seen := map[string]bool{}
+	for i, item := range req.Tasks {
+		key := strconv.Itoa(i)
+		if err := binding.Validator.ValidateStruct(item); err != nil {
+			itemErrors[key] = err.Error()
+			continue
+		}
+		if strings.TrimSpace(item.ID) == "" {
+			item.ID = fmt.Sprintf("task-%d", base+int64(i))
+		}
+		task, err := newTaskFromRequest(requestDB(c), item, userID, c.GetHeader("X-Client-Source"))
+		var reqErr taskRequestError
+		switch {
+		case errors.As(err, &reqErr), errors.As(err, new(apperr.ConflictError)):
+			itemErrors[key] = err.Error()
+			continue
+		case err != nil:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create tasks"})
+			return
+		}
+		if seen[task.ID] {
+			itemErrors[key] = fmt.Sprintf("duplicate id %s in request", response.ExternalID(task.ID))
+			continue
+		}
+		seen[task.ID] = true
+		tasks = append(tasks, task)
+	}
+	if len(itemErrors) > 0 {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":  "One or more tasks are invalid; nothing was created",
+			"errors": itemErrors,
 		})
 		return
 	}
 
-	// Broadcast event to the authenticated user's channels
+	err := requestDB(c).Transaction(func(tx *gorm.DB) error {
+		return tx.CreateInBatches(&tasks, maxBulkCreateTasks).Error
+	})
+	if errors.As(err, new(apperr.ValidationError)) {
+		// Rejected by the model's BeforeSave invariant
+		_ = c.Error(err)
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create tasks"})
+		return
+	}
+
+	createdIDs := make([]string, 0, len(tasks))
+	for _, task := range tasks {
+		recordAudit(requestDB(c), task.ID, userID, models.AuditCreated)
+		invalidateStats(task.AssigneeID)
+		createdIDs = append(createdIDs, response.ExternalID(task.ID))
+	}
 	evt := map[string]any{
-		"type":    "task_created",
-		"taskId":  task.ID,
-		"userId":  userID,
+		"type":    "task_bulk_created",
+		"taskIds": createdIDs,
+		"userId":  response.ExternalID(userID),
 		"version": 1,
 	}
 	if bytes, err := json.Marshal(evt); err == nil {
 		realtime.GetHub().Broadcast(userID, bytes)
 	}
 
-	c.JSON(http.StatusCreated, task)
+	c.JSON(http.StatusCreated, gin.H{
+		"created": response.TasksView(tasks, c.GetString("role")),
+		"count":   len(tasks),
+	})
+}
+
+// ensureTaskIDFree returns a ConflictError when a task (including a soft-deleted one) already
+// uses id, so a client-supplied ID can never replace an existing task.
+func ensureTaskIDFree(db *gorm.DB, id string) error {
+	var count int64
+	if err := db.Unscoped().Model(&models.Task{}).Where("id = ?", id).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return apperr.ConflictError{Message: fmt.Sprintf("task %s already exists", response.ExternalID(id))}
+	}
+	return nil
 }
 
 // UpdateTask handles PUT /api/tasks/:id
@@ -300,7 +998,7 @@ func UpdateTask(c *gin.Context) {
 		return
 	}
 
-	taskID := c.Param("id")
+	taskID := response.InternalTaskID(c.Param("id"))
 	if taskID == "" {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error": "Task ID is required",
@@ -309,24 +1007,15 @@ func UpdateTask(c *gin.Context) {
 	}
 
 	// Check if task exists and belongs to user
-	var existingTask models.Task
-	result := database.GetDB().Where("id = ? AND user_id = ?", taskID, userID).First(&existingTask)
-	if result.Error != nil {
-		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{
-				"error": "Task not found",
-			})
-		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": "Failed to fetch task",
-			})
-		}
+	existingTask, err := findOwnedTask(requestDB(c), taskID, userID)
+	if err != nil {
+		_ = c.Error(err)
 		return
 	}
 
 	// Parse update request
 	var req UpdateTaskRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if err := bindJSON(c, &req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error": err.Error(),
 		})
@@ -334,17 +1023,32 @@ func UpdateTask(c *gin.Context) {
 	}
 
 	// Update fields if provided
+	var newTitle, newDescription string
 	if req.Title != nil {
-		existingTask.Title = *req.Title
+		newTitle = *req.Title
+	}
+	if req.Description != nil {
+		newDescription = *req.Description
+	}
+	if !checkTextLimits(c, newTitle, newDescription) {
+		return
+	}
+	if req.Title != nil {
+		existingTask.Title = *req.Title
 	}
 	if req.Description != nil {
 		existingTask.Description = *req.Description
 	}
+	previousStatus := existingTask.Status
+	previousAssigneeID := existingTask.AssigneeID
 	if req.Status != nil {
+		if !checkStatusTransition(c, previousStatus, *req.Status) {
+			return
+		}
 		existingTask.Status = *req.Status
 	}
 	if req.ProjectID != nil {
-		existingTask.ProjectID = *req.ProjectID
+		existingTask.ProjectID = response.InternalTaskID(strings.TrimSpace(*req.ProjectID))
 	}
 	if req.Assignee != nil {
 		existingTask.AssigneeID = req.Assignee.ID
@@ -354,18 +1058,36 @@ func UpdateTask(c *gin.Context) {
 		existingTask.StartDate = *req.StartDate
 	}
 	if req.EndDate != nil {
+		if *req.EndDate != existingTask.EndDate {
+			// New deadline, so a new deadline alert may be due
+			existingTask.AlertSent = false
+		}
 		existingTask.EndDate = *req.EndDate
 	}
 	// Recalculate effort if either date was provided in the update; otherwise leave as-is
 	if req.StartDate != nil || req.EndDate != nil {
 		existingTask.Effort = calculateEffortDays(existingTask.StartDate, existingTask.EndDate)
 	}
+	if req.ActualEffort != nil {
+		existingTask.ActualEffort = *req.ActualEffort
+	}
 	if req.Priority != nil {
 		existingTask.Priority = *req.Priority
 	}
 	if req.TaskType != nil {
 		existingTask.TaskType = *req.TaskType
 	}
+	if req.Position != nil {
+		existingTask.Position = *req.Position
+	}
+	if req.Labels != nil {
+		labels, err := normalizeLabels(*req.Labels)
+		if err != nil {
+			c.JSON(err.status, err.body)
+			return
+		}
+		existingTask.Labels = labels
+	}
 
 	// Enforce projectId invariants based on (possibly updated) type
 	// Rules: story => projectId must be empty; subtask/defect => projectId required and must reference existing story
@@ -376,9 +1098,8 @@ func UpdateTask(c *gin.Context) {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "projectId is required for subtask/defect and must reference a story id"})
 			return
 		}
-		var parent models.Task
-		if err := database.GetDB().Where("id = ? AND task_type = ?", existingTask.ProjectID, models.TypeStory).First(&parent).Error; err != nil {
-			if errors.Is(err, gorm.ErrRecordNotFound) {
+		if _, err := findParentStory(requestDB(c), existingTask.ProjectID); err != nil {
+			if isInvalidParent(err) {
 				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid projectId: parent story not found"})
 			} else {
 				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to validate projectId"})
@@ -388,38 +1109,49 @@ func UpdateTask(c *gin.Context) {
 	}
 
 	// Save updated task
-	result = database.GetDB().Save(&existingTask)
+	result := requestDB(c).Save(&existingTask)
+	if errors.As(result.Error, new(apperr.ValidationError)) {
+		// Rejected by the model's BeforeSave invariant
+		_ = c.Error(result.Error)
+		return
+	}
 	if result.Error != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to update task",
 		})
 		return
 	}
+	recordAudit(requestDB(c), existingTask.ID, userID, models.AuditUpdated)
+	invalidateStats(previousAssigneeID, existingTask.AssigneeID)
+	if existingTask.Status != previousStatus {
+		recordStatusAudit(requestDB(c), existingTask.ID, userID, models.AuditStatusChanged, previousStatus, existingTask.Status)
+	}
 
 	// Enrich assignee in response
-	if existingTask.AssigneeID != "" {
-		var u models.User
-		if err := database.GetDB().Where("id = ?", existingTask.AssigneeID).First(&u).Error; err == nil {
-			existingTask.Assignee = models.Assignee{ID: u.ID, Name: u.Username}
-		}
-	}
+	enrichAssignee(requestDB(c), &existingTask)
 
 	// Broadcast update event
 	evt := map[string]any{
 		"type":    "task_updated",
-		"taskId":  existingTask.ID,
-		"userId":  userID,
+		"taskId":  response.ExternalID(existingTask.ID),
+		"userId":  response.ExternalID(userID),
 		"version": 1,
 	}
+	if realtimeFullPayload() {
+		evt["task"] = response.TaskView(existingTask, c.GetString("role"))
+	}
 	if bytes, err := json.Marshal(evt); err == nil {
 		realtime.GetHub().Broadcast(userID, bytes)
 	}
 
-	c.JSON(http.StatusOK, existingTask)
+	c.JSON(http.StatusOK, response.TaskView(existingTask, c.GetString("role")))
 }
 
 // GetTaskByID handles GET /api/tasks/:id
-// Returns a single task owned by the authenticated user
+// Returns a single task (team-wide, like GetTasks); admins may pass includeDeleted=true
+// to fetch it even if soft-deleted. expand=assignee,creator embeds the related user objects.
+// Open stories carry completable, true when they meet the auto-complete criteria.
+// permissions tells the caller which writes they may make (see taskPermissions).
 func GetTaskByID(c *gin.Context) {
 	userID := c.GetString("user_id")
 	if userID == "" {
@@ -429,47 +1161,172 @@ func GetTaskByID(c *gin.Context) {
 		return
 	}
 
-	taskID := c.Param("id")
+	taskID := response.InternalTaskID(c.Param("id"))
 	if taskID == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Task ID is required"})
 		return
 	}
 
-	var task models.Task
-	result := database.GetDB().Where("id = ? AND user_id = ?", taskID, userID).First(&task)
-	if result.Error != nil {
-		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
-		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch task"})
-		}
+	withDeleted, ok := includeDeleted(c)
+	if !ok {
 		return
 	}
+	expand, ok := parseExpand(c)
+	if !ok {
+		return
+	}
+	db := requestDB(c)
+	if withDeleted {
+		db = db.Unscoped()
+	}
 
-	// Enrich assignee
-	if task.AssigneeID != "" {
-		var u models.User
-		if err := database.GetDB().Where("id = ?", task.AssigneeID).First(&u).Error; err == nil {
-			task.Assignee = models.Assignee{ID: u.ID, Name: u.Username}
-		}
+	task, err := findTask(db, taskID)
+	if err != nil {
+		_ = c.Error(err)
+		return
 	}
 
+	// Enrich assignee
+	enrichAssignee(requestDB(c), &task)
+	task.PriorityScore = task.Score(scoreWeights(), time.Now())
+
 	// Broadcast status change
 	evt := map[string]any{
 		"type":    "task_status_changed",
-		"taskId":  task.ID,
-		"userId":  userID,
+		"taskId":  response.ExternalID(task.ID),
+		"userId":  response.ExternalID(userID),
 		"version": 1,
 	}
 	if bytes, err := json.Marshal(evt); err == nil {
 		realtime.GetHub().Broadcast(userID, bytes)
 	}
 
-	c.JSON(http.StatusOK, task)
+	view := response.TaskView(task, c.GetString("role"))
+	view["permissions"] = taskPermissions(c, task)
+	// Stories say whether POST /api/tasks/:id/auto-complete would succeed
+	if task.TaskType == models.TypeStory && task.Status != models.StatusDone {
+		if blockers, err := completionBlockers(requestDB(c), task, time.Now()); err == nil {
+			view["completable"] = len(blockers) == 0
+		}
+	}
+	if len(expand) > 0 {
+		userByID, err := lookupUsers(requestDB(c), []string{task.AssigneeID, task.UserID})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch related users"})
+			return
+		}
+		expandTaskViews([]map[string]any{view}, []models.Task{task}, expand, userByID, c.GetString("role"))
+	}
+
+	c.JSON(http.StatusOK, view)
+}
+
+// GetTaskChildren handles GET /api/tasks/:id/children
+// Returns a page of the defects and subtasks linked to a story (team-wide), oldest first, with
+// assignees enriched; supports page and limit. 404 when the id is missing or not a story.
+func GetTaskChildren(c *gin.Context) {
+	if c.GetString("user_id") == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found in token",
+		})
+		return
+	}
+
+	parentID := response.InternalTaskID(c.Param("id"))
+	if parentID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Task ID is required"})
+		return
+	}
+	page, limit, offset := parsePagination(c)
+
+	var parent models.Task
+	if err := requestDB(c).Where("id = ? AND task_type = ?", parentID, models.TypeStory).First(&parent).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			_ = c.Error(apperr.NotFoundError{Resource: "Story", ID: parentID})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch task"})
+		return
+	}
+
+	var total int64
+	var children []models.Task
+	err := requestDB(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Task{}).Where("project_id = ?", parent.ID).Count(&total).Error; err != nil {
+			return err
+		}
+		return tx.Where("project_id = ?", parent.ID).Order("created_at asc").Limit(limit).Offset(offset).Find(&children).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tasks"})
+		return
+	}
+	enrichAssignees(requestDB(c), children)
+
+	resp := paginationMeta(total, page, limit)
+	resp["parentId"] = response.ExternalID(parent.ID)
+	resp["tasks"] = response.TasksView(children, c.GetString("role"))
+	resp["count"] = len(children)
+	c.JSON(http.StatusOK, resp)
+}
+
+// ValidateStories handles POST /api/stories/validate
+// Checks, ahead of a bulk import, which ids can be used as a subtask/defect projectId. Each id
+// gets a verdict saying whether the task exists and whether it is a story; allValid is true when every id is usable.
+func ValidateStories(c *gin.Context) {
+	if c.GetString("user_id") == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found in token",
+		})
+		return
+	}
+
+	var req ValidateStoriesRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var ids []string
+	for _, id := range req.IDs {
+		if id = response.InternalTaskID(strings.TrimSpace(id)); id != "" && !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ids must contain at least one task id"})
+		return
+	}
+	if len(ids) > maxValidateStoryIDs {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("At most %d ids per request", maxValidateStoryIDs)})
+		return
+	}
+
+	results := make([]gin.H, 0, len(ids))
+	allValid := true
+	for _, id := range ids {
+		_, err := findParentStory(requestDB(c), id)
+		if err != nil && !isInvalidParent(err) {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to validate stories"})
+			return
+		}
+		valid := err == nil
+		allValid = allValid && valid
+		results = append(results, gin.H{
+			"id":      response.ExternalID(id),
+			"exists":  !errors.Is(err, errParentNotFound),
+			"isStory": valid,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"results":  results,
+		"allValid": allValid,
+	})
 }
 
 // UpdateTaskStatus handles PATCH /api/tasks/:id/status
-// Updates only the status of a task owned by the authenticated user
+// Updates only the status of a task owned by the authenticated user; transitions that skip a
+// state (see models.AllowedTransitions) get 422
 func UpdateTaskStatus(c *gin.Context) {
 	userID := c.GetString("user_id")
 	if userID == "" {
@@ -479,49 +1336,233 @@ func UpdateTaskStatus(c *gin.Context) {
 		return
 	}
 
-	taskID := c.Param("id")
+	taskID := response.InternalTaskID(c.Param("id"))
 	if taskID == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Task ID is required"})
 		return
 	}
 
 	var req UpdateTaskStatusRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if err := bindJSON(c, &req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	var task models.Task
-	result := database.GetDB().Where("id = ? AND user_id = ?", taskID, userID).First(&task)
-	if result.Error != nil {
-		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
-		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch task"})
-		}
+	task, err := findOwnedTask(requestDB(c), taskID, userID)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	if !checkStatusTransition(c, task.Status, req.Status) {
 		return
 	}
 
 	// Explicitly update only the status column to ensure persistence
+	previousStatus := task.Status
 	task.Status = req.Status
-	if err := database.GetDB().Model(&task).Update("status", req.Status).Error; err != nil {
+	if err := requestDB(c).Model(&task).Update("status", req.Status).Error; err != nil {
+		if errors.As(err, new(apperr.ValidationError)) {
+			// Stored task violates the story/child invariant; surface it rather than a 500
+			_ = c.Error(err)
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update status"})
 		return
 	}
+	invalidateStats(task.AssigneeID)
+	if previousStatus != req.Status {
+		recordStatusAudit(requestDB(c), task.ID, userID, models.AuditStatusChanged, previousStatus, req.Status)
+	}
 
 	// Enrich assignee in response
-	if task.AssigneeID != "" {
-		var u models.User
-		if err := database.GetDB().Where("id = ?", task.AssigneeID).First(&u).Error; err == nil {
-			task.Assignee = models.Assignee{ID: u.ID, Name: u.Username}
+	enrichAssignee(requestDB(c), &task)
+
+	c.JSON(http.StatusOK, response.TaskView(task, c.GetString("role")))
+}
+
+// AutoCompleteTask handles POST /api/tasks/:id/auto-complete
+// Marks one of the caller's stories done when its end date has passed and all of its subtasks and
+// defects are done. The workflow's inProgress step is not required. Otherwise answers 422 with the reasons.
+func AutoCompleteTask(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found in token",
+		})
+		return
+	}
+
+	taskID := response.InternalTaskID(c.Param("id"))
+	if taskID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Task ID is required"})
+		return
+	}
+
+	task, err := findOwnedTask(requestDB(c), taskID, userID)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	blockers, err := completionBlockers(requestDB(c), task, time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check completion criteria"})
+		return
+	}
+	if len(blockers) > 0 {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":   "Task cannot be auto-completed",
+			"reasons": blockers,
+		})
+		return
+	}
+
+	previousStatus := task.Status
+	task.Status = models.StatusDone
+	if err := requestDB(c).Model(&task).Update("status", models.StatusDone).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update status"})
+		return
+	}
+	invalidateStats(task.AssigneeID)
+	recordStatusAudit(requestDB(c), task.ID, userID, models.AuditStatusChanged, previousStatus, models.StatusDone)
+
+	enrichAssignee(requestDB(c), &task)
+	c.JSON(http.StatusOK, response.TaskView(task, c.GetString("role")))
+}
+
+// completionBlockers lists why task can't be auto-completed at now; none means it can:
+// it must be an unfinished story whose end date has passed and whose children are all done.
+func completionBlockers(db *gorm.DB, task models.Task, now time.Time) ([]string, error) {
+	var blockers []string
+	if task.TaskType != models.TypeStory {
+		return []string{"only stories can be auto-completed"}, nil
+	}
+	if task.Status == models.StatusDone {
+		blockers = append(blockers, "story is already done")
+	}
+	if deadline, ok := task.Deadline(); !ok {
+		blockers = append(blockers, "story has no end date")
+	} else if deadline.After(now) {
+		blockers = append(blockers, "end date has not passed")
+	}
+	var open int64
+	if err := db.Model(&models.Task{}).Where("project_id = ? AND status <> ?", task.ID, models.StatusDone).Count(&open).Error; err != nil {
+		return nil, err
+	}
+	if open > 0 {
+		blockers = append(blockers, fmt.Sprintf("%d subtasks/defects are not done", open))
+	}
+	return blockers, nil
+}
+
+// checkStatusTransition writes a 422 naming the allowed next states and returns false when a task
+// may not move from one status to another (see models.AllowedTransitions).
+func checkStatusTransition(c *gin.Context, from, to models.TaskStatus) bool {
+	if models.CanTransition(from, to) {
+		return true
+	}
+	next := make([]string, 0, len(models.AllowedTransitions[from]))
+	for _, s := range models.AllowedTransitions[from] {
+		next = append(next, string(s))
+	}
+	c.JSON(http.StatusUnprocessableEntity, gin.H{
+		"error":   fmt.Sprintf("Cannot move task from %s to %s; allowed next states: %s", from, to, strings.Join(next, ", ")),
+		"allowed": next,
+	})
+	return false
+}
+
+// SnoozeTaskRequest represents the request payload for snoozing a task's due date
+type SnoozeTaskRequest struct {
+	Days int `json:"days" binding:"required"`
+}
+
+// SnoozeTask handles PATCH /api/tasks/:id/snooze
+// Pushes the end date of a task owned by the authenticated user forward by the given number of days
+// and recalculates its effort. The new end date is stored as YYYY-MM-DD, or RFC3339 when it had a time.
+func SnoozeTask(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found in token",
+		})
+		return
+	}
+
+	taskID := response.InternalTaskID(c.Param("id"))
+	if taskID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Task ID is required"})
+		return
+	}
+
+	var req SnoozeTaskRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Days <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "days must be a positive integer"})
+		return
+	}
+
+	task, err := findOwnedTask(requestDB(c), taskID, userID)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	end, ok := parseDateFlexible(task.EndDate)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Task has no valid end date to snooze"})
+		return
+	}
+	layout := "2006-01-02"
+	if _, err := time.Parse(time.RFC3339, task.EndDate); err == nil {
+		layout = time.RFC3339
+	}
+	task.EndDate = end.AddDate(0, 0, req.Days).Format(layout)
+	task.Effort = calculateEffortDays(task.StartDate, task.EndDate)
+	// New deadline, so a new deadline alert may be due
+	task.AlertSent = false
+
+	if err := requestDB(c).Model(&task).Select("end_date", "effort", "alert_sent").Updates(&task).Error; err != nil {
+		if errors.As(err, new(apperr.ValidationError)) {
+			_ = c.Error(err)
+			return
 		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to snooze task"})
+		return
+	}
+	recordAudit(requestDB(c), task.ID, userID, models.AuditUpdated)
+	invalidateStats(task.AssigneeID, task.UserID)
+
+	// Enrich assignee in response
+	enrichAssignee(requestDB(c), &task)
+
+	// Broadcast update event
+	evt := map[string]any{
+		"type":    "task_updated",
+		"taskId":  response.ExternalID(task.ID),
+		"userId":  response.ExternalID(userID),
+		"version": 1,
+	}
+	if realtimeFullPayload() {
+		evt["task"] = response.TaskView(task, c.GetString("role"))
+	}
+	if bytes, err := json.Marshal(evt); err == nil {
+		realtime.GetHub().Broadcast(userID, bytes)
 	}
 
-	c.JSON(http.StatusOK, task)
+	c.JSON(http.StatusOK, response.TaskView(task, c.GetString("role")))
 }
 
 // DeleteTask handles DELETE /api/tasks/:id
-// Deletes a task owned by the authenticated user
+// Soft-deletes a task owned by the authenticated user (any task, for admins); it stays recoverable until the purge job
+// hard-deletes it after DELETE_GRACE_PERIOD (see GET /api/tasks/trash and POST /api/tasks/:id/restore).
+// Admins may pass ?hard=true (or the older ?immediate=true) to permanently remove it right away.
+// Stories with subtasks/defects get 409 listing the child ids, unless ?cascade=true deletes the
+// story and its children in one transaction.
 func DeleteTask(c *gin.Context) {
 	userID := c.GetString("user_id")
 	if userID == "" {
@@ -531,7 +1572,7 @@ func DeleteTask(c *gin.Context) {
 		return
 	}
 
-	taskID := c.Param("id")
+	taskID := response.InternalTaskID(c.Param("id"))
 	if taskID == "" {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error": "Task ID is required",
@@ -539,97 +1580,1040 @@ func DeleteTask(c *gin.Context) {
 		return
 	}
 
-	// Check if task exists and belongs to user
+	// ?hard=true (alias ?immediate=true) skips the grace period (admins only)
+	immediate := c.Query("hard") == "true" || c.Query("immediate") == "true"
+	if immediate && !requireAdmin(c) {
+		return
+	}
+
+	// Check if task exists and belongs to user; admins may delete any user's task
 	var task models.Task
-	result := database.GetDB().Where("id = ? AND user_id = ?", taskID, userID).First(&task)
-	if result.Error != nil {
-		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{
-				"error": "Task not found",
-			})
-		} else {
+	var err error
+	if isAdmin(c) {
+		task, err = findTask(requestDB(c), taskID)
+	} else {
+		task, err = findOwnedTask(requestDB(c), taskID, userID)
+	}
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	// A story with live children is only deleted together with them (?cascade=true)
+	var children []models.Task
+	if task.TaskType == models.TypeStory {
+		if err := requestDB(c).Where("project_id = ?", task.ID).Order("created_at asc").Find(&children).Error; err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": "Failed to fetch task",
+				"error": "Failed to delete task",
 			})
+			return
 		}
+	}
+	childIDs := make([]string, 0, len(children))
+	for _, child := range children {
+		childIDs = append(childIDs, response.ExternalID(child.ID))
+	}
+	if len(children) > 0 && c.Query("cascade") != "true" {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":    "Story has subtasks/defects; delete them first or pass cascade=true",
+			"childIds": childIDs,
+		})
 		return
 	}
 
-	// Delete task
-	result = database.GetDB().Delete(&task)
-	if result.Error != nil {
+	// Delete task (and children): soft-delete and schedule the hard delete after the grace period,
+	// unless an immediate delete was requested
+	var purgeAfter *time.Time
+	if !immediate {
+		at := time.Now().Add(deleteGracePeriod())
+		purgeAfter = &at
+	}
+	removed := append(children, task)
+	err = requestDB(c).Transaction(func(tx *gorm.DB) error {
+		for _, t := range removed {
+			if err := deleteTaskRow(tx, t, purgeAfter); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to delete task",
 		})
 		return
 	}
 
-	// Broadcast deletion
-	evt := map[string]any{
-		"type":    "task_deleted",
-		"taskId":  taskID,
-		"userId":  userID,
-		"version": 1,
-	}
-	if bytes, err := json.Marshal(evt); err == nil {
-		realtime.GetHub().Broadcast(userID, bytes)
+	// Audit and broadcast each removed child, then the task itself
+	for _, t := range removed {
+		recordAudit(requestDB(c), t.ID, userID, models.AuditDeleted)
+		invalidateStats(t.AssigneeID)
+		evt := map[string]any{
+			"type":    "task_deleted",
+			"taskId":  response.ExternalID(t.ID),
+			"userId":  response.ExternalID(userID),
+			"version": 1,
+		}
+		if bytes, err := json.Marshal(evt); err == nil {
+			realtime.GetHub().Broadcast(userID, bytes)
+		}
 	}
 
-	c.JSON(http.StatusOK, gin.H{
+	resp := gin.H{
 		"message": "Task deleted successfully",
-		"id":      taskID,
-	})
+		"id":      response.ExternalID(taskID),
+	}
+	if purgeAfter != nil {
+		resp["purgeAfter"] = purgeAfter
+	}
+	if len(childIDs) > 0 {
+		resp["deletedChildIds"] = childIDs
+	}
+	c.JSON(http.StatusOK, resp)
 }
 
-// GetStatsByUser handles GET /api/stats/:userid
-// Returns counts of tasks by status (todo, inProgress, done) where the assignee matches :userid
-func GetStatsByUser(c *gin.Context) {
-	// Ensure request is authenticated
-	authUserID := c.GetString("user_id")
-	if authUserID == "" {
+// deleteTaskRow soft-deletes task and schedules its purge at purgeAfter, or hard-deletes it when purgeAfter is nil
+func deleteTaskRow(tx *gorm.DB, task models.Task, purgeAfter *time.Time) error {
+	if purgeAfter == nil {
+		return tx.Unscoped().Delete(&task).Error
+	}
+	if err := tx.Model(&task).UpdateColumn("purge_after", *purgeAfter).Error; err != nil {
+		return err
+	}
+	return tx.Delete(&task).Error
+}
+
+// BulkDeleteTasks handles DELETE /api/tasks/bulk
+// Soft-deletes up to 100 of the caller's tasks (admins: any task) in one transaction, with the usual
+// grace period. Ids that are not found or not visible are reported under "notFound", and stories that
+// still have subtasks/defects outside the batch under "hasChildren"; neither fails the request.
+// One task_bulk_deleted event lists the deleted ids after commit.
+func BulkDeleteTasks(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if userID == "" {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in token"})
 		return
 	}
 
-	targetUserID := c.Param("userid")
-	if strings.TrimSpace(targetUserID) == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "userid is required"})
+	var req BulkDeleteTasksRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var ids []string
+	for _, id := range req.IDs {
+		if id = response.InternalTaskID(strings.TrimSpace(id)); id != "" && !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ids must contain at least one task id"})
+		return
+	}
+	if len(ids) > maxBulkDeleteIDs {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("At most %d ids per request", maxBulkDeleteIDs)})
 		return
 	}
 
-	db := database.GetDB()
+	deletedIDs := []string{}
+	notFound := []string{}
+	hasChildren := []string{}
+	var deleted []models.Task
+	purgeAfter := time.Now().Add(deleteGracePeriod())
+	err := requestDB(c).Transaction(func(tx *gorm.DB) error {
+		query := tx.Where("id IN ?", ids)
+		if !isAdmin(c) {
+			query = query.Where("user_id = ?", userID)
+		}
+		var tasks []models.Task
+		if err := query.Find(&tasks).Error; err != nil {
+			return err
+		}
+		byID := make(map[string]models.Task, len(tasks))
+		for _, t := range tasks {
+			byID[t.ID] = t
+		}
 
-	type row struct {
-		Status string
-		Count  int64
-	}
+		// A story is only deleted when none of its live children are left behind
+		var children []models.Task
+		if err := tx.Select("id", "project_id").Where("project_id IN ?", ids).Find(&children).Error; err != nil {
+			return err
+		}
+		blocked := make(map[string]bool)
+		for _, child := range children {
+			if _, ok := byID[child.ID]; !ok {
+				blocked[child.ProjectID] = true
+			}
+		}
 
-	var rows []row
-	if err := db.Model(&models.Task{}).
-		Select("status, COUNT(*) as count").
-		Where("assignee_id = ?", targetUserID).
-		Group("status").
-		Scan(&rows).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute stats"})
+		for _, id := range ids {
+			task, ok := byID[id]
+			switch {
+			case !ok:
+				notFound = append(notFound, response.ExternalID(id))
+			case task.TaskType == models.TypeStory && blocked[id]:
+				hasChildren = append(hasChildren, response.ExternalID(id))
+			default:
+				if err := deleteTaskRow(tx, task, &purgeAfter); err != nil {
+					return err
+				}
+				recordAudit(tx, task.ID, userID, models.AuditDeleted)
+				deleted = append(deleted, task)
+				deletedIDs = append(deletedIDs, response.ExternalID(id))
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete tasks"})
 		return
 	}
 
-	// Initialize with zeros
-	counts := map[string]int64{
-		string(models.StatusTodo):       0,
-		string(models.StatusInProgress): 0,
-		string(models.StatusDone):       0,
+	if len(deleted) > 0 {
+		for _, t := range deleted {
+			invalidateStats(t.AssigneeID)
+		}
+		evt := map[string]any{
+			"type":    "task_bulk_deleted",
+			"taskIds": deletedIDs,
+			"userId":  response.ExternalID(userID),
+			"version": 1,
+		}
+		if bytes, err := json.Marshal(evt); err == nil {
+			realtime.GetHub().Broadcast(userID, bytes)
+		}
+	}
+
+	resp := gin.H{
+		"deleted":    deletedIDs,
+		"notFound":   notFound,
+		"purgeAfter": purgeAfter,
 	}
-	var total int64 = 0
-	for _, r := range rows {
-		counts[r.Status] = r.Count
-		total += r.Count
+	if len(hasChildren) > 0 {
+		resp["hasChildren"] = hasChildren
 	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetTrash handles GET /api/tasks/trash
+// Lists the caller's soft-deleted tasks that are still awaiting purge, most recently deleted first.
+// Supports page and limit like GetTasks.
+func GetTrash(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found in token",
+		})
+		return
+	}
+	page, limit, offset := parsePagination(c)
+
+	var total int64
+	var tasks []models.Task
+	err := requestDB(c).Transaction(func(tx *gorm.DB) error {
+		trashed := func() *gorm.DB {
+			return tx.Unscoped().Model(&models.Task{}).Where("user_id = ? AND deleted_at IS NOT NULL", userID)
+		}
+		if err := trashed().Count(&total).Error; err != nil {
+			return err
+		}
+		return trashed().Order("deleted_at desc").Limit(limit).Offset(offset).Find(&tasks).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tasks"})
+		return
+	}
+	enrichAssignees(requestDB(c), tasks)
+
+	resp := paginationMeta(total, page, limit)
+	resp["tasks"] = response.TasksView(tasks, c.GetString("role"))
+	resp["count"] = len(tasks)
+	c.JSON(http.StatusOK, resp)
+}
+
+// RestoreTask handles POST /api/tasks/:id/restore
+// Brings back one of the caller's soft-deleted tasks and cancels its scheduled purge.
+// Tasks that are not in the trash get 404.
+func RestoreTask(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found in token",
+		})
+		return
+	}
+
+	taskID := response.InternalTaskID(c.Param("id"))
+	if taskID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Task ID is required"})
+		return
+	}
+
+	task, err := findOwnedTask(requestDB(c).Unscoped().Where("deleted_at IS NOT NULL"), taskID, userID)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	if err := requestDB(c).Unscoped().Model(&task).UpdateColumns(map[string]any{
+		"deleted_at":  nil,
+		"purge_after": nil,
+	}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore task"})
+		return
+	}
+	task.DeletedAt = gorm.DeletedAt{}
+	task.PurgeAfter = nil
+
+	recordAudit(requestDB(c), task.ID, userID, models.AuditRestored)
+	invalidateStats(task.AssigneeID)
+	evt := map[string]any{
+		"type":    "task_restored",
+		"taskId":  response.ExternalID(task.ID),
+		"userId":  response.ExternalID(userID),
+		"version": 1,
+	}
+	if bytes, err := json.Marshal(evt); err == nil {
+		realtime.GetHub().Broadcast(userID, bytes)
+	}
+
+	enrichAssignee(requestDB(c), &task)
+	c.JSON(http.StatusOK, response.TaskView(task, c.GetString("role")))
+}
+
+// MergeTask handles POST /api/tasks/:id/merge
+// Folds a duplicate into the task named by "into": its comments move to the target and, when both
+// are stories, its subtasks/defects are re-parented to the target; then the duplicate is soft-deleted
+// (with the usual grace period), all in one transaction. Both tasks must be the caller's (admins: any
+// task). A story with children can only be merged into another story (409).
+func MergeTask(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in token"})
+		return
+	}
+
+	sourceID := response.InternalTaskID(c.Param("id"))
+	if sourceID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Task ID is required"})
+		return
+	}
+	var req MergeTaskRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	targetID := response.InternalTaskID(strings.TrimSpace(req.Into))
+	if targetID == sourceID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A task cannot be merged into itself"})
+		return
+	}
+
+	find := func(id string) (models.Task, error) {
+		if isAdmin(c) {
+			return findTask(requestDB(c), id)
+		}
+		return findOwnedTask(requestDB(c), id, userID)
+	}
+	source, err := find(sourceID)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	target, err := find(targetID)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	var children []models.Task
+	if source.TaskType == models.TypeStory {
+		if err := requestDB(c).Where("project_id = ?", source.ID).Order("created_at asc").Find(&children).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to merge tasks"})
+			return
+		}
+	}
+	if len(children) > 0 && target.TaskType != models.TypeStory {
+		c.JSON(http.StatusConflict, gin.H{"error": "Story has subtasks/defects; it can only be merged into another story"})
+		return
+	}
+	movedIDs := make([]string, 0, len(children))
+	for _, child := range children {
+		movedIDs = append(movedIDs, response.ExternalID(child.ID))
+	}
+
+	purgeAfter := time.Now().Add(deleteGracePeriod())
+	err = requestDB(c).Transaction(func(tx *gorm.DB) error {
+		if len(children) > 0 {
+			if err := tx.Model(&models.Task{}).Where("project_id = ?", source.ID).
+				Update("project_id", target.ID).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Model(&models.Comment{}).Where("task_id = ?", source.ID).
+			Update("task_id", target.ID).Error; err != nil {
+			return err
+		}
+		if err := deleteTaskRow(tx, source, &purgeAfter); err != nil {
+			return err
+		}
+		recordAudit(tx, source.ID, userID, models.AuditMerged)
+		recordAudit(tx, target.ID, userID, models.AuditUpdated)
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to merge tasks"})
+		return
+	}
+	invalidateStats(source.AssigneeID)
+
+	evt := map[string]any{
+		"type":     "task_merged",
+		"taskId":   response.ExternalID(source.ID),
+		"intoId":   response.ExternalID(target.ID),
+		"childIds": movedIDs,
+		"userId":   response.ExternalID(userID),
+		"version":  1,
+	}
+	if bytes, err := json.Marshal(evt); err == nil {
+		realtime.GetHub().Broadcast(userID, bytes)
+	}
+
+	enrichAssignee(requestDB(c), &target)
+	c.JSON(http.StatusOK, gin.H{
+		"task":          response.TaskView(target, c.GetString("role")),
+		"mergedId":      response.ExternalID(source.ID),
+		"movedChildIds": movedIDs,
+		"purgeAfter":    purgeAfter,
+	})
+}
+
+// GetStatsByUser handles GET /api/stats/:userid
+// Returns counts of tasks by status (todo, inProgress, done) where the assignee matches :userid.
+// Results are cached per user for STATS_CACHE_TTL; task writes invalidate the affected users.
+// Responses carry an ETag hashed from the returned body; a matching If-None-Match gets
+// 304 Not Modified.
+func GetStatsByUser(c *gin.Context) {
+	// Ensure request is authenticated
+	authUserID := c.GetString("user_id")
+	if authUserID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in token"})
+		return
+	}
+
+	targetUserID := c.Param("userid")
+	if strings.TrimSpace(targetUserID) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "userid is required"})
+		return
+	}
+
+	stats, err := cachedStatusStats(requestDB(c), targetUserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute stats"})
+		return
+	}
+	body, err := json.Marshal(stats)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute stats"})
+		return
+	}
+
+	etag := statsETag(body)
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", "private, no-cache")
+	if etagMatches(c.GetHeader("If-None-Match"), etag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.Data(http.StatusOK, "application/json; charset=utf-8", body)
+}
+
+// statsETag fingerprints a marshalled stats payload, so the tag always matches the body it is sent with
+func statsETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return fmt.Sprintf(`"%x"`, sum[:16])
+}
+
+// etagMatches reports whether an If-None-Match header value matches etag (weak comparison)
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// MoveTaskStatus handles POST /api/tasks/move-status
+// Moves all tasks owned by or assigned to the caller from one status to another (e.g. end-of-sprint cleanup).
+// Optional assigneeId narrows the set to one assignee. Moves that skip a workflow state (see
+// models.AllowedTransitions) get 422 like UpdateTaskStatus. Returns the number of tasks moved.
+func MoveTaskStatus(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in token"})
+		return
+	}
+
+	var req MoveTaskStatusRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !req.From.IsValid() || !req.To.IsValid() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status; allowed: todo, inProgress, done"})
+		return
+	}
+	if req.From == req.To {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from and to statuses must differ"})
+		return
+	}
+	if !checkStatusTransition(c, req.From, req.To) {
+		return
+	}
+
+	var movedIDs []string
+	err := requestDB(c).Transaction(func(tx *gorm.DB) error {
+		query := tx.Model(&models.Task{}).
+			Where("(user_id = ? OR assignee_id = ?) AND status = ?", userID, userID, req.From)
+		if assigneeID := strings.TrimSpace(req.AssigneeID); assigneeID != "" {
+			query = query.Where("assignee_id = ?", assigneeID)
+		}
+		if err := query.Pluck("id", &movedIDs).Error; err != nil {
+			return err
+		}
+		if len(movedIDs) == 0 {
+			return nil
+		}
+		if err := tx.Model(&models.Task{}).Where("id IN ?", movedIDs).Update("status", req.To).Error; err != nil {
+			return err
+		}
+		for _, id := range movedIDs {
+			recordStatusAudit(tx, id, userID, models.AuditStatusChanged, req.From, req.To)
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to move task statuses"})
+		return
+	}
+	if len(movedIDs) > 0 {
+		invalidateAllStats()
+		evt := map[string]any{
+			"type":    "task_bulk_status_changed",
+			"taskIds": response.ExternalIDs(movedIDs),
+			"status":  req.To,
+			"userId":  response.ExternalID(userID),
+			"version": 1,
+		}
+		if bytes, err := json.Marshal(evt); err == nil {
+			realtime.GetHub().Broadcast(userID, bytes)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"updated": len(movedIDs),
+		"from":    req.From,
+		"to":      req.To,
+	})
+}
+
+// BulkUpdateStatus handles PATCH /api/tasks/status (also mounted at PATCH /api/tasks/bulk/status)
+// Sets the status of up to 100 tasks owned by the caller with a single UPDATE in one transaction. Ids
+// that are not found or not owned are reported under "notFound" (and the older "skipped"), and tasks
+// whose move would skip a workflow state under "invalidTransition"; neither fails the request.
+// One task_bulk_status_changed event lists the updated ids.
+func BulkUpdateStatus(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in token"})
+		return
+	}
+
+	var req BulkUpdateStatusRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !req.Status.IsValid() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status; allowed: todo, inProgress, done"})
+		return
+	}
+	var ids []string
+	for _, id := range req.IDs {
+		if id = response.InternalTaskID(strings.TrimSpace(id)); id != "" && !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ids must contain at least one task id"})
+		return
+	}
+	if len(ids) > maxBulkStatusIDs {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("At most %d ids per request", maxBulkStatusIDs)})
+		return
+	}
+
+	updatedIDs := []string{}
+	skipped := []string{}
+	invalid := []string{}
+	err := requestDB(c).Transaction(func(tx *gorm.DB) error {
+		var tasks []models.Task
+		if err := tx.Where("id IN ? AND user_id = ?", ids, userID).Find(&tasks).Error; err != nil {
+			return err
+		}
+		byID := make(map[string]models.Task, len(tasks))
+		for _, t := range tasks {
+			byID[t.ID] = t
+		}
+
+		var changed []models.Task
+		for _, id := range ids {
+			task, ok := byID[id]
+			switch {
+			case !ok:
+				skipped = append(skipped, response.ExternalID(id))
+			case !models.CanTransition(task.Status, req.Status):
+				invalid = append(invalid, response.ExternalID(id))
+			default:
+				updatedIDs = append(updatedIDs, response.ExternalID(id))
+				if task.Status != req.Status {
+					changed = append(changed, task)
+				}
+			}
+		}
+		if len(changed) == 0 {
+			return nil
+		}
+		changedIDs := make([]string, 0, len(changed))
+		for _, task := range changed {
+			changedIDs = append(changedIDs, task.ID)
+		}
+		if err := tx.Model(&models.Task{}).Where("id IN ? AND user_id = ?", changedIDs, userID).
+			Update("status", req.Status).Error; err != nil {
+			return err
+		}
+		for _, task := range changed {
+			recordStatusAudit(tx, task.ID, userID, models.AuditStatusChanged, task.Status, req.Status)
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update task statuses"})
+		return
+	}
+	if len(updatedIDs) > 0 {
+		invalidateAllStats()
+		evt := map[string]any{
+			"type":    "task_bulk_status_changed",
+			"taskIds": updatedIDs,
+			"status":  req.Status,
+			"userId":  response.ExternalID(userID),
+			"version": 1,
+		}
+		if bytes, err := json.Marshal(evt); err == nil {
+			realtime.GetHub().Broadcast(userID, bytes)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"updated":           len(updatedIDs),
+		"updatedIds":        updatedIDs,
+		"notFound":          skipped,
+		"skipped":           skipped,
+		"invalidTransition": invalid,
+		"status":            req.Status,
+	})
+}
+
+// defaultArchiveAge is how long a done task must have been untouched before ArchiveDoneTasks archives it
+const defaultArchiveAge = 14 * 24 * time.Hour
+
+// ArchiveDoneTasks handles POST /api/tasks/archive-done?olderThan=14d
+// Archives, in one transaction, the caller's done tasks not updated within olderThan (days such as 14d,
+// or a duration such as 36h; default 14d). Archived tasks drop out of task lists. Returns the count
+// and broadcasts a task_archived event per task.
+func ArchiveDoneTasks(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in token"})
+		return
+	}
+	age, ok := parseAgeParam(c, "olderThan", defaultArchiveAge)
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	cutoff := now.Add(-age)
+	var archivedIDs []string
+	err := requestDB(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Task{}).
+			Where("user_id = ? AND status = ? AND archived_at IS NULL AND updated_at < ?", userID, models.StatusDone, cutoff).
+			Pluck("id", &archivedIDs).Error; err != nil {
+			return err
+		}
+		if len(archivedIDs) == 0 {
+			return nil
+		}
+		// UpdateColumn keeps updated_at, which still says when the task was last worked on
+		if err := tx.Model(&models.Task{}).Where("id IN ?", archivedIDs).UpdateColumn("archived_at", now).Error; err != nil {
+			return err
+		}
+		for _, id := range archivedIDs {
+			recordAudit(tx, id, userID, models.AuditArchived)
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to archive tasks"})
+		return
+	}
+
+	for _, id := range archivedIDs {
+		evt := map[string]any{
+			"type":    "task_archived",
+			"taskId":  response.ExternalID(id),
+			"userId":  response.ExternalID(userID),
+			"version": 1,
+		}
+		if bytes, err := json.Marshal(evt); err == nil {
+			realtime.GetHub().Broadcast(userID, bytes)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"archived": len(archivedIDs),
+		"cutoff":   cutoff,
+	})
+}
+
+// ArchiveTask handles PATCH /api/tasks/:id/archive
+// Hides one of the caller's tasks from task lists by setting archived_at. Archiving an archived
+// task is a no-op that keeps the original time.
+func ArchiveTask(c *gin.Context) {
+	setTaskArchived(c, true)
+}
+
+// UnarchiveTask handles PATCH /api/tasks/:id/unarchive
+// Clears archived_at so the task shows up in task lists again.
+func UnarchiveTask(c *gin.Context) {
+	setTaskArchived(c, false)
+}
+
+// setTaskArchived archives or unarchives the caller's task :id, recording an audit entry and
+// broadcasting task_archived/task_unarchived when the flag actually changes
+func setTaskArchived(c *gin.Context, archive bool) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in token"})
+		return
+	}
+
+	taskID := response.InternalTaskID(c.Param("id"))
+	if taskID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Task ID is required"})
+		return
+	}
+
+	task, err := findOwnedTask(requestDB(c), taskID, userID)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	if archive != (task.ArchivedAt != nil) {
+		var archivedAt *time.Time
+		action, eventType := models.AuditUnarchived, "task_unarchived"
+		if archive {
+			now := time.Now()
+			archivedAt = &now
+			action, eventType = models.AuditArchived, "task_archived"
+		}
+		// UpdateColumn keeps updated_at, which still says when the task was last worked on
+		if err := requestDB(c).Model(&task).UpdateColumn("archived_at", archivedAt).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update task"})
+			return
+		}
+		task.ArchivedAt = archivedAt
+
+		recordAudit(requestDB(c), task.ID, userID, action)
+		evt := map[string]any{
+			"type":    eventType,
+			"taskId":  response.ExternalID(task.ID),
+			"userId":  response.ExternalID(userID),
+			"version": 1,
+		}
+		if bytes, err := json.Marshal(evt); err == nil {
+			realtime.GetHub().Broadcast(userID, bytes)
+		}
+	}
+
+	enrichAssignee(requestDB(c), &task)
+	c.JSON(http.StatusOK, response.TaskView(task, c.GetString("role")))
+}
+
+// escapeLike escapes LIKE wildcards so s is matched literally (use with ESCAPE '\')
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+// autocompleteLimit caps the number of suggestions returned by AutocompleteTasks
+const autocompleteLimit = 10
+
+// AutocompleteTasks handles GET /api/tasks/autocomplete?q=&limit=
+// Returns up to 10 lightweight {id,title,taskType} suggestions whose title contains q (team-wide).
+// Only those columns are selected and no assignee enrichment is done.
+func AutocompleteTasks(c *gin.Context) {
+	q := strings.TrimSpace(c.Query("q"))
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(autocompleteLimit)))
+	if err != nil || limit < 1 || limit > autocompleteLimit {
+		limit = autocompleteLimit
+	}
+
+	type suggestion struct {
+		ID       string          `json:"id"`
+		Title    string          `json:"title"`
+		TaskType models.TaskType `json:"taskType"`
+	}
+	suggestions := []suggestion{}
+	if q == "" {
+		c.JSON(http.StatusOK, gin.H{"tasks": suggestions})
+		return
+	}
+
+	escaped := escapeLike(q)
+	err = requestDB(c).Model(&models.Task{}).
+		Select("id", "title", "task_type").
+		Where(`title LIKE ? ESCAPE '\'`, "%"+escaped+"%").
+		// Prefix matches first, then alphabetical
+		Order(clause.OrderBy{Expression: clause.Expr{SQL: `CASE WHEN title LIKE ? ESCAPE '\' THEN 0 ELSE 1 END, title ASC`, Vars: []any{escaped + "%"}}}).
+		Limit(limit).
+		Scan(&suggestions).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch suggestions"})
+		return
+	}
+	for i := range suggestions {
+		suggestions[i].ID = response.ExternalID(suggestions[i].ID)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tasks": suggestions})
+}
+
+// SLA risk window defaults and caps, in hours
+const (
+	defaultSLARiskHours = 24
+	maxSLARiskHours     = 24 * 30
+)
+
+// GetSLARisk handles GET /api/tasks/sla-risk?hours=N
+// Returns open (not done), non-archived tasks team-wide that are due within the next N hours (default 24, max 720)
+// as atRisk, and those already past due as breached. Both lists are sorted by deadline, most urgent
+// first, and each task carries hoursRemaining (negative once breached). Tasks without a parseable
+// end date are skipped.
+func GetSLARisk(c *gin.Context) {
+	hours, err := strconv.Atoi(c.DefaultQuery("hours", strconv.Itoa(defaultSLARiskHours)))
+	if err != nil || hours < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "hours must be a positive integer"})
+		return
+	}
+	hours = min(hours, maxSLARiskHours)
+
+	// End dates are free-form strings, so deadlines are computed in Go
+	var open []models.Task
+	if err := requestDB(c).Scopes(models.NotArchived).Where("status <> ?", models.StatusDone).Find(&open).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tasks"})
+		return
+	}
+
+	now := time.Now()
+	horizon := now.Add(time.Duration(hours) * time.Hour)
+	type dueTask struct {
+		task     models.Task
+		deadline time.Time
+	}
+	var atRisk, breached []dueTask
+	for _, t := range open {
+		deadline, ok := t.Deadline()
+		switch {
+		case !ok || deadline.After(horizon):
+			continue
+		case deadline.Before(now):
+			breached = append(breached, dueTask{t, deadline})
+		default:
+			atRisk = append(atRisk, dueTask{t, deadline})
+		}
+	}
+
+	// Assignees are loaded once for both lists; enrichment is best effort
+	var assigneeIDs []string
+	for _, d := range append(atRisk, breached...) {
+		assigneeIDs = append(assigneeIDs, d.task.AssigneeID)
+	}
+	userByID, err := lookupUsers(requestDB(c), assigneeIDs)
+	lookupOK := err == nil
+
+	role := c.GetString("role")
+	views := func(due []dueTask) []map[string]any {
+		slices.SortFunc(due, func(a, b dueTask) int { return a.deadline.Compare(b.deadline) })
+		out := make([]map[string]any, 0, len(due))
+		for _, d := range due {
+			applyAssignee(&d.task, userByID, lookupOK)
+			view := response.TaskView(d.task, role)
+			view["hoursRemaining"] = math.Round(d.deadline.Sub(now).Hours()*10) / 10
+			out = append(out, view)
+		}
+		return out
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"hours":    hours,
+		"atRisk":   views(atRisk),
+		"breached": views(breached),
+	})
+}
+
+// ReassignTasks handles POST /api/tasks/reassign
+// Moves every task assigned to fromAssigneeId whose status is in statuses to toAssigneeId.
+// statuses defaults to the open statuses (todo, inProgress) so completed work keeps its credit.
+// The fromUserId/toUserId form hands off a departing user's whole workload, so there statuses
+// defaults to every status.
+// Only assignee_id changes; ownership (user_id) stays. Admin only (the route's RoleMiddleware), since
+// it moves other users' work.
+// Each moved task gets an audit entry; broadcasts task_updated per moved task plus one
+// tasks_reassigned summary.
+func ReassignTasks(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in token"})
+		return
+	}
+
+	var req ReassignTasksRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	handOff := req.FromAssigneeID == "" && req.ToAssigneeID == "" && (req.FromUserID != "" || req.ToUserID != "")
+	if req.FromAssigneeID == "" {
+		req.FromAssigneeID = req.FromUserID
+	}
+	if req.ToAssigneeID == "" {
+		req.ToAssigneeID = req.ToUserID
+	}
+	if req.FromAssigneeID == "" || req.ToAssigneeID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "fromAssigneeId and toAssigneeId are required"})
+		return
+	}
+	if req.FromAssigneeID == req.ToAssigneeID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "fromAssigneeId and toAssigneeId must differ"})
+		return
+	}
+	if len(req.Statuses) == 0 {
+		req.Statuses = []models.TaskStatus{models.StatusTodo, models.StatusInProgress}
+		if handOff {
+			req.Statuses = append(req.Statuses, models.StatusDone)
+		}
+	}
+	for _, st := range req.Statuses {
+		if !st.IsValid() {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status; allowed: todo, inProgress, done"})
+			return
+		}
+	}
+
+	db := requestDB(c)
+	var users int64
+	if err := db.Model(&models.User{}).Where("id IN ?", []string{req.FromAssigneeID, req.ToAssigneeID}).Count(&users).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reassign tasks"})
+		return
+	}
+	if users != 2 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "fromAssigneeId and toAssigneeId must be existing users"})
+		return
+	}
+
+	var movedIDs []string
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Task{}).
+			Where("assignee_id = ? AND status IN ?", req.FromAssigneeID, req.Statuses).
+			Pluck("id", &movedIDs).Error; err != nil {
+			return err
+		}
+		if len(movedIDs) == 0 {
+			return nil
+		}
+		if err := tx.Model(&models.Task{}).Where("id IN ?", movedIDs).Update("assignee_id", req.ToAssigneeID).Error; err != nil {
+			return err
+		}
+		for _, id := range movedIDs {
+			recordAudit(tx, id, userID, models.AuditUpdated)
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reassign tasks"})
+		return
+	}
+	invalidateStats(req.FromAssigneeID, req.ToAssigneeID)
+
+	if len(movedIDs) > 0 {
+		// Notify the actor and both assignees
+		var recipients []string
+		for _, id := range []string{userID, req.FromAssigneeID, req.ToAssigneeID} {
+			if !slices.Contains(recipients, id) {
+				recipients = append(recipients, id)
+			}
+		}
+		broadcast := func(evt map[string]any) {
+			if bytes, err := json.Marshal(evt); err == nil {
+				for _, id := range recipients {
+					realtime.GetHub().Broadcast(id, bytes)
+				}
+			}
+		}
+		for _, id := range movedIDs {
+			broadcast(map[string]any{
+				"type":    "task_updated",
+				"taskId":  response.ExternalID(id),
+				"userId":  response.ExternalID(userID),
+				"version": 1,
+			})
+		}
+		broadcast(map[string]any{
+			"type":           "tasks_reassigned",
+			"taskIds":        response.ExternalIDs(movedIDs),
+			"fromAssigneeId": response.ExternalID(req.FromAssigneeID),
+			"toAssigneeId":   response.ExternalID(req.ToAssigneeID),
+			"userId":         response.ExternalID(userID),
+			"version":        1,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"reassigned":     len(movedIDs),
+		"taskIds":        response.ExternalIDs(movedIDs),
+		"fromAssigneeId": response.ExternalID(req.FromAssigneeID),
+		"toAssigneeId":   response.ExternalID(req.ToAssigneeID),
+	})
+}
+
+// PreviewEffort handles GET /api/effort?startDate=&endDate=
+// Returns the effort (in days) CreateTask would compute for the range without saving anything.
+// valid is false when either date is missing or unparseable (effort then falls back to 1).
+func PreviewEffort(c *gin.Context) {
+	startDate := c.Query("startDate")
+	endDate := c.Query("endDate")
+
+	_, okStart := parseDateFlexible(startDate)
+	_, okEnd := parseDateFlexible(endDate)
 
 	c.JSON(http.StatusOK, gin.H{
-		"todo":       counts[string(models.StatusTodo)],
-		"inProgress": counts[string(models.StatusInProgress)],
-		"done":       counts[string(models.StatusDone)],
-		"total":      total,
+		"startDate": startDate,
+		"endDate":   endDate,
+		"effort":    calculateEffortDays(startDate, endDate),
+		"valid":     okStart && okEnd,
 	})
 }
@@ -2,19 +2,30 @@ package handlers
 
 import (
 	"bytes"
+	"database/sql"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"task-management-api/internal/auth"
 	"task-management-api/internal/database"
 	"task-management-api/internal/middleware"
 	"task-management-api/internal/models"
+	"task-management-api/internal/realtime"
 	"task-management-api/internal/testutil"
+	"task-management-api/internal/worker"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
 )
 
 func TestCreateTask_Success(t *testing.T) {
@@ -31,7 +42,7 @@ func TestCreateTask_Success(t *testing.T) {
 	r.Use(middleware.JWTAuthMiddleware())
 	r.POST("/api/tasks", CreateTask)
 
-	token, err := auth.GenerateToken("u-1", "alice")
+	token, err := auth.GenerateToken("u-1", "alice", models.RoleMember)
 	require.NoError(t, err)
 
 	payload := map[string]any{
@@ -56,3 +67,2738 @@ func TestCreateTask_Success(t *testing.T) {
 	require.Equal(t, 2, created.Effort) // 2025-01-01 to 2025-01-03 => 2 days
 	require.Equal(t, assignee.ID, created.Assignee.ID)
 }
+
+func TestForecastEffort_UsesHistoricalOverrun(t *testing.T) {
+	db, err := testutil.NewInMemoryDB()
+	require.NoError(t, err)
+
+	// Seed 5 done tasks that each took twice the estimate
+	for i := 0; i < 5; i++ {
+		task := models.Task{
+			ID:           fmt.Sprintf("task-hist-%d", i),
+			Title:        "Done",
+			Status:       models.StatusDone,
+			AssigneeID:   "u-1",
+			Effort:       3,
+			ActualEffort: 6,
+			TaskType:     models.TypeStory,
+			UserID:       "u-1",
+		}
+		require.NoError(t, db.Create(&task).Error)
+	}
+
+	require.Equal(t, 20, ForecastEffort("u-1", 10, db))
+	// No history => estimate unchanged
+	require.Equal(t, 10, ForecastEffort("u-2", 10, db))
+}
+
+// recordingClient is a realtime.Client that keeps every message it is sent.
+type recordingClient struct {
+	mu       sync.Mutex
+	messages [][]byte
+}
+
+func (r *recordingClient) Send(message []byte) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.messages = append(r.messages, message)
+	return true
+}
+
+func (r *recordingClient) Close() {}
+
+func (r *recordingClient) IsAlive(time.Duration) bool { return true }
+
+func (r *recordingClient) events(t *testing.T) []map[string]any {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([]map[string]any, 0, len(r.messages))
+	for _, m := range r.messages {
+		var evt map[string]any
+		require.NoError(t, json.Unmarshal(m, &evt))
+		out = append(out, evt)
+	}
+	return out
+}
+
+func TestCreateTask_BroadcastPayloadMode(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := testutil.NewInMemoryDB()
+	require.NoError(t, err)
+	database.DB = db
+
+	r := gin.New()
+	r.Use(middleware.JWTAuthMiddleware())
+	r.POST("/api/tasks", CreateTask)
+
+	token, err := auth.GenerateToken("u-1", "alice", models.RoleMember)
+	require.NoError(t, err)
+
+	createTask := func() map[string]any {
+		client := &recordingClient{}
+		realtime.GetHub().Register("u-1", client)
+		defer realtime.GetHub().Unregister("u-1", client)
+
+		body, _ := json.Marshal(map[string]any{
+			"title":       "Payload Task",
+			"description": "Desc",
+			"assignee":    map[string]string{"id": "u-1", "name": "alice"},
+			"startDate":   "2025-01-01",
+			"endDate":     "2025-01-02",
+			"taskType":    "story",
+		})
+		req := httptest.NewRequest(http.MethodPost, "/api/tasks", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		require.Equal(t, http.StatusCreated, w.Code)
+
+		events := client.events(t)
+		require.Len(t, events, 1)
+		return events[0]
+	}
+
+	// Default: lightweight payload only
+	evt := createTask()
+	require.Equal(t, "task_created", evt["type"])
+	require.NotContains(t, evt, "task")
+
+	// Opt-in: full task embedded
+	t.Setenv("REALTIME_FULL_PAYLOAD", "true")
+	evt = createTask()
+	task, ok := evt["task"].(map[string]any)
+	require.True(t, ok, "expected embedded task object")
+	require.Equal(t, evt["taskId"], task["id"])
+	require.Equal(t, "Payload Task", task["title"])
+}
+
+func TestGetTasks_JSONAPIFormat(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := testutil.NewInMemoryDB()
+	require.NoError(t, err)
+	database.DB = db
+
+	require.NoError(t, db.Create(&models.User{ID: "u-1", Username: "alice", Password: "x"}).Error)
+	require.NoError(t, db.Create(&models.Task{ID: "task-1", Title: "Story", TaskType: models.TypeStory, AssigneeID: "u-1", UserID: "u-1"}).Error)
+	require.NoError(t, db.Create(&models.Task{ID: "task-2", Title: "Sub", TaskType: models.TypeSubtask, ProjectID: "task-1", AssigneeID: "u-1", UserID: "u-1"}).Error)
+
+	r := gin.New()
+	r.Use(middleware.JWTAuthMiddleware())
+	r.GET("/api/tasks", GetTasks)
+
+	token, err := auth.GenerateToken("u-1", "alice", models.RoleMember)
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodGet, "/api/tasks?format=jsonapi", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "application/vnd.api+json", w.Header().Get("Content-Type"))
+
+	var doc struct {
+		Data []struct {
+			Type string `json:"type"`
+			ID   string `json:"id"`
+		} `json:"data"`
+		Included []struct {
+			Type string `json:"type"`
+			ID   string `json:"id"`
+		} `json:"included"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &doc))
+	require.Len(t, doc.Data, 2)
+	require.Len(t, doc.Included, 1)
+	require.Equal(t, "u-1", doc.Included[0].ID)
+}
+
+func TestGetTasks_DefaultSortFromEnv(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := testutil.NewInMemoryDB()
+	require.NoError(t, err)
+	database.DB = db
+
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	older := models.Task{ID: "task-old", Title: "Old", TaskType: models.TypeStory, UserID: "u-1"}
+	older.CreatedAt = base
+	newer := models.Task{ID: "task-new", Title: "New", TaskType: models.TypeStory, UserID: "u-1"}
+	newer.CreatedAt = base.Add(time.Hour)
+	require.NoError(t, db.Create(&older).Error)
+	require.NoError(t, db.Create(&newer).Error)
+
+	r := gin.New()
+	r.Use(middleware.JWTAuthMiddleware())
+	r.GET("/api/tasks", GetTasks)
+	token, err := auth.GenerateToken("u-1", "alice", models.RoleMember)
+	require.NoError(t, err)
+
+	firstID := func(url string) string {
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+		var resp struct {
+			Tasks []models.Task `json:"tasks"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		require.NotEmpty(t, resp.Tasks)
+		return resp.Tasks[0].ID
+	}
+
+	require.Equal(t, "task-new", firstID("/api/tasks"))
+
+	t.Setenv("DEFAULT_SORT", "asc")
+	require.NoError(t, ValidateConfig())
+	require.Equal(t, "task-old", firstID("/api/tasks"))
+	// Explicit sort still wins
+	require.Equal(t, "task-new", firstID("/api/tasks?sort=desc"))
+
+	t.Setenv("DEFAULT_SORT", "sideways")
+	require.Error(t, ValidateConfig())
+}
+
+func TestMoveTaskStatus_OnlyMatchingTasks(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := testutil.NewInMemoryDB()
+	require.NoError(t, err)
+	database.DB = db
+
+	seed := []models.Task{
+		{ID: "task-1", Status: models.StatusInProgress, UserID: "u-1"},                    // owned, matches
+		{ID: "task-2", Status: models.StatusInProgress, UserID: "u-9", AssigneeID: "u-1"}, // assigned, matches
+		{ID: "task-3", Status: models.StatusDone, UserID: "u-1"},                          // wrong status
+		{ID: "task-4", Status: models.StatusInProgress, UserID: "u-9"},                    // not mine
+	}
+	for _, task := range seed {
+		task.Title = task.ID
+		task.TaskType = models.TypeStory
+		require.NoError(t, db.Create(&task).Error)
+	}
+
+	r := gin.New()
+	r.Use(middleware.JWTAuthMiddleware())
+	r.POST("/api/tasks/move-status", MoveTaskStatus)
+	token, err := auth.GenerateToken("u-1", "alice", models.RoleMember)
+	require.NoError(t, err)
+
+	post := func(payload map[string]string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(payload)
+		req := httptest.NewRequest(http.MethodPost, "/api/tasks/move-status", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	require.Equal(t, http.StatusBadRequest, post(map[string]string{"from": "inProgress", "to": "foo"}).Code)
+
+	w := post(map[string]string{"from": "inProgress", "to": "todo"})
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp struct {
+		Updated int `json:"updated"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Equal(t, 2, resp.Updated)
+
+	want := map[string]models.TaskStatus{
+		"task-1": models.StatusTodo,
+		"task-2": models.StatusTodo,
+		"task-3": models.StatusDone,
+		"task-4": models.StatusInProgress,
+	}
+	for id, status := range want {
+		var got models.Task
+		require.NoError(t, db.First(&got, "id = ?", id).Error)
+		require.Equal(t, status, got.Status, id)
+	}
+
+	// Skipping inProgress is rejected like a single status change, and nothing moves
+	w = post(map[string]string{"from": "todo", "to": "done"})
+	require.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	var rejected struct {
+		Allowed []string `json:"allowed"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &rejected))
+	require.Equal(t, []string{"inProgress"}, rejected.Allowed)
+	var done int64
+	require.NoError(t, db.Model(&models.Task{}).Where("status = ?", models.StatusDone).Count(&done).Error)
+	require.Equal(t, int64(1), done)
+}
+
+func TestGetTaskByID_NotFoundProblem(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := testutil.NewInMemoryDB()
+	require.NoError(t, err)
+	database.DB = db
+
+	r := gin.New()
+	r.Use(middleware.ErrorHandler())
+	r.Use(middleware.JWTAuthMiddleware())
+	r.GET("/api/tasks/:id", GetTaskByID)
+
+	token, err := auth.GenerateToken("u-1", "alice", models.RoleMember)
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodGet, "/api/tasks/task-missing", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusNotFound, w.Code)
+	require.Equal(t, middleware.ProblemContentType, w.Header().Get("Content-Type"))
+	require.Contains(t, w.Body.String(), "Task task-missing not found")
+}
+
+func TestCreateTask_BlockSubtaskOnDoneStory(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := testutil.NewInMemoryDB()
+	require.NoError(t, err)
+	database.DB = db
+
+	require.NoError(t, db.Create(&models.Task{ID: "task-done", Title: "Done story", Status: models.StatusDone, TaskType: models.TypeStory, UserID: "u-1"}).Error)
+	require.NoError(t, db.Create(&models.Task{ID: "task-open", Title: "Open story", Status: models.StatusInProgress, TaskType: models.TypeStory, UserID: "u-1"}).Error)
+
+	r := gin.New()
+	r.Use(middleware.JWTAuthMiddleware())
+	r.POST("/api/tasks", CreateTask)
+	token, err := auth.GenerateToken("u-1", "alice", models.RoleMember)
+	require.NoError(t, err)
+
+	createSubtask := func(parentID string) int {
+		body, _ := json.Marshal(map[string]any{
+			"title":       "Sub",
+			"description": "Desc",
+			"assignee":    map[string]string{"id": "u-1", "name": "alice"},
+			"startDate":   "2025-01-01",
+			"endDate":     "2025-01-02",
+			"taskType":    "subtask",
+			"projectId":   parentID,
+		})
+		req := httptest.NewRequest(http.MethodPost, "/api/tasks", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	// Off by default: done parents are accepted
+	require.Equal(t, http.StatusCreated, createSubtask("task-done"))
+
+	t.Setenv("BLOCK_SUBTASK_ON_DONE_STORY", "true")
+	require.Equal(t, http.StatusUnprocessableEntity, createSubtask("task-done"))
+	require.Equal(t, http.StatusCreated, createSubtask("task-open"))
+}
+
+func TestGetTasks_StreamNDJSON(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := testutil.NewInMemoryDB()
+	require.NoError(t, err)
+	database.DB = db
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, db.Create(&models.Task{ID: fmt.Sprintf("task-%d", i), Title: "T", TaskType: models.TypeStory,
+			UserID: "u-1", AssigneeID: "u-1"}).Error)
+	}
+	// Several batches, each enriched with users, must not need a second connection
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	defer func(n int) { streamBatchSize = n }(streamBatchSize)
+	streamBatchSize = 2
+
+	r := gin.New()
+	r.Use(middleware.JWTAuthMiddleware())
+	r.GET("/api/tasks", GetTasks)
+	token, err := auth.GenerateToken("u-1", "alice", models.RoleMember)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/tasks?stream=true&limit=1", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	require.Len(t, lines, 3) // pagination ignored while streaming
+	seen := map[string]bool{}
+	for _, line := range lines {
+		var task models.Task
+		require.NoError(t, json.Unmarshal([]byte(line), &task))
+		require.NotEmpty(t, task.ID)
+		require.False(t, seen[task.ID], "task %s streamed twice", task.ID)
+		seen[task.ID] = true
+	}
+}
+
+func TestPreviewEffort(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/api/effort", PreviewEffort)
+
+	preview := func(query string) (int, bool) {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/effort"+query, nil))
+		require.Equal(t, http.StatusOK, w.Code)
+		var resp struct {
+			Effort int  `json:"effort"`
+			Valid  bool `json:"valid"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		return resp.Effort, resp.Valid
+	}
+
+	effort, valid := preview("?startDate=2025-01-01&endDate=2025-01-06")
+	require.True(t, valid)
+	require.Equal(t, 5, effort)
+
+	// Reversed ranges are normalized
+	effort, valid = preview("?startDate=2025-01-06&endDate=2025-01-01")
+	require.True(t, valid)
+	require.Equal(t, 5, effort)
+
+	effort, valid = preview("?startDate=soon&endDate=2025-01-01")
+	require.False(t, valid)
+	require.Equal(t, 1, effort)
+}
+
+func TestGetTaskByID_StripIDPrefixRoundTrip(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := testutil.NewInMemoryDB()
+	require.NoError(t, err)
+	database.DB = db
+	require.NoError(t, db.Create(&models.Task{ID: "task-123", Title: "T", TaskType: models.TypeStory, UserID: "u-1"}).Error)
+
+	t.Setenv("STRIP_ID_PREFIX", "true")
+	r := gin.New()
+	r.Use(middleware.JWTAuthMiddleware())
+	r.GET("/api/tasks/:id", GetTaskByID)
+	token, err := auth.GenerateToken("u-1", "alice", models.RoleMember)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/tasks/123", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Equal(t, "123", resp["id"])
+}
+
+func TestUpdateTask_StrictJSONRejectsUnknownFields(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := testutil.NewInMemoryDB()
+	require.NoError(t, err)
+	database.DB = db
+
+	require.NoError(t, db.Create(&models.Task{ID: "task-1", Title: "Original", Status: models.StatusTodo, TaskType: models.TypeStory, UserID: "u-1"}).Error)
+
+	r := gin.New()
+	r.Use(middleware.JWTAuthMiddleware())
+	r.PUT("/api/tasks/:id", UpdateTask)
+
+	token, err := auth.GenerateToken("u-1", "alice", models.RoleMember)
+	require.NoError(t, err)
+
+	send := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPut, "/api/tasks/task-1", strings.NewReader(`{"titel":"Typo"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	// Lenient by default: the unknown field is ignored
+	w := send()
+	require.Equal(t, http.StatusOK, w.Code)
+
+	t.Setenv("STRICT_JSON", "true")
+	w = send()
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Contains(t, w.Body.String(), "titel")
+
+	var task models.Task
+	require.NoError(t, db.First(&task, "id = ?", "task-1").Error)
+	require.Equal(t, "Original", task.Title)
+}
+
+func TestReassignTasks_OnlySpecifiedStatuses(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := testutil.NewInMemoryDB()
+	require.NoError(t, err)
+	database.DB = db
+
+	require.NoError(t, db.Create(&models.User{ID: "u-2", Username: "bob", Password: "x"}).Error)
+	require.NoError(t, db.Create(&models.User{ID: "u-3", Username: "carol", Password: "x"}).Error)
+
+	seed := []models.Task{
+		{ID: "task-1", Status: models.StatusInProgress, AssigneeID: "u-2"}, // moves
+		{ID: "task-2", Status: models.StatusTodo, AssigneeID: "u-2"},       // status not requested
+		{ID: "task-3", Status: models.StatusDone, AssigneeID: "u-2"},       // status not requested
+		{ID: "task-4", Status: models.StatusInProgress, AssigneeID: "u-9"}, // other assignee
+	}
+	for _, task := range seed {
+		task.Title = task.ID
+		task.TaskType = models.TypeStory
+		task.UserID = "u-1"
+		require.NoError(t, db.Create(&task).Error)
+	}
+
+	r := gin.New()
+	r.Use(middleware.JWTAuthMiddleware())
+	r.POST("/api/tasks/reassign", middleware.RoleMiddleware(models.RoleAdmin), ReassignTasks)
+	token, err := auth.GenerateToken("u-1", "alice", models.RoleAdmin)
+	require.NoError(t, err)
+
+	post := func(payload map[string]any) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(payload)
+		req := httptest.NewRequest(http.MethodPost, "/api/tasks/reassign", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	// Unknown target user
+	w := post(map[string]any{"fromAssigneeId": "u-2", "toAssigneeId": "u-404", "statuses": []string{"inProgress"}})
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = post(map[string]any{"fromAssigneeId": "u-2", "toAssigneeId": "u-3", "statuses": []string{"inProgress"}})
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp struct {
+		Reassigned int      `json:"reassigned"`
+		TaskIDs    []string `json:"taskIds"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Equal(t, 1, resp.Reassigned)
+	require.Equal(t, []string{"task-1"}, resp.TaskIDs)
+
+	want := map[string]string{"task-1": "u-3", "task-2": "u-2", "task-3": "u-2", "task-4": "u-9"}
+	for id, assignee := range want {
+		var task models.Task
+		require.NoError(t, db.First(&task, "id = ?", id).Error)
+		require.Equal(t, assignee, task.AssigneeID, id)
+	}
+
+	// The move is audited per task
+	var audits []models.TaskAudit
+	require.NoError(t, db.Where("action = ?", models.AuditUpdated).Find(&audits).Error)
+	require.Len(t, audits, 1)
+	require.Equal(t, "task-1", audits[0].TaskID)
+	require.Equal(t, "u-1", audits[0].UserID)
+
+	// Members may not move other users' work
+	token, err = auth.GenerateToken("u-2", "bob", models.RoleMember)
+	require.NoError(t, err)
+	w = post(map[string]any{"fromAssigneeId": "u-3", "toAssigneeId": "u-2"})
+	require.Equal(t, http.StatusForbidden, w.Code)
+	var task models.Task
+	require.NoError(t, db.First(&task, "id = ?", "task-1").Error)
+	require.Equal(t, "u-3", task.AssigneeID)
+}
+
+func TestAutocompleteTasks_ShapeAndCap(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := testutil.NewInMemoryDB()
+	require.NoError(t, err)
+	database.DB = db
+
+	for i := 0; i < autocompleteLimit+5; i++ {
+		require.NoError(t, db.Create(&models.Task{
+			ID: fmt.Sprintf("task-%d", i), Title: fmt.Sprintf("Login flow %02d", i), Description: "long text",
+			TaskType: models.TypeStory, UserID: "u-9",
+		}).Error)
+	}
+	require.NoError(t, db.Create(&models.Task{ID: "task-x", Title: "Fix 100% CPU", TaskType: models.TypeDefect, ProjectID: "task-0", UserID: "u-9"}).Error)
+
+	r := gin.New()
+	r.GET("/api/tasks/autocomplete", AutocompleteTasks)
+	get := func(query string) []map[string]any {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/tasks/autocomplete"+query, nil))
+		require.Equal(t, http.StatusOK, w.Code)
+		var resp struct {
+			Tasks []map[string]any `json:"tasks"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		return resp.Tasks
+	}
+
+	tasks := get("?q=login")
+	require.Len(t, tasks, autocompleteLimit)
+	require.Len(t, tasks[0], 3)
+	require.Equal(t, "task-0", tasks[0]["id"])
+	require.Equal(t, "Login flow 00", tasks[0]["title"])
+	require.Equal(t, "story", tasks[0]["taskType"])
+
+	require.Len(t, get("?q=login&limit=3"), 3)
+
+	// LIKE wildcards in q are matched literally
+	tasks = get("?q=100%25")
+	require.Len(t, tasks, 1)
+	require.Equal(t, "task-x", tasks[0]["id"])
+	require.Empty(t, get("?q=%25%25%25zzz"))
+}
+
+func TestDeleteTask_GracePeriod(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := testutil.NewInMemoryDB()
+	require.NoError(t, err)
+	database.DB = db
+	t.Setenv("DELETE_GRACE_PERIOD", "48h")
+
+	for _, id := range []string{"task-1", "task-2"} {
+		require.NoError(t, db.Create(&models.Task{ID: id, Title: id, TaskType: models.TypeStory, UserID: "u-1"}).Error)
+	}
+
+	role := models.RoleMember
+	r := gin.New()
+	r.Use(middleware.JWTAuthMiddleware(), func(c *gin.Context) { c.Set("role", role) })
+	r.DELETE("/api/tasks/:id", DeleteTask)
+	token, err := auth.GenerateToken("u-1", "alice", models.RoleMember)
+	require.NoError(t, err)
+	del := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodDelete, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	// Normal delete: hidden but recoverable within the window
+	require.Equal(t, http.StatusOK, del("/api/tasks/task-1").Code)
+	require.ErrorIs(t, db.First(&models.Task{}, "id = ?", "task-1").Error, gorm.ErrRecordNotFound)
+	var deleted models.Task
+	require.NoError(t, db.Unscoped().First(&deleted, "id = ?", "task-1").Error)
+	require.NotNil(t, deleted.PurgeAfter)
+	require.WithinDuration(t, time.Now().Add(48*time.Hour), *deleted.PurgeAfter, time.Minute)
+
+	n, err := worker.PurgeDeletedTasks(db, time.Now())
+	require.NoError(t, err)
+	require.Zero(t, n)
+	require.NoError(t, db.Unscoped().Model(&deleted).Update("deleted_at", nil).Error)
+	require.NoError(t, db.First(&models.Task{}, "id = ?", "task-1").Error)
+
+	// After the window the purge removes it for good
+	require.Equal(t, http.StatusOK, del("/api/tasks/task-1").Code)
+	n, err = worker.PurgeDeletedTasks(db, time.Now().Add(49*time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, int64(1), n)
+	require.ErrorIs(t, db.Unscoped().First(&models.Task{}, "id = ?", "task-1").Error, gorm.ErrRecordNotFound)
+
+	// Immediate (hard) delete is admin-only
+	require.Equal(t, http.StatusForbidden, del("/api/tasks/task-2?hard=true").Code)
+	require.Equal(t, http.StatusForbidden, del("/api/tasks/task-2?immediate=true").Code)
+	role = models.RoleAdmin
+	require.Equal(t, http.StatusOK, del("/api/tasks/task-2?immediate=true").Code)
+	require.ErrorIs(t, db.Unscoped().First(&models.Task{}, "id = ?", "task-2").Error, gorm.ErrRecordNotFound)
+}
+
+func TestGetTasks_IncludeDeletedAdminOnly(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := testutil.NewInMemoryDB()
+	require.NoError(t, err)
+	database.DB = db
+
+	live := models.Task{ID: "task-live", Title: "Live", TaskType: models.TypeStory, UserID: "u-1"}
+	gone := models.Task{ID: "task-gone", Title: "Gone", TaskType: models.TypeStory, UserID: "u-1"}
+	require.NoError(t, db.Create(&live).Error)
+	require.NoError(t, db.Create(&gone).Error)
+	require.NoError(t, db.Delete(&gone).Error)
+
+	role := models.RoleMember
+	r := gin.New()
+	r.Use(middleware.ErrorHandler(), middleware.JWTAuthMiddleware(), func(c *gin.Context) { c.Set("role", role) })
+	r.GET("/api/tasks", GetTasks)
+	r.GET("/api/tasks/:id", GetTaskByID)
+	token, err := auth.GenerateToken("u-1", "alice", models.RoleMember)
+	require.NoError(t, err)
+	get := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	// Members may not use the flag
+	require.Equal(t, http.StatusForbidden, get("/api/tasks?includeDeleted=true").Code)
+	require.Equal(t, http.StatusForbidden, get("/api/tasks/task-gone?includeDeleted=true").Code)
+	require.Equal(t, http.StatusNotFound, get("/api/tasks/task-gone").Code)
+
+	role = models.RoleAdmin
+	w := get("/api/tasks?includeDeleted=true&limit=10")
+	require.Equal(t, http.StatusOK, w.Code)
+	var list struct {
+		Tasks []map[string]any `json:"tasks"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	require.Len(t, list.Tasks, 2)
+	for _, task := range list.Tasks {
+		_, marked := task["deletedAt"]
+		require.Equal(t, task["id"] == "task-gone", marked, task["id"])
+	}
+
+	w = get("/api/tasks/task-gone?includeDeleted=true")
+	require.Equal(t, http.StatusOK, w.Code)
+	var single map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &single))
+	require.NotEmpty(t, single["deletedAt"])
+}
+
+func TestCreateTask_TitleLengthCountsRunes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := testutil.NewInMemoryDB()
+	require.NoError(t, err)
+	database.DB = db
+	t.Setenv("MAX_TITLE_LENGTH", "10")
+
+	r := gin.New()
+	r.Use(middleware.JWTAuthMiddleware())
+	r.POST("/api/tasks", CreateTask)
+	r.PUT("/api/tasks/:id", UpdateTask)
+	token, err := auth.GenerateToken("u-1", "alice", models.RoleMember)
+	require.NoError(t, err)
+	valid := func(title string) map[string]any {
+		return map[string]any{
+			"title": title, "description": "d", "taskType": "story",
+			"assignee": map[string]string{"id": "u-1", "name": "alice"}, "startDate": "2025-01-01", "endDate": "2025-01-02",
+		}
+	}
+	send := func(method, path string, payload map[string]any) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(payload)
+		req := httptest.NewRequest(method, path, bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	// 10 runes but 30 bytes: at the limit
+	atLimit := strings.Repeat("界", 10)
+	w := send(http.MethodPost, "/api/tasks", valid(atLimit))
+	require.Equal(t, http.StatusCreated, w.Code)
+	var created map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+
+	w = send(http.MethodPost, "/api/tasks", valid(atLimit+"é"))
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.JSONEq(t, `{"error":"title must be at most 10 characters","field":"title","limit":10}`, w.Body.String())
+
+	w = send(http.MethodPut, "/api/tasks/"+created["id"].(string), map[string]any{"title": atLimit + "!"})
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Contains(t, w.Body.String(), `"field":"title"`)
+}
+
+func TestGetTasks_ExpandRelatedUsers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := testutil.NewInMemoryDB()
+	require.NoError(t, err)
+	database.DB = db
+
+	require.NoError(t, db.Create(&models.User{ID: "u-1", Username: "alice", Password: "x"}).Error)
+	require.NoError(t, db.Create(&models.User{ID: "u-2", Username: "bob", Password: "x"}).Error)
+	require.NoError(t, db.Create(&models.Task{ID: "task-1", Title: "T", TaskType: models.TypeStory, UserID: "u-1", AssigneeID: "u-2"}).Error)
+
+	r := gin.New()
+	r.Use(middleware.ErrorHandler(), middleware.JWTAuthMiddleware())
+	r.GET("/api/tasks", GetTasks)
+	r.GET("/api/tasks/:id", GetTaskByID)
+	token, err := auth.GenerateToken("u-1", "alice", models.RoleMember)
+	require.NoError(t, err)
+	get := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	// Lean shape by default
+	w := get("/api/tasks/task-1")
+	require.Equal(t, http.StatusOK, w.Code)
+	var lean map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &lean))
+	require.NotContains(t, lean, "expanded")
+
+	w = get("/api/tasks/task-1?expand=assignee,creator")
+	require.Equal(t, http.StatusOK, w.Code)
+	var single struct {
+		Expanded map[string]map[string]any `json:"expanded"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &single))
+	require.Equal(t, "bob", single.Expanded["assignee"]["username"])
+	require.Equal(t, "alice", single.Expanded["creator"]["username"])
+	require.NotContains(t, single.Expanded["creator"], "password")
+
+	w = get("/api/tasks?expand=creator")
+	require.Equal(t, http.StatusOK, w.Code)
+	var list struct {
+		Tasks []struct {
+			Expanded map[string]map[string]any `json:"expanded"`
+		} `json:"tasks"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	require.Len(t, list.Tasks, 1)
+	require.Equal(t, map[string]any{"id": "u-1", "username": "alice"}, list.Tasks[0].Expanded["creator"])
+	require.NotContains(t, list.Tasks[0].Expanded, "assignee")
+
+	require.Equal(t, http.StatusBadRequest, get("/api/tasks?expand=watchers").Code)
+	require.Equal(t, http.StatusBadRequest, get("/api/tasks/task-1?expand=assignee,board").Code)
+}
+
+func TestCreateTask_DuplicateIDConflict(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := testutil.NewInMemoryDB()
+	require.NoError(t, err)
+	database.DB = db
+
+	require.NoError(t, db.Create(&models.Task{ID: "task-1", Title: "Original", TaskType: models.TypeStory, UserID: "u-2"}).Error)
+
+	r := gin.New()
+	r.Use(middleware.ErrorHandler(), middleware.JWTAuthMiddleware())
+	r.POST("/api/tasks", CreateTask)
+	token, err := auth.GenerateToken("u-1", "alice", models.RoleMember)
+	require.NoError(t, err)
+	create := func(id string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(map[string]any{
+			"id":          id,
+			"title":       "Imported",
+			"description": "Desc",
+			"assignee":    map[string]string{"id": "u-1", "name": "alice"},
+			"startDate":   "2025-01-01",
+			"endDate":     "2025-01-03",
+			"taskType":    "story",
+		})
+		req := httptest.NewRequest(http.MethodPost, "/api/tasks", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := create("task-1")
+	require.Equal(t, http.StatusConflict, w.Code)
+	var existing models.Task
+	require.NoError(t, db.First(&existing, "id = ?", "task-1").Error)
+	require.Equal(t, "Original", existing.Title)
+	require.Equal(t, "u-2", existing.UserID)
+
+	// Soft-deleted tasks still hold their ID
+	require.NoError(t, db.Create(&models.Task{ID: "task-2", Title: "Gone", TaskType: models.TypeStory, UserID: "u-2"}).Error)
+	require.NoError(t, db.Delete(&models.Task{ID: "task-2"}).Error)
+	require.Equal(t, http.StatusConflict, create("task-2").Code)
+
+	w = create("task-3")
+	require.Equal(t, http.StatusCreated, w.Code)
+	var created models.Task
+	require.NoError(t, db.First(&created, "id = ?", "task-3").Error)
+	require.Equal(t, "Imported", created.Title)
+}
+
+func TestStreamTasks_NDJSONHonorsFilters(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := testutil.NewInMemoryDB()
+	require.NoError(t, err)
+	database.DB = db
+
+	base := time.Now().Add(-time.Hour)
+	for i := 0; i < 4; i++ {
+		owner := "u-1"
+		if i == 3 {
+			owner = "u-2"
+		}
+		task := models.Task{ID: fmt.Sprintf("task-%d", i), Title: fmt.Sprintf("T%d", i), TaskType: models.TypeStory, UserID: owner}
+		task.CreatedAt = base.Add(time.Duration(i) * time.Minute)
+		require.NoError(t, db.Create(&task).Error)
+	}
+
+	r := gin.New()
+	r.Use(middleware.JWTAuthMiddleware())
+	r.GET("/api/tasks/stream", StreamTasks)
+	token, err := auth.GenerateToken("u-1", "alice", models.RoleMember)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/tasks/stream?userId=u-1&sort=asc", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	require.Len(t, lines, 3) // u-2's task filtered out
+	for i, line := range lines {
+		var task models.Task
+		require.NoError(t, json.Unmarshal([]byte(line), &task))
+		require.Equal(t, fmt.Sprintf("task-%d", i), task.ID)
+		require.Equal(t, fmt.Sprintf("T%d", i), task.Title)
+	}
+}
+
+func TestSnoozeTask_AdvancesEndDateAndEffort(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := testutil.NewInMemoryDB()
+	require.NoError(t, err)
+	database.DB = db
+
+	require.NoError(t, db.Create(&models.Task{ID: "task-1", Title: "T", TaskType: models.TypeStory, UserID: "u-1",
+		StartDate: "2025-01-01", EndDate: "2025-01-03", Effort: 2, AlertSent: true}).Error)
+	require.NoError(t, db.Create(&models.Task{ID: "task-2", Title: "T", TaskType: models.TypeStory, UserID: "u-1", EndDate: "someday"}).Error)
+	statsCache.Set("u-1", statusStats{}, time.Minute) // a cached stats entry the snooze must drop
+
+	r := gin.New()
+	r.Use(middleware.ErrorHandler(), middleware.JWTAuthMiddleware())
+	r.PATCH("/api/tasks/:id/snooze", SnoozeTask)
+	token, err := auth.GenerateToken("u-1", "alice", models.RoleMember)
+	require.NoError(t, err)
+	snooze := func(id, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPatch, "/api/tasks/"+id+"/snooze", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := snooze("task-1", `{"days":3}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp models.Task
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Equal(t, "2025-01-06", resp.EndDate)
+	require.Equal(t, 5, resp.Effort)
+
+	var stored models.Task
+	require.NoError(t, db.First(&stored, "id = ?", "task-1").Error)
+	require.Equal(t, "2025-01-06", stored.EndDate)
+	require.Equal(t, 5, stored.Effort)
+	require.False(t, stored.AlertSent)
+	_, cached := statsCache.Get("u-1")
+	require.False(t, cached)
+
+	require.Equal(t, http.StatusBadRequest, snooze("task-1", `{"days":0}`).Code)
+	require.Equal(t, http.StatusBadRequest, snooze("task-1", `{"days":-2}`).Code)
+	require.Equal(t, http.StatusBadRequest, snooze("task-2", `{"days":1}`).Code)
+	require.Equal(t, http.StatusNotFound, snooze("task-404", `{"days":1}`).Code)
+}
+
+func TestGetSLARisk_PartitionsAtRiskAndBreached(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := testutil.NewInMemoryDB()
+	require.NoError(t, err)
+	database.DB = db
+
+	now := time.Now()
+	due := func(d time.Duration) string { return now.Add(d).UTC().Format(time.RFC3339) }
+	seed := []models.Task{
+		{ID: "task-soon", EndDate: due(2 * time.Hour)},
+		{ID: "task-later", EndDate: due(20 * time.Hour)},
+		{ID: "task-far", EndDate: due(72 * time.Hour)},                        // outside the window
+		{ID: "task-late", EndDate: due(-5 * time.Hour)},                       // breached
+		{ID: "task-very-late", EndDate: due(-48 * time.Hour)},                 // breached
+		{ID: "task-done", EndDate: due(time.Hour), Status: models.StatusDone}, // done tasks are ignored
+		{ID: "task-undated", EndDate: "someday"},                              // unparseable
+		{ID: "task-archived", EndDate: due(time.Hour), ArchivedAt: &now},      // archived tasks are ignored
+		{ID: "task-archived-late", EndDate: due(-time.Hour), ArchivedAt: &now},
+	}
+	for _, task := range seed {
+		task.Title = task.ID
+		task.TaskType = models.TypeStory
+		task.UserID = "u-1"
+		if task.Status == "" {
+			task.Status = models.StatusTodo
+		}
+		require.NoError(t, db.Create(&task).Error)
+	}
+
+	r := gin.New()
+	r.GET("/api/tasks/sla-risk", GetSLARisk)
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	w := get("/api/tasks/sla-risk?hours=24")
+	require.Equal(t, http.StatusOK, w.Code)
+	var body struct {
+		AtRisk   []map[string]any `json:"atRisk"`
+		Breached []map[string]any `json:"breached"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+
+	require.Len(t, body.AtRisk, 2)
+	require.Equal(t, "task-soon", body.AtRisk[0]["id"])
+	require.InDelta(t, 2, body.AtRisk[0]["hoursRemaining"], 0.2)
+	require.Equal(t, "task-later", body.AtRisk[1]["id"])
+
+	require.Len(t, body.Breached, 2)
+	require.Equal(t, "task-very-late", body.Breached[0]["id"])
+	require.InDelta(t, -48, body.Breached[0]["hoursRemaining"], 0.2)
+	require.Equal(t, "task-late", body.Breached[1]["id"])
+
+	require.Equal(t, http.StatusBadRequest, get("/api/tasks/sla-risk?hours=0").Code)
+}
+
+func TestGetTasks_FlagsDeletedAssignee(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := testutil.NewInMemoryDB()
+	require.NoError(t, err)
+	database.DB = db
+
+	require.NoError(t, db.Create(&models.User{ID: "u-1", Username: "alice", Password: "x"}).Error)
+	require.NoError(t, db.Create(&models.User{ID: "u-2", Username: "bob", Password: "x"}).Error)
+	require.NoError(t, db.Create(&models.Task{ID: "task-1", Title: "T", TaskType: models.TypeStory, UserID: "u-1", AssigneeID: "u-2"}).Error)
+	require.NoError(t, db.Create(&models.Task{ID: "task-2", Title: "T", TaskType: models.TypeStory, UserID: "u-1", AssigneeID: "u-1"}).Error)
+	require.NoError(t, db.Delete(&models.User{ID: "u-2"}).Error)
+
+	r := gin.New()
+	r.Use(middleware.JWTAuthMiddleware())
+	r.GET("/api/tasks", GetTasks)
+	r.GET("/api/tasks/:id", GetTaskByID)
+	token, err := auth.GenerateToken("u-1", "alice", models.RoleMember)
+	require.NoError(t, err)
+	get := func(path string) []byte {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+		return w.Body.Bytes()
+	}
+
+	var list struct {
+		Tasks []map[string]any `json:"tasks"`
+	}
+	require.NoError(t, json.Unmarshal(get("/api/tasks"), &list))
+	require.Len(t, list.Tasks, 2)
+	for _, task := range list.Tasks {
+		if task["id"] == "task-1" {
+			require.Equal(t, true, task["assigneeMissing"])
+		} else {
+			require.NotContains(t, task, "assigneeMissing")
+		}
+	}
+
+	var detail map[string]any
+	require.NoError(t, json.Unmarshal(get("/api/tasks/task-1"), &detail))
+	require.Equal(t, true, detail["assigneeMissing"])
+}
+
+func TestGetTasks_FilterByStatusPriorityAndType(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := testutil.NewInMemoryDB()
+	require.NoError(t, err)
+	database.DB = db
+
+	seed := []models.Task{
+		{ID: "task-1", Status: models.StatusTodo, Priority: models.PriorityHigh, TaskType: models.TypeStory, UserID: "u-1"},
+		{ID: "task-2", Status: models.StatusTodo, Priority: models.PriorityLow, TaskType: models.TypeStory, UserID: "u-1"},
+		{ID: "task-3", Status: models.StatusDone, Priority: models.PriorityHigh, TaskType: models.TypeStory, UserID: "u-1"},
+		{ID: "task-4", Status: models.StatusTodo, Priority: models.PriorityHigh, TaskType: models.TypeDefect, ProjectID: "task-1", UserID: "u-1"},
+		{ID: "task-5", Status: models.StatusTodo, Priority: models.PriorityHigh, TaskType: models.TypeStory, UserID: "u-2"},
+	}
+	for _, task := range seed {
+		task.Title = task.ID
+		require.NoError(t, db.Create(&task).Error)
+	}
+
+	r := gin.New()
+	r.Use(middleware.JWTAuthMiddleware())
+	r.GET("/api/tasks", GetTasks)
+	token, err := auth.GenerateToken("u-1", "alice", models.RoleMember)
+	require.NoError(t, err)
+	get := func(query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/tasks?"+query, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	ids := func(query string) ([]string, int64) {
+		w := get(query)
+		require.Equal(t, http.StatusOK, w.Code)
+		var body struct {
+			Tasks []map[string]any `json:"tasks"`
+			Total int64            `json:"total"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		var out []string
+		for _, task := range body.Tasks {
+			out = append(out, task["id"].(string))
+		}
+		return out, body.Total
+	}
+
+	got, total := ids("status=todo&priority=high&taskType=story&sort=asc")
+	require.Equal(t, []string{"task-1", "task-5"}, got)
+	require.Equal(t, int64(2), total)
+
+	got, total = ids("status=todo&priority=high&taskType=story&userId=u-1")
+	require.Equal(t, []string{"task-1"}, got)
+	require.Equal(t, int64(1), total)
+
+	// Total counts the whole filtered set, not just the page
+	got, total = ids("status=todo&limit=1")
+	require.Len(t, got, 1)
+	require.Equal(t, int64(4), total)
+
+	got, total = ids("taskType=story,defect&priority=high&status=todo&sort=asc")
+	require.Equal(t, []string{"task-1", "task-4", "task-5"}, got)
+	require.Equal(t, int64(3), total)
+
+	for _, bad := range []string{"status=foo", "priority=urgent", "taskType=epic", "status=todo,foo"} {
+		w := get(bad)
+		require.Equal(t, http.StatusBadRequest, w.Code, bad)
+		require.Contains(t, w.Body.String(), "Invalid", bad)
+	}
+}
+
+func TestGetTasks_CountAndPageShareTransaction(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := testutil.NewInMemoryDB()
+	require.NoError(t, err)
+	database.DB = db
+
+	for i := 0; i < 7; i++ {
+		require.NoError(t, db.Create(&models.Task{ID: fmt.Sprintf("task-%d", i), Title: "T", TaskType: models.TypeStory, UserID: "u-1"}).Error)
+	}
+
+	// Record whether each tasks query ran inside a transaction
+	var inTx []bool
+	require.NoError(t, db.Callback().Query().After("gorm:query").Register("test:record_tx", func(tx *gorm.DB) {
+		if tx.Statement.Table == "tasks" {
+			_, ok := tx.Statement.ConnPool.(*sql.Tx)
+			inTx = append(inTx, ok)
+		}
+	}))
+
+	r := gin.New()
+	r.Use(middleware.JWTAuthMiddleware())
+	r.GET("/api/tasks", GetTasks)
+	token, err := auth.GenerateToken("u-1", "alice", models.RoleMember)
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodGet, "/api/tasks?page=2&limit=3", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var body struct {
+		Tasks []map[string]any `json:"tasks"`
+		Total int64            `json:"total"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	require.Equal(t, int64(7), body.Total)
+	require.Len(t, body.Tasks, 3)
+
+	// The count and the page query both read from the same transaction snapshot
+	require.Equal(t, []bool{true, true}, inTx)
+}
+
+func TestGetTasks_SearchTitleAndDescription(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := testutil.NewInMemoryDB()
+	require.NoError(t, err)
+	database.DB = db
+
+	seed := []models.Task{
+		{ID: "task-1", Title: "Fix LOGIN bug", Description: "users get 500", Status: models.StatusTodo},
+		{ID: "task-2", Title: "Write docs", Description: "explain the login flow", Status: models.StatusDone},
+		{ID: "task-3", Title: "Refactor cache", Description: "nothing to see", Status: models.StatusTodo},
+		{ID: "task-4", Title: "100% coverage", Description: "", Status: models.StatusTodo},
+	}
+	for _, task := range seed {
+		task.TaskType = models.TypeStory
+		task.UserID = "u-1"
+		require.NoError(t, db.Create(&task).Error)
+	}
+
+	r := gin.New()
+	r.Use(middleware.JWTAuthMiddleware())
+	r.GET("/api/tasks", GetTasks)
+	token, err := auth.GenerateToken("u-1", "alice", models.RoleMember)
+	require.NoError(t, err)
+	search := func(query string) ([]string, int64) {
+		req := httptest.NewRequest(http.MethodGet, "/api/tasks?sort=asc&"+query, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+		var body struct {
+			Tasks []map[string]any `json:"tasks"`
+			Total int64            `json:"total"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		var ids []string
+		for _, task := range body.Tasks {
+			ids = append(ids, task["id"].(string))
+		}
+		return ids, body.Total
+	}
+
+	// Case-insensitive, matching title or description
+	ids, total := search("q=Login")
+	require.Equal(t, []string{"task-1", "task-2"}, ids)
+	require.Equal(t, int64(2), total)
+
+	// Combines with other filters and pagination
+	ids, total = search("q=login&status=done")
+	require.Equal(t, []string{"task-2"}, ids)
+	require.Equal(t, int64(1), total)
+	ids, total = search("q=login&limit=1")
+	require.Len(t, ids, 1)
+	require.Equal(t, int64(2), total)
+
+	// Wildcards are matched literally
+	ids, _ = search("q=" + url.QueryEscape("100%"))
+	require.Equal(t, []string{"task-4"}, ids)
+
+	// Blank q is ignored
+	_, total = search("q=" + url.QueryEscape("   "))
+	require.Equal(t, int64(4), total)
+}
+
+func TestGetTaskChildren_PagedAndEnriched(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := testutil.NewInMemoryDB()
+	require.NoError(t, err)
+	database.DB = db
+	resetUsersCache()
+
+	require.NoError(t, db.Create(&models.User{ID: "u-2", Username: "bob", Password: "x"}).Error)
+	base := time.Now().Add(-time.Hour)
+	seed := []models.Task{
+		{ID: "task-story", Title: "Story", TaskType: models.TypeStory, UserID: "u-1"},
+		{ID: "task-other", Title: "Other story", TaskType: models.TypeStory, UserID: "u-1"},
+		{ID: "task-c1", Title: "First", TaskType: models.TypeSubtask, ProjectID: "task-story", AssigneeID: "u-2", UserID: "u-1"},
+		{ID: "task-c2", Title: "Second", TaskType: models.TypeDefect, ProjectID: "task-story", UserID: "u-3"},
+		{ID: "task-c3", Title: "Third", TaskType: models.TypeSubtask, ProjectID: "task-story", UserID: "u-1"},
+		{ID: "task-x", Title: "Elsewhere", TaskType: models.TypeSubtask, ProjectID: "task-other", UserID: "u-1"},
+	}
+	for i, task := range seed {
+		task.CreatedAt = base.Add(time.Duration(i) * time.Minute)
+		require.NoError(t, db.Create(&task).Error)
+	}
+
+	r := gin.New()
+	r.Use(middleware.ErrorHandler(), middleware.JWTAuthMiddleware())
+	r.GET("/api/tasks/:id/children", GetTaskChildren)
+	token, err := auth.GenerateToken("u-1", "alice", models.RoleMember)
+	require.NoError(t, err)
+	get := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get("/api/tasks/task-story/children?limit=2")
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp struct {
+		ParentID string           `json:"parentId"`
+		Tasks    []map[string]any `json:"tasks"`
+		Total    int64            `json:"total"`
+		HasNext  bool             `json:"hasNext"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Equal(t, "task-story", resp.ParentID)
+	require.Equal(t, int64(3), resp.Total)
+	require.True(t, resp.HasNext)
+	require.Len(t, resp.Tasks, 2)
+	require.Equal(t, "task-c1", resp.Tasks[0]["id"])
+	require.Equal(t, "task-c2", resp.Tasks[1]["id"])
+	require.Equal(t, "bob", resp.Tasks[0]["assignee"].(map[string]any)["name"])
+
+	w = get("/api/tasks/task-story/children?limit=2&page=2")
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp.Tasks, 1)
+	require.Equal(t, "task-c3", resp.Tasks[0]["id"])
+
+	// Non-story and unknown parents are 404
+	require.Equal(t, http.StatusNotFound, get("/api/tasks/task-c1/children").Code)
+	require.Equal(t, http.StatusNotFound, get("/api/tasks/task-missing/children").Code)
+}
+
+func TestCreateTask_SourceAndFilter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := testutil.NewInMemoryDB()
+	require.NoError(t, err)
+	database.DB = db
+
+	r := gin.New()
+	r.Use(middleware.JWTAuthMiddleware())
+	r.POST("/api/tasks", CreateTask)
+	r.GET("/api/tasks", GetTasks)
+	token, err := auth.GenerateToken("u-1", "alice", models.RoleMember)
+	require.NoError(t, err)
+
+	create := func(title, bodySource, headerSource string) *httptest.ResponseRecorder {
+		payload := map[string]any{
+			"title":       title,
+			"description": "Desc",
+			"assignee":    map[string]string{"id": "u-1", "name": "alice"},
+			"startDate":   "2025-01-01",
+			"endDate":     "2025-01-02",
+			"taskType":    "story",
+		}
+		if bodySource != "" {
+			payload["source"] = bodySource
+		}
+		body, _ := json.Marshal(payload)
+		req := httptest.NewRequest(http.MethodPost, "/api/tasks", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		if headerSource != "" {
+			req.Header.Set("X-Client-Source", headerSource)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	require.Equal(t, http.StatusCreated, create("From web", "web", "").Code)
+	require.Equal(t, http.StatusCreated, create("From mobile", "", "mobile").Code)
+	// The body field wins over the header
+	require.Equal(t, http.StatusCreated, create("From import", "import", "web").Code)
+	require.Equal(t, http.StatusCreated, create("Untagged", "", "").Code)
+	require.Equal(t, http.StatusBadRequest, create("Bad", "fax", "").Code)
+	require.Equal(t, http.StatusBadRequest, create("Bad header", "", "fax").Code)
+
+	list := func(query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/tasks?limit=100&"+query, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	titles := func(query string) []string {
+		w := list(query)
+		require.Equal(t, http.StatusOK, w.Code)
+		var resp struct {
+			Tasks []struct {
+				Title  string `json:"title"`
+				Source string `json:"source"`
+			} `json:"tasks"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		var out []string
+		for _, task := range resp.Tasks {
+			out = append(out, task.Title+"/"+task.Source)
+		}
+		return out
+	}
+
+	require.Equal(t, []string{"From mobile/mobile"}, titles("source=mobile"))
+	require.ElementsMatch(t, []string{"From web/web", "From import/import"}, titles("source=web,import"))
+	require.Len(t, titles(""), 4)
+	require.Equal(t, http.StatusBadRequest, list("source=fax").Code)
+}
+
+func TestDeleteTask_StoryWithChildren(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := testutil.NewInMemoryDB()
+	require.NoError(t, err)
+	database.DB = db
+
+	base := time.Now().Add(-time.Hour)
+	seed := []models.Task{
+		{ID: "task-story", Title: "Story", TaskType: models.TypeStory, UserID: "u-cascade"},
+		{ID: "task-sub", Title: "Sub", TaskType: models.TypeSubtask, ProjectID: "task-story", UserID: "u-cascade"},
+		{ID: "task-bug", Title: "Bug", TaskType: models.TypeDefect, ProjectID: "task-story", UserID: "u-other"},
+		{ID: "task-lone", Title: "Lone story", TaskType: models.TypeStory, UserID: "u-cascade"},
+	}
+	for i, task := range seed {
+		task.CreatedAt = base.Add(time.Duration(i) * time.Minute)
+		require.NoError(t, db.Create(&task).Error)
+	}
+
+	client := &recordingClient{}
+	realtime.GetHub().Register("u-cascade", client)
+	defer realtime.GetHub().Unregister("u-cascade", client)
+
+	r := gin.New()
+	r.Use(middleware.JWTAuthMiddleware())
+	r.DELETE("/api/tasks/:id", DeleteTask)
+	token, err := auth.GenerateToken("u-cascade", "carol", models.RoleMember)
+	require.NoError(t, err)
+	del := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodDelete, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	// Blocked by default, naming the children to remove first
+	w := del("/api/tasks/task-story")
+	require.Equal(t, http.StatusConflict, w.Code)
+	var blocked struct {
+		ChildIDs []string `json:"childIds"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &blocked))
+	require.Equal(t, []string{"task-sub", "task-bug"}, blocked.ChildIDs)
+	require.NoError(t, db.First(&models.Task{}, "id = ?", "task-story").Error)
+	require.Empty(t, client.events(t))
+
+	// Stories without children delete as before
+	require.Equal(t, http.StatusOK, del("/api/tasks/task-lone").Code)
+
+	// Cascade removes the story and every child, one event each
+	w = del("/api/tasks/task-story?cascade=true")
+	require.Equal(t, http.StatusOK, w.Code)
+	for _, id := range []string{"task-story", "task-sub", "task-bug"} {
+		require.ErrorIs(t, db.First(&models.Task{}, "id = ?", id).Error, gorm.ErrRecordNotFound)
+		var deleted models.Task
+		require.NoError(t, db.Unscoped().First(&deleted, "id = ?", id).Error)
+		require.NotNil(t, deleted.PurgeAfter)
+	}
+
+	var deletedIDs []any
+	for _, evt := range client.events(t) {
+		require.Equal(t, "task_deleted", evt["type"])
+		deletedIDs = append(deletedIDs, evt["taskId"])
+	}
+	require.Equal(t, []any{"task-lone", "task-sub", "task-bug", "task-story"}, deletedIDs)
+}
+
+func TestDeleteTask_AdminDeletesAnyUsersTask(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := testutil.NewInMemoryDB()
+	require.NoError(t, err)
+	database.DB = db
+	require.NoError(t, db.Create(&models.Task{ID: "task-bob", Title: "Bob's", TaskType: models.TypeStory, UserID: "u-2"}).Error)
+
+	r := gin.New()
+	r.Use(middleware.ErrorHandler(), middleware.JWTAuthMiddleware())
+	r.DELETE("/api/tasks/:id", DeleteTask)
+	del := func(role string) int {
+		token, err := auth.GenerateToken("u-1", "alice", role)
+		require.NoError(t, err)
+		req := httptest.NewRequest(http.MethodDelete, "/api/tasks/task-bob", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	require.Equal(t, http.StatusNotFound, del(models.RoleMember))
+	require.Equal(t, http.StatusOK, del(models.RoleAdmin))
+	require.ErrorIs(t, db.First(&models.Task{}, "id = ?", "task-bob").Error, gorm.ErrRecordNotFound)
+}
+
+func TestStatusTransitions_BlockSkips(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := testutil.NewInMemoryDB()
+	require.NoError(t, err)
+	database.DB = db
+	require.NoError(t, db.Create(&models.Task{ID: "task-1", Title: "Flow", Status: models.StatusTodo, TaskType: models.TypeStory, UserID: "u-1"}).Error)
+
+	r := gin.New()
+	r.Use(middleware.JWTAuthMiddleware())
+	r.PATCH("/api/tasks/:id/status", UpdateTaskStatus)
+	r.PUT("/api/tasks/:id", UpdateTask)
+	token, err := auth.GenerateToken("u-1", "alice", models.RoleMember)
+	require.NoError(t, err)
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	status := func() models.TaskStatus {
+		var task models.Task
+		require.NoError(t, db.First(&task, "id = ?", "task-1").Error)
+		return task.Status
+	}
+
+	// todo -> done skips inProgress, on both paths
+	w := send(http.MethodPatch, "/api/tasks/task-1/status", `{"status":"done"}`)
+	require.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	require.Contains(t, w.Body.String(), "allowed next states: inProgress")
+	require.Equal(t, http.StatusUnprocessableEntity, send(http.MethodPut, "/api/tasks/task-1", `{"status":"done"}`).Code)
+	require.Equal(t, models.StatusTodo, status())
+
+	require.Equal(t, http.StatusOK, send(http.MethodPatch, "/api/tasks/task-1/status", `{"status":"inProgress"}`).Code)
+	require.Equal(t, http.StatusOK, send(http.MethodPut, "/api/tasks/task-1", `{"status":"done"}`).Code)
+	require.Equal(t, models.StatusDone, status())
+
+	// Backward one step is fine; backward skips are not
+	require.Equal(t, http.StatusUnprocessableEntity, send(http.MethodPatch, "/api/tasks/task-1/status", `{"status":"todo"}`).Code)
+	require.Equal(t, http.StatusOK, send(http.MethodPatch, "/api/tasks/task-1/status", `{"status":"inProgress"}`).Code)
+	require.Equal(t, models.StatusInProgress, status())
+
+	// Updates that leave the status alone are unaffected
+	require.Equal(t, http.StatusOK, send(http.MethodPut, "/api/tasks/task-1", `{"title":"Renamed"}`).Code)
+}
+
+func TestGetTaskDefaults_ReflectsConfig(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := testutil.NewInMemoryDB()
+	require.NoError(t, err)
+	database.DB = db
+
+	r := gin.New()
+	r.Use(middleware.JWTAuthMiddleware())
+	r.GET("/api/tasks/defaults", GetTaskDefaults)
+	r.POST("/api/tasks", CreateTask)
+	token, err := auth.GenerateToken("u-1", "alice", models.RoleMember)
+	require.NoError(t, err)
+	defaults := func() string {
+		req := httptest.NewRequest(http.MethodGet, "/api/tasks/defaults", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+		return w.Body.String()
+	}
+
+	t.Setenv("DEFAULT_TASK_STATUS", "")
+	t.Setenv("DEFAULT_TASK_PRIORITY", "")
+	require.JSONEq(t, `{"status":"todo","priority":"medium","effort":1}`, defaults())
+
+	t.Setenv("DEFAULT_TASK_STATUS", "inProgress")
+	t.Setenv("DEFAULT_TASK_PRIORITY", "high")
+	require.NoError(t, ValidateConfig())
+	require.JSONEq(t, `{"status":"inProgress","priority":"high","effort":1}`, defaults())
+
+	// Tasks created without those fields get the same values
+	body, _ := json.Marshal(map[string]any{
+		"title":       "Defaults",
+		"description": "Desc",
+		"assignee":    map[string]string{"id": "u-1", "name": "alice"},
+		"startDate":   "2025-01-01",
+		"endDate":     "2025-01-01",
+		"taskType":    "story",
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/tasks", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var created models.Task
+	require.NoError(t, db.First(&created, "title = ?", "Defaults").Error)
+	require.Equal(t, models.StatusInProgress, created.Status)
+	require.Equal(t, models.PriorityHigh, created.Priority)
+	require.Equal(t, 1, created.Effort)
+
+	t.Setenv("DEFAULT_TASK_PRIORITY", "urgent")
+	require.Error(t, ValidateConfig())
+}
+
+func TestBulkUpdateStatus_PartialOwnership(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := testutil.NewInMemoryDB()
+	require.NoError(t, err)
+	database.DB = db
+
+	seed := []models.Task{
+		{ID: "task-1", Title: "Mine 1", Status: models.StatusInProgress, TaskType: models.TypeStory, UserID: "u-bulk"},
+		{ID: "task-2", Title: "Mine 2", Status: models.StatusInProgress, TaskType: models.TypeStory, UserID: "u-bulk"},
+		{ID: "task-3", Title: "Mine, not started", Status: models.StatusTodo, TaskType: models.TypeStory, UserID: "u-bulk"},
+		{ID: "task-4", Title: "Not mine", Status: models.StatusInProgress, TaskType: models.TypeStory, UserID: "u-other"},
+	}
+	for _, task := range seed {
+		require.NoError(t, db.Create(&task).Error)
+	}
+
+	client := &recordingClient{}
+	realtime.GetHub().Register("u-bulk", client)
+	defer realtime.GetHub().Unregister("u-bulk", client)
+
+	r := gin.New()
+	r.Use(middleware.JWTAuthMiddleware())
+	r.PATCH("/api/tasks/status", BulkUpdateStatus)
+	token, err := auth.GenerateToken("u-bulk", "dana", models.RoleMember)
+	require.NoError(t, err)
+	patch := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPatch, "/api/tasks/status", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := patch(`{"ids":["task-1","task-2","task-3","task-4","task-missing"],"status":"done"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp struct {
+		Updated           int      `json:"updated"`
+		UpdatedIDs        []string `json:"updatedIds"`
+		Skipped           []string `json:"skipped"`
+		InvalidTransition []string `json:"invalidTransition"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Equal(t, 2, resp.Updated)
+	require.Equal(t, []string{"task-1", "task-2"}, resp.UpdatedIDs)
+	require.Equal(t, []string{"task-4", "task-missing"}, resp.Skipped)
+	require.Equal(t, []string{"task-3"}, resp.InvalidTransition)
+
+	statusOf := func(id string) models.TaskStatus {
+		var task models.Task
+		require.NoError(t, db.First(&task, "id = ?", id).Error)
+		return task.Status
+	}
+	require.Equal(t, models.StatusDone, statusOf("task-1"))
+	require.Equal(t, models.StatusDone, statusOf("task-2"))
+	require.Equal(t, models.StatusTodo, statusOf("task-3"))
+	require.Equal(t, models.StatusInProgress, statusOf("task-4"))
+
+	// One event for the whole batch
+	events := client.events(t)
+	require.Len(t, events, 1)
+	require.Equal(t, "task_bulk_status_changed", events[0]["type"])
+	require.Equal(t, []any{"task-1", "task-2"}, events[0]["taskIds"])
+
+	require.Equal(t, http.StatusBadRequest, patch(`{"ids":[],"status":"done"}`).Code)
+	require.Equal(t, http.StatusBadRequest, patch(`{"ids":["task-1"],"status":"archived"}`).Code)
+}
+
+func TestArchiveDoneTasks_OnlyOldDoneTasks(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := testutil.NewInMemoryDB()
+	require.NoError(t, err)
+	database.DB = db
+
+	old := time.Now().AddDate(0, 0, -30)
+	recent := time.Now().AddDate(0, 0, -2)
+	seed := []models.Task{
+		{ID: "task-old-done", Title: "Old done", Status: models.StatusDone, TaskType: models.TypeStory, UserID: "u-arch"},
+		{ID: "task-recent-done", Title: "Recent done", Status: models.StatusDone, TaskType: models.TypeStory, UserID: "u-arch"},
+		{ID: "task-old-todo", Title: "Old todo", Status: models.StatusTodo, TaskType: models.TypeStory, UserID: "u-arch"},
+		{ID: "task-old-other", Title: "Someone else's", Status: models.StatusDone, TaskType: models.TypeStory, UserID: "u-other"},
+	}
+	for _, task := range seed {
+		task.UpdatedAt = old
+		if task.ID == "task-recent-done" {
+			task.UpdatedAt = recent
+		}
+		require.NoError(t, db.Create(&task).Error)
+	}
+
+	client := &recordingClient{}
+	realtime.GetHub().Register("u-arch", client)
+	defer realtime.GetHub().Unregister("u-arch", client)
+
+	r := gin.New()
+	r.Use(middleware.JWTAuthMiddleware())
+	r.POST("/api/tasks/archive-done", ArchiveDoneTasks)
+	r.GET("/api/tasks", GetTasks)
+	token, err := auth.GenerateToken("u-arch", "erin", models.RoleMember)
+	require.NoError(t, err)
+	call := func(method, path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	require.Equal(t, http.StatusBadRequest, call(http.MethodPost, "/api/tasks/archive-done?olderThan=soon").Code)
+
+	w := call(http.MethodPost, "/api/tasks/archive-done?olderThan=14d")
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp struct {
+		Archived int `json:"archived"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Equal(t, 1, resp.Archived)
+
+	archived := map[string]bool{}
+	for _, task := range seed {
+		var stored models.Task
+		require.NoError(t, db.First(&stored, "id = ?", task.ID).Error)
+		archived[task.ID] = stored.ArchivedAt != nil
+	}
+	require.Equal(t, map[string]bool{
+		"task-old-done":    true,
+		"task-recent-done": false,
+		"task-old-todo":    false,
+		"task-old-other":   false,
+	}, archived)
+
+	events := client.events(t)
+	require.Len(t, events, 1)
+	require.Equal(t, "task_archived", events[0]["type"])
+	require.Equal(t, "task-old-done", events[0]["taskId"])
+
+	// Archived tasks drop out of the list
+	w = call(http.MethodGet, "/api/tasks?limit=100")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NotContains(t, w.Body.String(), "task-old-done")
+	require.Contains(t, w.Body.String(), "task-recent-done")
+
+	// Nothing left to archive
+	w = call(http.MethodPost, "/api/tasks/archive-done?olderThan=14d")
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Zero(t, resp.Archived)
+}
+
+func TestTrashAndRestore_RestoredTaskReappears(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := testutil.NewInMemoryDB()
+	require.NoError(t, err)
+	database.DB = db
+
+	for _, task := range []models.Task{
+		{ID: "task-keep", Title: "Keep", TaskType: models.TypeStory, UserID: "u-1"},
+		{ID: "task-bin", Title: "Bin", TaskType: models.TypeStory, UserID: "u-1"},
+		{ID: "task-other", Title: "Other", TaskType: models.TypeStory, UserID: "u-2"},
+	} {
+		require.NoError(t, db.Create(&task).Error)
+	}
+	require.NoError(t, db.Delete(&models.Task{}, "id = ?", "task-other").Error)
+
+	r := gin.New()
+	r.Use(middleware.ErrorHandler(), middleware.JWTAuthMiddleware())
+	r.GET("/api/tasks", GetTasks)
+	r.GET("/api/tasks/trash", GetTrash)
+	r.POST("/api/tasks/:id/restore", RestoreTask)
+	r.DELETE("/api/tasks/:id", DeleteTask)
+	token, err := auth.GenerateToken("u-1", "alice", models.RoleMember)
+	require.NoError(t, err)
+	call := func(method, path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	listedIDs := func(path string) []string {
+		w := call(http.MethodGet, path)
+		require.Equal(t, http.StatusOK, w.Code)
+		var resp struct {
+			Tasks []map[string]any `json:"tasks"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		ids := make([]string, 0, len(resp.Tasks))
+		for _, task := range resp.Tasks {
+			ids = append(ids, task["id"].(string))
+		}
+		return ids
+	}
+
+	require.Equal(t, http.StatusOK, call(http.MethodDelete, "/api/tasks/task-bin").Code)
+	require.Equal(t, []string{"task-keep"}, listedIDs("/api/tasks"))
+	// Only the caller's own deleted tasks are in the trash
+	require.Equal(t, []string{"task-bin"}, listedIDs("/api/tasks/trash"))
+
+	// Live tasks and other users' tasks can't be restored
+	require.Equal(t, http.StatusNotFound, call(http.MethodPost, "/api/tasks/task-keep/restore").Code)
+	require.Equal(t, http.StatusNotFound, call(http.MethodPost, "/api/tasks/task-other/restore").Code)
+
+	w := call(http.MethodPost, "/api/tasks/task-bin/restore")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NotContains(t, w.Body.String(), "deletedAt")
+	require.ElementsMatch(t, []string{"task-keep", "task-bin"}, listedIDs("/api/tasks"))
+	require.Empty(t, listedIDs("/api/tasks/trash"))
+
+	var restored models.Task
+	require.NoError(t, db.First(&restored, "id = ?", "task-bin").Error)
+	require.Nil(t, restored.PurgeAfter)
+}
+
+func TestGetTasks_SortByPriorityScore(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := testutil.NewInMemoryDB()
+	require.NoError(t, err)
+	database.DB = db
+
+	now := time.Now()
+	createdAt := map[string]time.Time{"task-aged": now.AddDate(0, 0, -20)}
+	for _, task := range []models.Task{
+		// 0.9: low priority, nothing else going for it
+		{ID: "task-low", Title: "Low", Priority: models.PriorityLow, Effort: 1},
+		// 2.9: fresh high priority
+		{ID: "task-high", Title: "High", Priority: models.PriorityHigh, Effort: 1},
+		// ~5.9: medium but four days overdue
+		{ID: "task-overdue", Title: "Overdue", Priority: models.PriorityMedium, Effort: 1,
+			EndDate: now.AddDate(0, 0, -5).Format("2006-01-02")},
+		// 5.0: high priority open for 20 days, but a big effort
+		{ID: "task-aged", Title: "Aged", Priority: models.PriorityHigh, Effort: 10},
+	} {
+		task.TaskType = models.TypeStory
+		task.UserID = "u-1"
+		task.CreatedAt = now
+		if at, ok := createdAt[task.ID]; ok {
+			task.CreatedAt = at
+		}
+		require.NoError(t, db.Create(&task).Error)
+	}
+
+	r := gin.New()
+	r.Use(middleware.JWTAuthMiddleware())
+	r.GET("/api/tasks", GetTasks)
+	token, err := auth.GenerateToken("u-1", "alice", models.RoleMember)
+	require.NoError(t, err)
+	list := func(query string) ([]string, []float64) {
+		req := httptest.NewRequest(http.MethodGet, "/api/tasks?"+query, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var resp struct {
+			Tasks []map[string]any `json:"tasks"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		var ids []string
+		var scores []float64
+		for _, task := range resp.Tasks {
+			ids = append(ids, task["id"].(string))
+			scores = append(scores, task["priorityScore"].(float64))
+		}
+		return ids, scores
+	}
+
+	ids, scores := list("sortBy=priorityScore&limit=10")
+	require.Equal(t, []string{"task-overdue", "task-aged", "task-high", "task-low"}, ids)
+	require.InDelta(t, 5.0, scores[1], 0.01)
+	require.InDelta(t, 2.9, scores[2], 0.01)
+	require.InDelta(t, 0.9, scores[3], 0.01)
+
+	// Paging applies after sorting
+	ids, _ = list("sortBy=priorityScore&limit=2&page=2")
+	require.Equal(t, []string{"task-high", "task-low"}, ids)
+
+	// Weights come from the environment
+	t.Setenv("SCORE_WEIGHT_OVERDUE", "0")
+	ids, _ = list("sortBy=priorityScore&limit=10")
+	require.Equal(t, []string{"task-aged", "task-high", "task-overdue", "task-low"}, ids)
+
+	// Only the highest-priority candidates are scored once the cap is reached
+	defer func(n int) { maxInMemoryCandidates = n }(maxInMemoryCandidates)
+	maxInMemoryCandidates = 2
+	ids, _ = list("sortBy=priorityScore&limit=10")
+	require.Equal(t, []string{"task-aged", "task-high"}, ids)
+	req := httptest.NewRequest(http.MethodGet, "/api/tasks?sortBy=priorityScore", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	require.Contains(t, w.Body.String(), `"truncated":true`)
+
+	req = httptest.NewRequest(http.MethodGet, "/api/tasks?sortBy=title", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestGetTasks_CursorPaginationStableAcrossInserts(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := testutil.NewInMemoryDB()
+	require.NoError(t, err)
+	database.DB = db
+
+	// task-c and task-d share a creation time; the id breaks the tie
+	base := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	seed := map[string]time.Time{
+		"task-a": base,
+		"task-b": base.Add(time.Minute),
+		"task-c": base.Add(2 * time.Minute),
+		"task-d": base.Add(2 * time.Minute),
+		"task-e": base.Add(3 * time.Minute),
+	}
+	for id, at := range seed {
+		task := models.Task{ID: id, Title: id, TaskType: models.TypeStory, UserID: "u-1"}
+		task.CreatedAt = at
+		require.NoError(t, db.Create(&task).Error)
+	}
+
+	r := gin.New()
+	r.Use(middleware.JWTAuthMiddleware())
+	r.GET("/api/tasks", GetTasks)
+	token, err := auth.GenerateToken("u-1", "alice", models.RoleMember)
+	require.NoError(t, err)
+	type page struct {
+		Tasks      []map[string]any `json:"tasks"`
+		HasNext    bool             `json:"hasNext"`
+		NextCursor *string          `json:"nextCursor"`
+		Total      *int64           `json:"total"`
+	}
+	get := func(query string) (int, page) {
+		req := httptest.NewRequest(http.MethodGet, "/api/tasks?"+query, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		var p page
+		if w.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &p))
+		}
+		return w.Code, p
+	}
+
+	var seen []string
+	cursor := ""
+	for pages := 0; ; pages++ {
+		require.Less(t, pages, 5, "cursor iteration did not terminate")
+		code, p := get("limit=2&cursor=" + cursor)
+		require.Equal(t, http.StatusOK, code)
+		require.Nil(t, p.Total)
+		for _, task := range p.Tasks {
+			seen = append(seen, task["id"].(string))
+		}
+		// Tasks created mid-iteration land before the cursor and don't shift later pages
+		if pages == 0 {
+			require.NoError(t, db.Create(&models.Task{ID: "task-new", Title: "New", TaskType: models.TypeStory, UserID: "u-1"}).Error)
+		}
+		if !p.HasNext {
+			require.Nil(t, p.NextCursor)
+			break
+		}
+		require.NotNil(t, p.NextCursor)
+		cursor = *p.NextCursor
+	}
+	require.Equal(t, []string{"task-e", "task-d", "task-c", "task-b", "task-a"}, seen)
+
+	// Ascending order iterates the other way, including the tie
+	seen = nil
+	cursor = ""
+	for {
+		_, p := get("limit=3&sort=asc&cursor=" + cursor)
+		for _, task := range p.Tasks {
+			seen = append(seen, task["id"].(string))
+		}
+		if !p.HasNext {
+			break
+		}
+		cursor = *p.NextCursor
+	}
+	require.Equal(t, []string{"task-a", "task-b", "task-c", "task-d", "task-e", "task-new"}, seen)
+
+	code, _ := get("cursor=not-a-cursor")
+	require.Equal(t, http.StatusBadRequest, code)
+	code, _ = get("cursor=&sortBy=priorityScore")
+	require.Equal(t, http.StatusBadRequest, code)
+}
+
+func TestValidateStories_PerIDVerdicts(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := testutil.NewInMemoryDB()
+	require.NoError(t, err)
+	database.DB = db
+
+	require.NoError(t, db.Create(&models.Task{ID: "task-s1", Title: "Story 1", TaskType: models.TypeStory, UserID: "u-1"}).Error)
+	require.NoError(t, db.Create(&models.Task{ID: "task-s2", Title: "Story 2", TaskType: models.TypeStory, UserID: "u-2"}).Error)
+	require.NoError(t, db.Create(&models.Task{ID: "task-sub", Title: "Subtask", TaskType: models.TypeSubtask, ProjectID: "task-s1", UserID: "u-1"}).Error)
+
+	r := gin.New()
+	r.Use(middleware.JWTAuthMiddleware())
+	r.POST("/api/stories/validate", ValidateStories)
+	token, err := auth.GenerateToken("u-1", "alice", models.RoleMember)
+	require.NoError(t, err)
+	post := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/stories/validate", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := post(`{"ids":["task-s1","task-sub","task-missing","task-s2","task-s1"]}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp struct {
+		Results []struct {
+			ID      string `json:"id"`
+			Exists  bool   `json:"exists"`
+			IsStory bool   `json:"isStory"`
+		} `json:"results"`
+		AllValid bool `json:"allValid"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.False(t, resp.AllValid)
+	require.Len(t, resp.Results, 4) // duplicates collapse
+	verdicts := map[string][2]bool{}
+	for _, r := range resp.Results {
+		verdicts[r.ID] = [2]bool{r.Exists, r.IsStory}
+	}
+	require.Equal(t, map[string][2]bool{
+		"task-s1":      {true, true},
+		"task-s2":      {true, true}, // other users' stories are valid parents too
+		"task-sub":     {true, false},
+		"task-missing": {false, false},
+	}, verdicts)
+
+	w = post(`{"ids":["task-s1","task-s2"]}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Contains(t, w.Body.String(), `"allValid":true`)
+
+	require.Equal(t, http.StatusBadRequest, post(`{"ids":[]}`).Code)
+}
+
+func TestAutoCompleteTask_RequiresPastEndDateAndDoneChildren(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := testutil.NewInMemoryDB()
+	require.NoError(t, err)
+	database.DB = db
+
+	past := time.Now().AddDate(0, 0, -3).Format("2006-01-02")
+	future := time.Now().AddDate(0, 0, 3).Format("2006-01-02")
+	for _, task := range []models.Task{
+		{ID: "task-ready", Title: "Ready", TaskType: models.TypeStory, Status: models.StatusTodo, EndDate: past},
+		{ID: "task-ready-a", Title: "A", TaskType: models.TypeSubtask, ProjectID: "task-ready", Status: models.StatusDone},
+		{ID: "task-ready-b", Title: "B", TaskType: models.TypeDefect, ProjectID: "task-ready", Status: models.StatusDone},
+		{ID: "task-open", Title: "Open", TaskType: models.TypeStory, Status: models.StatusInProgress, EndDate: past},
+		{ID: "task-open-a", Title: "A", TaskType: models.TypeSubtask, ProjectID: "task-open", Status: models.StatusDone},
+		{ID: "task-open-b", Title: "B", TaskType: models.TypeSubtask, ProjectID: "task-open", Status: models.StatusTodo},
+		{ID: "task-later", Title: "Later", TaskType: models.TypeStory, Status: models.StatusInProgress, EndDate: future},
+	} {
+		task.UserID = "u-1"
+		require.NoError(t, db.Create(&task).Error)
+	}
+
+	r := gin.New()
+	r.Use(middleware.ErrorHandler(), middleware.JWTAuthMiddleware())
+	r.GET("/api/tasks/:id", GetTaskByID)
+	r.POST("/api/tasks/:id/auto-complete", AutoCompleteTask)
+	token, err := auth.GenerateToken("u-1", "alice", models.RoleMember)
+	require.NoError(t, err)
+	call := func(method, path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	completable := func(id string) any {
+		w := call(http.MethodGet, "/api/tasks/"+id)
+		require.Equal(t, http.StatusOK, w.Code)
+		var view map[string]any
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &view))
+		return view["completable"]
+	}
+
+	require.Equal(t, true, completable("task-ready"))
+	require.Equal(t, false, completable("task-open"))
+
+	// All children done and past the end date: completed, even straight from todo
+	w := call(http.MethodPost, "/api/tasks/task-ready/auto-complete")
+	require.Equal(t, http.StatusOK, w.Code)
+	var stored models.Task
+	require.NoError(t, db.First(&stored, "id = ?", "task-ready").Error)
+	require.Equal(t, models.StatusDone, stored.Status)
+	require.Nil(t, completable("task-ready")) // done stories aren't flagged
+
+	// An open child blocks completion
+	w = call(http.MethodPost, "/api/tasks/task-open/auto-complete")
+	require.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	require.Contains(t, w.Body.String(), "1 subtasks/defects are not done")
+	var open models.Task
+	require.NoError(t, db.First(&open, "id = ?", "task-open").Error)
+	require.Equal(t, models.StatusInProgress, open.Status)
+
+	w = call(http.MethodPost, "/api/tasks/task-later/auto-complete")
+	require.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	require.Contains(t, w.Body.String(), "end date has not passed")
+
+	require.Equal(t, http.StatusUnprocessableEntity, call(http.MethodPost, "/api/tasks/task-open-b/auto-complete").Code)
+}
+
+func TestBulkCreateTasks_AllOrNothing(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := testutil.NewInMemoryDB()
+	require.NoError(t, err)
+	database.DB = db
+	require.NoError(t, db.Create(&models.Task{ID: "task-story", Title: "Story", TaskType: models.TypeStory, UserID: "u-1"}).Error)
+
+	r := gin.New()
+	r.Use(middleware.JWTAuthMiddleware())
+	r.POST("/api/tasks/bulk", BulkCreateTasks)
+	token, err := auth.GenerateToken("u-1", "alice", models.RoleMember)
+	require.NoError(t, err)
+	post := func(items []map[string]any) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(map[string]any{"tasks": items})
+		req := httptest.NewRequest(http.MethodPost, "/api/tasks/bulk", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	item := func(title, taskType, projectID string) map[string]any {
+		return map[string]any{
+			"title":       title,
+			"description": "Desc",
+			"assignee":    map[string]string{"id": "u-2", "name": "bob"},
+			"startDate":   "2025-01-01",
+			"endDate":     "2025-01-03",
+			"taskType":    taskType,
+			"projectId":   projectID,
+		}
+	}
+	countTasks := func() int64 {
+		var n int64
+		require.NoError(t, db.Model(&models.Task{}).Count(&n).Error)
+		return n
+	}
+
+	// One bad item rejects the whole batch
+	missingTitle := item("", "story", "")
+	w := post([]map[string]any{
+		item("First", "story", ""),
+		missingTitle,
+		item("Orphan", "subtask", "task-nope"),
+		item("Child", "subtask", "task-story"),
+	})
+	require.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	var failed struct {
+		Errors map[string]string `json:"errors"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &failed))
+	require.Len(t, failed.Errors, 2)
+	require.Contains(t, failed.Errors["1"], "Title")
+	require.Equal(t, "Invalid projectId: parent story not found", failed.Errors["2"])
+	require.Equal(t, int64(1), countTasks())
+
+	// All valid: inserted together, in order
+	w = post([]map[string]any{
+		item("First", "story", ""),
+		item("Child", "subtask", "task-story"),
+		item("Second", "story", ""),
+	})
+	require.Equal(t, http.StatusCreated, w.Code)
+	var created struct {
+		Created []map[string]any `json:"created"`
+		Count   int              `json:"count"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	require.Equal(t, 3, created.Count)
+	require.Equal(t, "First", created.Created[0]["title"])
+	require.Equal(t, "task-story", created.Created[1]["projectId"])
+	require.Equal(t, float64(2), created.Created[2]["effort"])
+	require.Equal(t, int64(4), countTasks())
+
+	// At most 50 items
+	items := make([]map[string]any, maxBulkCreateTasks+1)
+	for i := range items {
+		items[i] = item(fmt.Sprintf("Task %d", i), "story", "")
+	}
+	require.Equal(t, http.StatusBadRequest, post(items).Code)
+	require.Equal(t, int64(4), countTasks())
+	w = post(items[:maxBulkCreateTasks])
+	require.Equal(t, http.StatusCreated, w.Code)
+	require.Equal(t, int64(4+maxBulkCreateTasks), countTasks())
+}
+
+func TestBulkDeleteTasks_PartialOwnership(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := testutil.NewInMemoryDB()
+	require.NoError(t, err)
+	database.DB = db
+
+	seed := []models.Task{
+		{ID: "task-1", Title: "Mine 1", TaskType: models.TypeStory, UserID: "u-bulk"},
+		{ID: "task-2", Title: "Mine 2", TaskType: models.TypeStory, UserID: "u-bulk"},
+		{ID: "task-3", Title: "Not mine", TaskType: models.TypeStory, UserID: "u-other"},
+		{ID: "task-4", Title: "Story with child", TaskType: models.TypeStory, UserID: "u-bulk"},
+		{ID: "task-5", Title: "Child", TaskType: models.TypeSubtask, ProjectID: "task-4", UserID: "u-bulk"},
+	}
+	for _, task := range seed {
+		require.NoError(t, db.Create(&task).Error)
+	}
+
+	client := &recordingClient{}
+	realtime.GetHub().Register("u-bulk", client)
+	defer realtime.GetHub().Unregister("u-bulk", client)
+
+	r := gin.New()
+	r.Use(middleware.JWTAuthMiddleware())
+	r.DELETE("/api/tasks/bulk", BulkDeleteTasks)
+	token, err := auth.GenerateToken("u-bulk", "dana", models.RoleMember)
+	require.NoError(t, err)
+	del := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodDelete, "/api/tasks/bulk", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	live := func(id string) bool {
+		var n int64
+		require.NoError(t, db.Model(&models.Task{}).Where("id = ?", id).Count(&n).Error)
+		return n == 1
+	}
+
+	w := del(`{"ids":["task-1","task-2","task-3","task-4","task-missing","task-1"]}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp struct {
+		Deleted     []string `json:"deleted"`
+		NotFound    []string `json:"notFound"`
+		HasChildren []string `json:"hasChildren"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Equal(t, []string{"task-1", "task-2"}, resp.Deleted)
+	require.Equal(t, []string{"task-3", "task-missing"}, resp.NotFound)
+	require.Equal(t, []string{"task-4"}, resp.HasChildren)
+	require.False(t, live("task-1"))
+	require.False(t, live("task-2"))
+	require.True(t, live("task-3"))
+	require.True(t, live("task-4"))
+
+	// Deleted rows stay recoverable in the trash
+	var trashed models.Task
+	require.NoError(t, db.Unscoped().First(&trashed, "id = ?", "task-1").Error)
+	require.NotNil(t, trashed.PurgeAfter)
+
+	// One event for the whole batch
+	events := client.events(t)
+	require.Len(t, events, 1)
+	require.Equal(t, "task_bulk_deleted", events[0]["type"])
+	require.Equal(t, []any{"task-1", "task-2"}, events[0]["taskIds"])
+
+	// A story goes together with its children
+	w = del(`{"ids":["task-4","task-5"]}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.False(t, live("task-4"))
+	require.False(t, live("task-5"))
+
+	require.Equal(t, http.StatusBadRequest, del(`{"ids":[]}`).Code)
+	ids := make([]string, maxBulkDeleteIDs+1)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("task-%d", i)
+	}
+	body, _ := json.Marshal(map[string]any{"ids": ids})
+	require.Equal(t, http.StatusBadRequest, del(string(body)).Code)
+}
+
+func TestBulkDeleteTasks_RollsBackOnDBError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := testutil.NewInMemoryDB()
+	require.NoError(t, err)
+	database.DB = db
+
+	for _, id := range []string{"task-1", "task-2", "task-3"} {
+		require.NoError(t, db.Create(&models.Task{ID: id, Title: id, TaskType: models.TypeStory, UserID: "u-bulk"}).Error)
+	}
+	// Fail the second delete so the first has to be rolled back
+	deletes := 0
+	require.NoError(t, db.Callback().Delete().Before("gorm:delete").Register("test:fail_delete", func(tx *gorm.DB) {
+		if deletes++; deletes == 2 {
+			_ = tx.AddError(errors.New("disk full"))
+		}
+	}))
+
+	client := &recordingClient{}
+	realtime.GetHub().Register("u-bulk", client)
+	defer realtime.GetHub().Unregister("u-bulk", client)
+
+	r := gin.New()
+	r.Use(middleware.JWTAuthMiddleware())
+	r.DELETE("/api/tasks/bulk", BulkDeleteTasks)
+	token, err := auth.GenerateToken("u-bulk", "dana", models.RoleMember)
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodDelete, "/api/tasks/bulk", strings.NewReader(`{"ids":["task-1","task-2","task-3"]}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusInternalServerError, w.Code)
+
+	var tasks []models.Task
+	require.NoError(t, db.Order("id").Find(&tasks).Error)
+	require.Len(t, tasks, 3)
+	require.Nil(t, tasks[0].PurgeAfter)
+	var audits int64
+	require.NoError(t, db.Model(&models.TaskAudit{}).Count(&audits).Error)
+	require.Zero(t, audits)
+	require.Empty(t, client.events(t))
+}
+
+func TestReassignTasks_UserIDAliasesKeepOwnership(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := testutil.NewInMemoryDB()
+	require.NoError(t, err)
+	database.DB = db
+
+	require.NoError(t, db.Create(&models.User{ID: "u-2", Username: "bob", Password: "x"}).Error)
+	require.NoError(t, db.Create(&models.User{ID: "u-3", Username: "carol", Password: "x"}).Error)
+	for _, id := range []string{"task-1", "task-2"} {
+		require.NoError(t, db.Create(&models.Task{ID: id, Title: id, TaskType: models.TypeStory, UserID: "u-2", AssigneeID: "u-2"}).Error)
+	}
+	// Finished work is handed off too
+	require.NoError(t, db.Create(&models.Task{ID: "task-3", Title: "task-3", Status: models.StatusDone,
+		TaskType: models.TypeStory, UserID: "u-2", AssigneeID: "u-2"}).Error)
+
+	client := &recordingClient{}
+	realtime.GetHub().Register("u-3", client)
+	defer realtime.GetHub().Unregister("u-3", client)
+
+	r := gin.New()
+	r.Use(middleware.JWTAuthMiddleware())
+	r.POST("/api/tasks/reassign", middleware.RoleMiddleware(models.RoleAdmin), ReassignTasks)
+	token, err := auth.GenerateToken("u-1", "alice", models.RoleAdmin)
+	require.NoError(t, err)
+	post := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/tasks/reassign", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	require.Equal(t, http.StatusBadRequest, post(`{"fromUserId":"u-2"}`).Code)
+	require.Equal(t, http.StatusBadRequest, post(`{"fromUserId":"u-404","toUserId":"u-3"}`).Code)
+
+	w := post(`{"fromUserId":"u-2","toUserId":"u-3"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp struct {
+		Reassigned int `json:"reassigned"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Equal(t, 3, resp.Reassigned)
+
+	var tasks []models.Task
+	require.NoError(t, db.Order("id").Find(&tasks).Error)
+	for _, task := range tasks {
+		require.Equal(t, "u-3", task.AssigneeID)
+		require.Equal(t, "u-2", task.UserID)
+	}
+
+	events := client.events(t)
+	require.Len(t, tasks, 3)
+	require.Len(t, events, 4)
+	require.Equal(t, "task_updated", events[0]["type"])
+	require.Equal(t, "task_updated", events[1]["type"])
+	require.Equal(t, "task_updated", events[2]["type"])
+	require.Equal(t, "tasks_reassigned", events[3]["type"])
+}
+
+func TestBulkUpdateStatus_BulkRouteUpdatesAllAtomically(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := testutil.NewInMemoryDB()
+	require.NoError(t, err)
+	database.DB = db
+
+	for _, id := range []string{"task-1", "task-2", "task-3"} {
+		require.NoError(t, db.Create(&models.Task{ID: id, Title: id, Status: models.StatusInProgress,
+			TaskType: models.TypeStory, UserID: "u-bulk"}).Error)
+	}
+	// Count UPDATE statements issued against tasks
+	updates := 0
+	require.NoError(t, db.Callback().Update().After("gorm:update").Register("test:count_updates", func(tx *gorm.DB) {
+		if tx.Statement.Table == "tasks" {
+			updates++
+		}
+	}))
+
+	r := gin.New()
+	r.Use(middleware.JWTAuthMiddleware())
+	r.PATCH("/api/tasks/bulk/status", BulkUpdateStatus)
+	token, err := auth.GenerateToken("u-bulk", "dana", models.RoleMember)
+	require.NoError(t, err)
+	patch := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPatch, "/api/tasks/bulk/status", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	require.Equal(t, http.StatusBadRequest, patch(`{"ids":["task-1"],"status":"blocked"}`).Code)
+
+	w := patch(`{"ids":["task-1","task-2","task-3"],"status":"done"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp struct {
+		Updated  int      `json:"updated"`
+		NotFound []string `json:"notFound"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Equal(t, 3, resp.Updated)
+	require.Empty(t, resp.NotFound)
+	require.Equal(t, 1, updates)
+
+	var done int64
+	require.NoError(t, db.Model(&models.Task{}).Where("status = ?", models.StatusDone).Count(&done).Error)
+	require.Equal(t, int64(3), done)
+}
+
+func TestGetTasks_OverdueFlagAndFilter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := testutil.NewInMemoryDB()
+	require.NoError(t, err)
+	database.DB = db
+
+	now := time.Now()
+	day := func(offset int) string { return now.AddDate(0, 0, offset).Format("2006-01-02") }
+	for _, task := range []models.Task{
+		{ID: "task-late", Status: models.StatusInProgress, EndDate: day(-3)},
+		{ID: "task-late-2", Status: models.StatusTodo, EndDate: now.AddDate(0, 0, -1).Format("2 Jan 2006")},
+		{ID: "task-late-done", Status: models.StatusDone, EndDate: day(-3)},
+		{ID: "task-due-today", Status: models.StatusInProgress, EndDate: day(0)},
+		{ID: "task-future", Status: models.StatusInProgress, EndDate: day(5)},
+		{ID: "task-garbled", Status: models.StatusInProgress, EndDate: "someday"},
+		{ID: "task-no-date", Status: models.StatusInProgress},
+	} {
+		task.Title = task.ID
+		task.TaskType = models.TypeStory
+		task.UserID = "u-1"
+		require.NoError(t, db.Create(&task).Error)
+	}
+
+	r := gin.New()
+	r.Use(middleware.JWTAuthMiddleware())
+	r.GET("/api/tasks", GetTasks)
+	token, err := auth.GenerateToken("u-1", "alice", models.RoleMember)
+	require.NoError(t, err)
+	list := func(query string) (int, map[string]bool, float64) {
+		req := httptest.NewRequest(http.MethodGet, "/api/tasks?"+query, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		var resp struct {
+			Tasks []map[string]any `json:"tasks"`
+			Total float64          `json:"total"`
+		}
+		_ = json.Unmarshal(w.Body.Bytes(), &resp)
+		overdue := map[string]bool{}
+		for _, task := range resp.Tasks {
+			overdue[task["id"].(string)] = task["overdue"].(bool)
+		}
+		return w.Code, overdue, resp.Total
+	}
+
+	code, overdue, _ := list("limit=20")
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, overdue, 7)
+	require.True(t, overdue["task-late"])
+	require.True(t, overdue["task-late-2"])
+	for _, id := range []string{"task-late-done", "task-due-today", "task-future", "task-garbled", "task-no-date"} {
+		require.False(t, overdue[id], id)
+	}
+
+	code, overdue, total := list("overdue=true&limit=20")
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, map[string]bool{"task-late": true, "task-late-2": true}, overdue)
+	require.Equal(t, float64(2), total)
+
+	// Paging applies after filtering
+	_, overdue, total = list("overdue=true&limit=1&page=2")
+	require.Len(t, overdue, 1)
+	require.Equal(t, float64(2), total)
+
+	code, _, _ = list("overdue=true&cursor=")
+	require.Equal(t, http.StatusBadRequest, code)
+}
+
+func TestMergeTask_MovesChildrenCommentsAndDeletesSource(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := testutil.NewInMemoryDB()
+	require.NoError(t, err)
+	database.DB = db
+
+	for _, task := range []models.Task{
+		{ID: "task-dup", Title: "Login page (dup)", TaskType: models.TypeStory, UserID: "u-1"},
+		{ID: "task-main", Title: "Login page", TaskType: models.TypeStory, UserID: "u-1"},
+		{ID: "task-sub", Title: "Form", TaskType: models.TypeSubtask, ProjectID: "task-dup", UserID: "u-1"},
+		{ID: "task-bug", Title: "Typo", TaskType: models.TypeDefect, ProjectID: "task-dup", UserID: "u-1"},
+		{ID: "task-other", Title: "Elsewhere", TaskType: models.TypeSubtask, ProjectID: "task-main", UserID: "u-1"},
+		{ID: "task-theirs", Title: "Not mine", TaskType: models.TypeStory, UserID: "u-2"},
+	} {
+		require.NoError(t, db.Create(&task).Error)
+	}
+	require.NoError(t, db.Create(&models.Comment{ID: "comment-1", TaskID: "task-dup", UserID: "u-2", Body: "Same as task-main"}).Error)
+
+	r := gin.New()
+	r.Use(middleware.ErrorHandler())
+	r.Use(middleware.JWTAuthMiddleware())
+	r.POST("/api/tasks/:id/merge", MergeTask)
+	token, err := auth.GenerateToken("u-1", "alice", models.RoleMember)
+	require.NoError(t, err)
+	merge := func(id, into string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/tasks/"+id+"/merge", strings.NewReader(`{"into":"`+into+`"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	require.Equal(t, http.StatusBadRequest, merge("task-dup", "task-dup").Code)
+	require.Equal(t, http.StatusNotFound, merge("task-dup", "task-theirs").Code)
+	// A story with children can't be folded into a subtask
+	require.Equal(t, http.StatusConflict, merge("task-dup", "task-other").Code)
+
+	w := merge("task-dup", "task-main")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var resp struct {
+		Task          map[string]any `json:"task"`
+		MergedID      string         `json:"mergedId"`
+		MovedChildIDs []string       `json:"movedChildIds"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Equal(t, "task-main", resp.Task["id"])
+	require.Equal(t, "task-dup", resp.MergedID)
+	require.ElementsMatch(t, []string{"task-sub", "task-bug"}, resp.MovedChildIDs)
+
+	var children []string
+	require.NoError(t, db.Model(&models.Task{}).Where("project_id = ?", "task-main").Order("id").Pluck("id", &children).Error)
+	require.Equal(t, []string{"task-bug", "task-other", "task-sub"}, children)
+
+	var comment models.Comment
+	require.NoError(t, db.First(&comment, "id = ?", "comment-1").Error)
+	require.Equal(t, "task-main", comment.TaskID)
+
+	// The duplicate sits in the trash
+	var n int64
+	require.NoError(t, db.Model(&models.Task{}).Where("id = ?", "task-dup").Count(&n).Error)
+	require.Zero(t, n)
+	var trashed models.Task
+	require.NoError(t, db.Unscoped().First(&trashed, "id = ?", "task-dup").Error)
+	require.NotNil(t, trashed.PurgeAfter)
+	require.NoError(t, db.Model(&models.TaskAudit{}).Where("task_id = ? AND action = ?", "task-dup", models.AuditMerged).Count(&n).Error)
+	require.Equal(t, int64(1), n)
+}
+
+func TestMergeTask_EventUsesExternalIDs(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := testutil.NewInMemoryDB()
+	require.NoError(t, err)
+	database.DB = db
+	for _, id := range []string{"task-dup", "task-main"} {
+		require.NoError(t, db.Create(&models.Task{ID: id, Title: id, TaskType: models.TypeStory, UserID: "user-1"}).Error)
+	}
+
+	client := &recordingClient{}
+	realtime.GetHub().Register("user-1", client)
+	defer realtime.GetHub().Unregister("user-1", client)
+
+	t.Setenv("STRIP_ID_PREFIX", "true")
+	r := gin.New()
+	r.Use(middleware.ErrorHandler())
+	r.Use(middleware.JWTAuthMiddleware())
+	r.POST("/api/tasks/:id/merge", MergeTask)
+	token, err := auth.GenerateToken("user-1", "alice", models.RoleMember)
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodPost, "/api/tasks/dup/merge", strings.NewReader(`{"into":"main"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	events := client.events(t)
+	require.Len(t, events, 1)
+	require.Equal(t, "task_merged", events[0]["type"])
+	require.Equal(t, "dup", events[0]["taskId"])
+	require.Equal(t, "main", events[0]["intoId"])
+	require.Equal(t, "1", events[0]["userId"])
+}
+
+func TestArchiveTask_ToggleAndListFilter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := testutil.NewInMemoryDB()
+	require.NoError(t, err)
+	database.DB = db
+
+	for _, id := range []string{"task-1", "task-2"} {
+		require.NoError(t, db.Create(&models.Task{ID: id, Title: id, Status: models.StatusDone,
+			TaskType: models.TypeStory, UserID: "u-1"}).Error)
+	}
+	require.NoError(t, db.Create(&models.Task{ID: "task-theirs", Title: "Theirs", TaskType: models.TypeStory, UserID: "u-2"}).Error)
+
+	r := gin.New()
+	r.Use(middleware.ErrorHandler())
+	r.Use(middleware.JWTAuthMiddleware())
+	r.GET("/api/tasks", GetTasks)
+	r.GET("/api/tasks/:id", GetTaskByID)
+	r.PATCH("/api/tasks/:id/archive", ArchiveTask)
+	r.PATCH("/api/tasks/:id/unarchive", UnarchiveTask)
+	token, err := auth.GenerateToken("u-1", "alice", models.RoleMember)
+	require.NoError(t, err)
+	call := func(method, path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	listIDs := func(query string) []string {
+		w := call(http.MethodGet, "/api/tasks?limit=100&"+query)
+		require.Equal(t, http.StatusOK, w.Code)
+		var resp struct {
+			Tasks []map[string]any `json:"tasks"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		var ids []string
+		for _, task := range resp.Tasks {
+			ids = append(ids, task["id"].(string))
+		}
+		slices.Sort(ids)
+		return ids
+	}
+	archivedFlag := func(w *httptest.ResponseRecorder) bool {
+		var view map[string]any
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &view))
+		return view["archived"].(bool)
+	}
+
+	w := call(http.MethodPatch, "/api/tasks/task-1/archive")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.True(t, archivedFlag(w))
+	require.Equal(t, http.StatusNotFound, call(http.MethodPatch, "/api/tasks/task-theirs/archive").Code)
+
+	// Hidden by default, included on request
+	require.Equal(t, []string{"task-2", "task-theirs"}, listIDs(""))
+	require.Equal(t, []string{"task-1", "task-2", "task-theirs"}, listIDs("archived=true"))
+
+	// Still readable by its owner, flagged as archived
+	w = call(http.MethodGet, "/api/tasks/task-1")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.True(t, archivedFlag(w))
+
+	w = call(http.MethodPatch, "/api/tasks/task-1/unarchive")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.False(t, archivedFlag(w))
+	require.Equal(t, []string{"task-1", "task-2", "task-theirs"}, listIDs(""))
+
+	var audits []models.AuditAction
+	require.NoError(t, db.Model(&models.TaskAudit{}).Where("task_id = ?", "task-1").Order("id").Pluck("action", &audits).Error)
+	require.Equal(t, []models.AuditAction{models.AuditArchived, models.AuditUnarchived}, audits)
+}
+
+func TestGetTaskByID_PermissionsByOwnershipAndRole(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := testutil.NewInMemoryDB()
+	require.NoError(t, err)
+	database.DB = db
+	require.NoError(t, db.Create(&models.Task{ID: "task-1", Title: "T", Status: models.StatusInProgress,
+		TaskType: models.TypeStory, UserID: "u-owner"}).Error)
+
+	r := gin.New()
+	r.Use(middleware.ErrorHandler())
+	r.Use(middleware.JWTAuthMiddleware())
+	r.GET("/api/tasks/:id", GetTaskByID)
+	permissions := func(userID, role string) map[string]any {
+		token, err := auth.GenerateToken(userID, userID, role)
+		require.NoError(t, err)
+		req := httptest.NewRequest(http.MethodGet, "/api/tasks/task-1", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var view struct {
+			Permissions map[string]any `json:"permissions"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &view))
+		return view.Permissions
+	}
+
+	owner := permissions("u-owner", models.RoleMember)
+	require.Equal(t, true, owner["canEdit"])
+	require.Equal(t, true, owner["canDelete"])
+	require.Equal(t, true, owner["canChangeStatus"])
+	require.Equal(t, []any{"todo", "done"}, owner["statusTransitions"])
+
+	other := permissions("u-other", models.RoleMember)
+	require.Equal(t, false, other["canEdit"])
+	require.Equal(t, false, other["canDelete"])
+	require.Equal(t, false, other["canChangeStatus"])
+	require.Empty(t, other["statusTransitions"])
+
+	// Admins may delete anyone's task but not edit it
+	admin := permissions("u-admin", models.RoleAdmin)
+	require.Equal(t, false, admin["canEdit"])
+	require.Equal(t, true, admin["canDelete"])
+}
+
+func TestTaskLabels_SetOnCreateUpdateAndFilter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := testutil.NewInMemoryDB()
+	require.NoError(t, err)
+	database.DB = db
+	require.NoError(t, db.Create(&models.Task{ID: "task-lookalike", Title: "Lookalike", TaskType: models.TypeStory,
+		UserID: "u-1", Labels: models.Labels{"backend-legacy"}}).Error)
+
+	r := gin.New()
+	r.Use(middleware.ErrorHandler())
+	r.Use(middleware.JWTAuthMiddleware())
+	r.GET("/api/tasks", GetTasks)
+	r.POST("/api/tasks", CreateTask)
+	r.PUT("/api/tasks/:id", UpdateTask)
+	token, err := auth.GenerateToken("u-1", "alice", models.RoleMember)
+	require.NoError(t, err)
+	call := func(method, path string, payload any) *httptest.ResponseRecorder {
+		var body []byte
+		if payload != nil {
+			body, _ = json.Marshal(payload)
+		}
+		req := httptest.NewRequest(method, path, bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	create := func(id string, labels []string) *httptest.ResponseRecorder {
+		return call(http.MethodPost, "/api/tasks", map[string]any{
+			"id": id, "title": id, "description": "Desc", "taskType": "story",
+			"assignee": map[string]string{"id": "u-2", "name": "bob"}, "startDate": "2025-01-01", "endDate": "2025-01-02",
+			"labels": labels,
+		})
+	}
+	listIDs := func(label string) []string {
+		w := call(http.MethodGet, "/api/tasks?limit=100&label="+url.QueryEscape(label), nil)
+		require.Equal(t, http.StatusOK, w.Code)
+		var resp struct {
+			Tasks []map[string]any `json:"tasks"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		var ids []string
+		for _, task := range resp.Tasks {
+			ids = append(ids, task["id"].(string))
+		}
+		slices.Sort(ids)
+		return ids
+	}
+
+	w := create("task-api", []string{" Backend", "urgent", "backend", ""})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var created map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	require.Equal(t, []any{"backend", "urgent"}, created["labels"])
+	require.Equal(t, http.StatusCreated, create("task-ui", []string{"frontend"}).Code)
+	require.Equal(t, http.StatusCreated, create("task-plain", nil).Code)
+
+	// Whole labels only: backend-legacy is not backend
+	require.Equal(t, []string{"task-api"}, listIDs("backend"))
+	require.Equal(t, []string{"task-api"}, listIDs("URGENT"))
+	require.Empty(t, listIDs("back"))
+	require.Len(t, listIDs(""), 4)
+
+	w = call(http.MethodPut, "/api/tasks/task-ui", map[string]any{"labels": []string{"frontend", "backend"}})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.Equal(t, []string{"task-api", "task-ui"}, listIDs("backend"))
+
+	// Omitting labels leaves them alone; [] clears them
+	require.Equal(t, http.StatusOK, call(http.MethodPut, "/api/tasks/task-ui", map[string]any{"title": "UI"}).Code)
+	require.Equal(t, []string{"task-ui"}, listIDs("frontend"))
+	w = call(http.MethodPut, "/api/tasks/task-ui", map[string]any{"labels": []string{}})
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Empty(t, listIDs("frontend"))
+
+	tooMany := make([]string, maxTaskLabels+1)
+	for i := range tooMany {
+		tooMany[i] = fmt.Sprintf("label-%d", i)
+	}
+	require.Equal(t, http.StatusBadRequest, create("task-noisy", tooMany).Code)
+	require.Equal(t, http.StatusBadRequest, call(http.MethodPut, "/api/tasks/task-api",
+		map[string]any{"labels": []string{strings.Repeat("x", maxLabelLength+1)}}).Code)
+}
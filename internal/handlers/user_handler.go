@@ -1,38 +1,422 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
-	"task-management-api/internal/database"
+	"slices"
+	"strconv"
+	"strings"
+	"task-management-api/internal/auth"
 	"task-management-api/internal/models"
+	"task-management-api/internal/response"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 )
 
-type UserResponse struct {
-	ID       string `json:"id"`
-	Username string `json:"username"`
+// PromoteAdmin grants the admin role to the user named by ADMIN_USERNAME, so a fresh install has
+// someone who can reach the admin-only routes. Call it once at startup, after the user registered.
+// It does nothing when ADMIN_USERNAME is unset and returns an error when no such user exists.
+func PromoteAdmin(db *gorm.DB) error {
+	username := adminUsername()
+	if username == "" {
+		return nil
+	}
+	var user models.User
+	if err := db.Where("username = ?", username).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("ADMIN_USERNAME %q: no such user", username)
+		}
+		return err
+	}
+	if user.Role == models.RoleAdmin {
+		return nil
+	}
+	if err := db.Model(&user).Update("role", models.RoleAdmin).Error; err != nil {
+		return err
+	}
+	invalidateCachedUser(user.ID)
+	return nil
 }
 
 // GetUsers returns all users (protected)
 // GET /api/users
+// Pagination is opt-in: the full list is returned unless page or limit is supplied.
 func GetAllUsers(c *gin.Context) {
+	// Session makes the base query safe to reuse for both Count and Find
+	query := requestDB(c).Model(&models.User{}).Order("username asc").Session(&gorm.Session{})
+
+	resp := gin.H{}
+	if c.Query("page") != "" || c.Query("limit") != "" {
+		page, limit, offset := parsePagination(c)
+		var total int64
+		if err := query.Count(&total).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count users"})
+			return
+		}
+		resp = paginationMeta(total, page, limit)
+		query = query.Limit(limit).Offset(offset)
+	}
+
 	var users []models.User
-	if err := database.GetDB().Find(&users).Error; err != nil {
+	if err := query.Find(&users).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch users"})
 		return
 	}
 
 	// Map to safe response payload
-	resp := make([]UserResponse, 0, len(users))
-	for _, u := range users {
-		resp = append(resp, UserResponse{
-			ID:       u.ID,
-			Username: u.Username,
-		})
+	resp["users"] = response.UsersView(users, c.GetString("role"))
+	resp["count"] = len(users)
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetUserWorkload handles GET /api/users/:id/workload
+// Returns the effort of the user's open (not done) assigned tasks, raw and weighted by priority
+func GetUserWorkload(c *gin.Context) {
+	targetUserID := strings.TrimSpace(c.Param("id"))
+	if targetUserID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "User ID is required"})
+		return
+	}
+
+	var workload struct {
+		OpenTasks      int64
+		TotalEffort    int64
+		WeightedEffort float64
+	}
+	if err := requestDB(c).Model(&models.Task{}).
+		Select("COUNT(*) as open_tasks, COALESCE(SUM(effort), 0) as total_effort, COALESCE(SUM(effort * "+priorityWeightExpr+"), 0) as weighted_effort").
+		Where("assignee_id = ? AND status <> ?", targetUserID, models.StatusDone).
+		Scan(&workload).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute workload"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"userId":         targetUserID,
+		"openTasks":      workload.OpenTasks,
+		"totalEffort":    workload.TotalEffort,
+		"weightedEffort": workload.WeightedEffort,
+	})
+}
+
+// Velocity window defaults and caps
+const (
+	defaultVelocityWeeks = 8
+	maxVelocityWeeks     = 52
+)
+
+// startOfWeek returns the Monday 00:00 UTC of the week containing t
+func startOfWeek(t time.Time) time.Time {
+	t = t.UTC()
+	daysSinceMonday := (int(t.Weekday()) + 6) % 7
+	return time.Date(t.Year(), t.Month(), t.Day()-daysSinceMonday, 0, 0, 0, 0, time.UTC)
+}
+
+// GetUserVelocity handles GET /api/users/:id/velocity?weeks=N
+// Returns completed-task counts and effort per calendar week (Monday-based, UTC) over the last N weeks
+// (default 8, max 52), oldest first and including the current week. A done task counts toward the
+// week of its last update; weeks without completions are reported as zeros.
+func GetUserVelocity(c *gin.Context) {
+	targetUserID := strings.TrimSpace(c.Param("id"))
+	if targetUserID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "User ID is required"})
+		return
+	}
+	weeks, err := strconv.Atoi(c.DefaultQuery("weeks", strconv.Itoa(defaultVelocityWeeks)))
+	if err != nil || weeks < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "weeks must be a positive integer"})
+		return
+	}
+	if weeks > maxVelocityWeeks {
+		weeks = maxVelocityWeeks
+	}
+
+	type weekVelocity struct {
+		WeekStart string `json:"weekStart"`
+		Completed int    `json:"completed"`
+		Effort    int    `json:"effort"`
+	}
+	first := startOfWeek(time.Now()).AddDate(0, 0, -7*(weeks-1))
+	series := make([]weekVelocity, weeks)
+	for i := range series {
+		series[i].WeekStart = first.AddDate(0, 0, 7*i).Format("2006-01-02")
+	}
+
+	var done []models.Task
+	if err := requestDB(c).Select("id", "effort", "updated_at").
+		Where("assignee_id = ? AND status = ? AND updated_at >= ?", targetUserID, models.StatusDone, first).
+		Find(&done).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute velocity"})
+		return
+	}
+	for _, task := range done {
+		i := int(startOfWeek(task.UpdatedAt).Sub(first).Hours() / (24 * 7))
+		if i < 0 || i >= weeks {
+			continue
+		}
+		series[i].Completed++
+		series[i].Effort += task.Effort
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"userId": targetUserID,
+		"weeks":  weeks,
+		"series": series,
+	})
+}
+
+// parseActivityBound parses a from/to query value (ISO date or RFC3339).
+// A date-only upper bound covers that whole day.
+func parseActivityBound(raw string, upper bool) (time.Time, error) {
+	t, ok := models.ParseTaskDate(raw)
+	if !ok {
+		return time.Time{}, fmt.Errorf("invalid date %q; use YYYY-MM-DD or RFC3339", raw)
+	}
+	if upper && len(raw) == len("2006-01-02") {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
+
+// GetUserActivity handles GET /api/users/:id/activity?from=&to=
+// Returns the tasks the user created, updated, changed status on or deleted within the window
+// (default: since the start of today, UTC), one entry per task with the distinct actions taken,
+// most recently touched first. Built from the task audit trail.
+func GetUserActivity(c *gin.Context) {
+	targetUserID := strings.TrimSpace(c.Param("id"))
+	if targetUserID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "User ID is required"})
+		return
+	}
+
+	now := time.Now().UTC()
+	from := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	to := now
+	var err error
+	if raw := c.Query("from"); raw != "" {
+		if from, err = parseActivityBound(raw, false); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "from: " + err.Error()})
+			return
+		}
+	}
+	if raw := c.Query("to"); raw != "" {
+		if to, err = parseActivityBound(raw, true); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "to: " + err.Error()})
+			return
+		}
+	}
+	if !from.Before(to) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from must be before to"})
+		return
+	}
+
+	db := requestDB(c)
+	var entries []models.TaskAudit
+	if err := db.Where("user_id = ? AND created_at >= ? AND created_at < ?", targetUserID, from, to).
+		Order("created_at desc, id desc").
+		Find(&entries).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch activity"})
+		return
+	}
+
+	type taskActivity struct {
+		TaskID         string               `json:"taskId"`
+		Title          string               `json:"title"`
+		Actions        []models.AuditAction `json:"actions"`
+		LastActivityAt time.Time            `json:"lastActivityAt"`
+	}
+	activity := []*taskActivity{}
+	byTask := map[string]*taskActivity{}
+	var taskIDs []string
+	for _, e := range entries {
+		a, ok := byTask[e.TaskID]
+		if !ok {
+			// Entries are newest first, so the first one seen is the latest
+			a = &taskActivity{TaskID: e.TaskID, LastActivityAt: e.CreatedAt}
+			byTask[e.TaskID] = a
+			activity = append(activity, a)
+			taskIDs = append(taskIDs, e.TaskID)
+		}
+		if !slices.Contains(a.Actions, e.Action) {
+			a.Actions = append(a.Actions, e.Action)
+		}
+	}
+
+	// Titles come from the tasks themselves, including deleted ones
+	if len(taskIDs) > 0 {
+		var tasks []models.Task
+		if err := db.Unscoped().Select("id", "title").Where("id IN ?", taskIDs).Find(&tasks).Error; err == nil {
+			for _, t := range tasks {
+				byTask[t.ID].Title = t.Title
+			}
+		}
+	}
+	for _, a := range activity {
+		a.TaskID = response.ExternalID(a.TaskID)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"userId":   targetUserID,
+		"from":     from,
+		"to":       to,
+		"activity": activity,
+		"count":    len(activity),
+	})
+}
+
+// ResetPasswordRequest is the payload for an admin password reset.
+// Password uses the same client-side hash scheme as login.
+type ResetPasswordRequest struct {
+	Password     string `json:"password" binding:"required"`
+	RevokeTokens bool   `json:"revokeTokens"`
+}
+
+// ResetPassword handles POST /api/users/:id/reset-password (admin only, via the route's RoleMiddleware)
+// Sets a new password for a (e.g. locked-out) user and lifts any login lockout. With
+// revokeTokens=true the user's existing tokens stop working immediately, signing them out everywhere.
+func ResetPassword(c *gin.Context) {
+	targetUserID := strings.TrimSpace(c.Param("id"))
+	var req ResetPasswordRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	db := requestDB(c)
+	var user models.User
+	if err := db.Where("id = ?", targetUserID).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user"})
+		}
+		return
+	}
+
+	hashed, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process password"})
+		return
+	}
+	if err := db.Model(&user).Update("password", string(hashed)).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset password"})
+		return
+	}
+	// The reset is how a locked-out user gets back in, so drop their failed logins too
+	if err := clearLoginFailures(db, user.Username); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset password"})
+		return
+	}
+	invalidateCachedUser(user.ID)
+	recordAuthEvent(c, user.ID, models.EventPasswordChange)
+	if req.RevokeTokens {
+		auth.RevokeUserTokens(user.ID, time.Now())
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":       "Password reset successfully",
+		"id":            targetUserID,
+		"tokensRevoked": req.RevokeTokens,
+	})
+}
+
+// DeleteUser handles DELETE /api/users/:id?reassignTo=&transferOwnership=true (admin only, via the route's RoleMiddleware)
+// Soft-deletes the user so they can no longer log in, and revokes the tokens already issued to
+// them. Tasks assigned to them move to reassignTo
+// (or become unassigned when omitted); with transferOwnership=true the tasks they created move too.
+// Every moved task gets an audit entry.
+func DeleteUser(c *gin.Context) {
+	targetUserID := strings.TrimSpace(c.Param("id"))
+	reassignTo := strings.TrimSpace(c.Query("reassignTo"))
+	transferOwnership := c.Query("transferOwnership") == "true"
+
+	if targetUserID == c.GetString("user_id") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You cannot delete your own account"})
+		return
+	}
+	if reassignTo == targetUserID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "reassignTo must be a different user"})
+		return
+	}
+	if transferOwnership && reassignTo == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "reassignTo is required to transfer ownership"})
+		return
+	}
+
+	db := requestDB(c)
+
+	var user models.User
+	if err := db.Where("id = ?", targetUserID).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user"})
+		}
+		return
+	}
+	if reassignTo != "" {
+		if err := db.Where("id = ?", reassignTo).First(&models.User{}).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "reassignTo user not found"})
+			} else {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user"})
+			}
+			return
+		}
+	}
+
+	actorID := c.GetString("user_id")
+	var reassigned, transferred int64
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var movedIDs []string
+		if err := tx.Model(&models.Task{}).Where("assignee_id = ?", targetUserID).Pluck("id", &movedIDs).Error; err != nil {
+			return err
+		}
+		res := tx.Model(&models.Task{}).Where("assignee_id = ?", targetUserID).Update("assignee_id", reassignTo)
+		if res.Error != nil {
+			return res.Error
+		}
+		reassigned = res.RowsAffected
+
+		if transferOwnership {
+			var ownedIDs []string
+			if err := tx.Model(&models.Task{}).Where("user_id = ?", targetUserID).Pluck("id", &ownedIDs).Error; err != nil {
+				return err
+			}
+			res = tx.Model(&models.Task{}).Where("user_id = ?", targetUserID).Update("user_id", reassignTo)
+			if res.Error != nil {
+				return res.Error
+			}
+			transferred = res.RowsAffected
+			for _, id := range ownedIDs {
+				if !slices.Contains(movedIDs, id) {
+					movedIDs = append(movedIDs, id)
+				}
+			}
+		}
+
+		// One audit entry per task that changed hands, even if both its assignee and owner moved
+		for _, id := range movedIDs {
+			recordAudit(tx, id, actorID, models.AuditUpdated)
+		}
+		return tx.Delete(&user).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete user"})
+		return
 	}
+	auth.RevokeUserTokens(targetUserID, time.Now())
+	invalidateCachedUser(targetUserID)
+	invalidateStats(targetUserID, reassignTo)
 
 	c.JSON(http.StatusOK, gin.H{
-		"users": resp,
-		"count": len(resp),
+		"message":          "User deleted successfully",
+		"id":               targetUserID,
+		"reassignedTasks":  reassigned,
+		"transferredTasks": transferred,
 	})
 }
@@ -1,9 +1,13 @@
 package handlers
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"task-management-api/internal/auth"
 	"task-management-api/internal/database"
@@ -29,11 +33,411 @@ func TestGetAllUsers(t *testing.T) {
 	r.Use(middleware.JWTAuthMiddleware())
 	r.GET("/api/users", GetAllUsers)
 
-	token, _ := auth.GenerateToken("u-1", "alice")
+	token, _ := auth.GenerateToken("u-1", "alice", models.RoleMember)
 	req := httptest.NewRequest(http.MethodGet, "/api/users", nil)
 	req.Header.Set("Authorization", "Bearer "+token)
 	w := httptest.NewRecorder()
 
 	r.ServeHTTP(w, req)
 	require.Equal(t, http.StatusOK, w.Code)
+	var all struct {
+		Users []map[string]any `json:"users"`
+		Count int              `json:"count"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &all))
+	require.Equal(t, 2, all.Count)
+
+	// Opt-in pagination
+	req = httptest.NewRequest(http.MethodGet, "/api/users?limit=1&page=2", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	var paged struct {
+		Users      []map[string]any `json:"users"`
+		Total      int64            `json:"total"`
+		TotalPages int              `json:"totalPages"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &paged))
+	require.Len(t, paged.Users, 1)
+	require.Equal(t, "bob", paged.Users[0]["username"])
+	require.Equal(t, int64(2), paged.Total)
+	require.Equal(t, 2, paged.TotalPages)
+}
+
+func TestGetUserWorkload_WeightsHighPriority(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := testutil.NewInMemoryDB()
+	require.NoError(t, err)
+	database.DB = db
+
+	seed := []models.Task{
+		{ID: "task-1", Title: "A", Effort: 4, Priority: models.PriorityHigh, Status: models.StatusTodo},
+		{ID: "task-2", Title: "B", Effort: 4, Priority: models.PriorityHigh, Status: models.StatusInProgress},
+		{ID: "task-3", Title: "C", Effort: 2, Priority: models.PriorityLow, Status: models.StatusTodo},
+		{ID: "task-4", Title: "D", Effort: 9, Priority: models.PriorityHigh, Status: models.StatusDone}, // not open
+	}
+	for _, task := range seed {
+		task.AssigneeID = "u-2"
+		task.UserID = "u-1"
+		task.TaskType = models.TypeStory
+		require.NoError(t, db.Create(&task).Error)
+	}
+
+	r := gin.New()
+	r.Use(middleware.JWTAuthMiddleware())
+	r.GET("/api/users/:id/workload", GetUserWorkload)
+	r.GET("/api/stats/:userid", GetStatsByUser)
+
+	token, _ := auth.GenerateToken("u-1", "alice", models.RoleMember)
+	get := func(url string) map[string]float64 {
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+		var resp map[string]any
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		out := map[string]float64{}
+		for k, v := range resp {
+			if f, ok := v.(float64); ok {
+				out[k] = f
+			}
+		}
+		return out
+	}
+
+	workload := get("/api/users/u-2/workload")
+	require.Equal(t, float64(10), workload["totalEffort"])
+	require.Equal(t, float64(4*3+4*3+2*1), workload["weightedEffort"])
+	require.Greater(t, workload["weightedEffort"], workload["totalEffort"])
+
+	stats := get("/api/stats/u-2")
+	require.Equal(t, float64(4*3+4*3+2*1+9*3), stats["weightedEffort"])
+}
+
+func TestDeleteUser_ReassignsAndBlocksLogin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := testutil.NewInMemoryDB()
+	require.NoError(t, err)
+	database.DB = db
+
+	r := gin.New()
+	r.POST("/api/register", Register)
+	r.POST("/api/login", Login)
+	r.GET("/api/tasks", middleware.JWTAuthMiddleware(), GetTasks)
+	admin := func(c *gin.Context) {
+		c.Set("user_id", "u-admin")
+		c.Set("role", models.RoleAdmin)
+	}
+	r.DELETE("/api/users/:id", admin, DeleteUser)
+	listTasks := func(token string) int {
+		req := httptest.NewRequest(http.MethodGet, "/api/tasks", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	login := func(username string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(map[string]string{"username": username, "password": "hash"})
+		req := httptest.NewRequest(http.MethodPost, "/api/login", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	// Sign up the departing user, plus the user taking over
+	body, _ := json.Marshal(map[string]string{"username": "leaver", "password": "hash", "email": "leaver@example.com"})
+	req := httptest.NewRequest(http.MethodPost, "/api/register", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusCreated, w.Code)
+	require.Equal(t, http.StatusOK, login("leaver").Code)
+	var leaver LoginResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &leaver))
+	require.NoError(t, db.Create(&models.User{ID: "u-heir", Username: "heir", Password: "x"}).Error)
+	require.Equal(t, http.StatusOK, listTasks(leaver.Token))
+
+	require.NoError(t, db.Create(&models.Task{ID: "task-1", Title: "Assigned", TaskType: models.TypeStory, AssigneeID: leaver.UserID, UserID: "u-other"}).Error)
+	require.NoError(t, db.Create(&models.Task{ID: "task-2", Title: "Owned", TaskType: models.TypeStory, UserID: leaver.UserID}).Error)
+
+	req = httptest.NewRequest(http.MethodDelete, "/api/users/"+leaver.UserID+"?reassignTo=u-heir&transferOwnership=true", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var assigned, owned models.Task
+	require.NoError(t, db.First(&assigned, "id = ?", "task-1").Error)
+	require.Equal(t, "u-heir", assigned.AssigneeID)
+	require.NoError(t, db.First(&owned, "id = ?", "task-2").Error)
+	require.Equal(t, "u-heir", owned.UserID)
+	var audits []models.TaskAudit
+	require.NoError(t, db.Where("user_id = ? AND action = ?", "u-admin", models.AuditUpdated).Order("task_id").Find(&audits).Error)
+	require.Len(t, audits, 2)
+	require.Equal(t, "task-1", audits[0].TaskID)
+	require.Equal(t, "task-2", audits[1].TaskID)
+
+	// The deleted user can no longer authenticate, neither by login (indistinguishable from a
+	// wrong password) nor with a token issued before the delete
+	w = login("leaver")
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+	require.Contains(t, w.Body.String(), "Invalid credentials")
+	require.Equal(t, http.StatusUnauthorized, listTasks(leaver.Token))
+}
+
+func TestGetUserVelocity_WeeklySeries(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := testutil.NewInMemoryDB()
+	require.NoError(t, err)
+	database.DB = db
+
+	thisWeek := startOfWeek(time.Now())
+	seed := []struct {
+		task models.Task
+		at   time.Time
+	}{
+		{models.Task{ID: "task-1", Status: models.StatusDone, AssigneeID: "u-1", Effort: 1}, thisWeek.Add(time.Hour)},
+		{models.Task{ID: "task-2", Status: models.StatusDone, AssigneeID: "u-1", Effort: 2}, thisWeek.AddDate(0, 0, -7).Add(2 * time.Hour)},
+		{models.Task{ID: "task-3", Status: models.StatusDone, AssigneeID: "u-1", Effort: 3}, thisWeek.AddDate(0, 0, -2)},
+		{models.Task{ID: "task-4", Status: models.StatusDone, AssigneeID: "u-1", Effort: 4}, thisWeek.AddDate(0, 0, -21).Add(time.Hour)},
+		{models.Task{ID: "task-5", Status: models.StatusDone, AssigneeID: "u-1", Effort: 9}, thisWeek.AddDate(0, 0, -60)},   // outside window
+		{models.Task{ID: "task-6", Status: models.StatusInProgress, AssigneeID: "u-1", Effort: 9}, thisWeek.Add(time.Hour)}, // not done
+		{models.Task{ID: "task-7", Status: models.StatusDone, AssigneeID: "u-2", Effort: 9}, thisWeek.Add(time.Hour)},       // other user
+	}
+	for _, s := range seed {
+		s.task.Title = s.task.ID
+		s.task.TaskType = models.TypeStory
+		s.task.UserID = "u-1"
+		s.task.CreatedAt = s.at
+		s.task.UpdatedAt = s.at
+		require.NoError(t, db.Create(&s.task).Error)
+	}
+
+	r := gin.New()
+	r.GET("/api/users/:id/velocity", GetUserVelocity)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/users/u-1/velocity?weeks=4", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp struct {
+		Series []struct {
+			WeekStart string `json:"weekStart"`
+			Completed int    `json:"completed"`
+			Effort    int    `json:"effort"`
+		} `json:"series"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp.Series, 4)
+
+	want := []struct{ completed, effort int }{{1, 4}, {0, 0}, {2, 5}, {1, 1}}
+	for i, wk := range resp.Series {
+		require.Equal(t, thisWeek.AddDate(0, 0, -7*(3-i)).Format("2006-01-02"), wk.WeekStart)
+		require.Equal(t, want[i].completed, wk.Completed, wk.WeekStart)
+		require.Equal(t, want[i].effort, wk.Effort, wk.WeekStart)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/users/u-1/velocity?weeks=zero", nil))
+	require.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestGetUserActivity(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := testutil.NewInMemoryDB()
+	require.NoError(t, err)
+	database.DB = db
+
+	for _, id := range []string{"task-1", "task-2", "task-3", "task-4"} {
+		task := models.Task{ID: id, Title: "Title " + id, TaskType: models.TypeStory, UserID: "u-2"}
+		require.NoError(t, db.Create(&task).Error)
+	}
+	require.NoError(t, db.Delete(&models.Task{}, "id = ?", "task-3").Error)
+
+	now := time.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	at := func(minutes int) time.Time { return today.Add(time.Duration(minutes) * time.Minute) }
+	entries := []models.TaskAudit{
+		{TaskID: "task-1", UserID: "u-2", Action: models.AuditCreated, CreatedAt: at(1)},
+		{TaskID: "task-1", UserID: "u-2", Action: models.AuditStatusChanged, FromStatus: models.StatusTodo, ToStatus: models.StatusInProgress, CreatedAt: at(3)},
+		{TaskID: "task-2", UserID: "u-2", Action: models.AuditUpdated, CreatedAt: at(2)},
+		{TaskID: "task-2", UserID: "u-2", Action: models.AuditUpdated, CreatedAt: at(4)}, // deduplicated
+		{TaskID: "task-3", UserID: "u-2", Action: models.AuditDeleted, CreatedAt: at(5)},
+		{TaskID: "task-4", UserID: "u-9", Action: models.AuditUpdated, CreatedAt: at(6)},        // someone else
+		{TaskID: "task-4", UserID: "u-2", Action: models.AuditUpdated, CreatedAt: at(-60 * 24)}, // yesterday
+	}
+	require.NoError(t, db.Create(&entries).Error)
+
+	r := gin.New()
+	r.GET("/api/users/:id/activity", GetUserActivity)
+	get := func(query string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/users/u-2/activity"+query, nil))
+		return w
+	}
+
+	type activity struct {
+		TaskID  string   `json:"taskId"`
+		Title   string   `json:"title"`
+		Actions []string `json:"actions"`
+	}
+	var resp struct {
+		Activity []activity `json:"activity"`
+	}
+	w := get(fmt.Sprintf("?from=%s&to=%s", today.Format(time.RFC3339), today.Add(time.Hour).Format(time.RFC3339)))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Equal(t, []activity{
+		{TaskID: "task-3", Title: "Title task-3", Actions: []string{"deleted"}},
+		{TaskID: "task-2", Title: "Title task-2", Actions: []string{"updated"}},
+		{TaskID: "task-1", Title: "Title task-1", Actions: []string{"status_changed", "created"}},
+	}, resp.Activity)
+
+	// A date-only window covering yesterday picks up the older entry only
+	yesterday := today.AddDate(0, 0, -1).Format("2006-01-02")
+	w = get("?from=" + yesterday + "&to=" + yesterday)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Equal(t, []activity{{TaskID: "task-4", Title: "Title task-4", Actions: []string{"updated"}}}, resp.Activity)
+
+	require.Equal(t, http.StatusBadRequest, get("?from=yesterday").Code)
+}
+
+func TestUpdateTaskStatus_RecordsAudit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := testutil.NewInMemoryDB()
+	require.NoError(t, err)
+	database.DB = db
+	require.NoError(t, db.Create(&models.Task{ID: "task-1", Title: "T", TaskType: models.TypeStory, UserID: "u-1"}).Error)
+
+	r := gin.New()
+	r.Use(middleware.JWTAuthMiddleware())
+	r.PATCH("/api/tasks/:id/status", UpdateTaskStatus)
+	token, err := auth.GenerateToken("u-1", "alice", models.RoleMember)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPatch, "/api/tasks/task-1/status", bytes.NewReader([]byte(`{"status":"inProgress"}`)))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var entries []models.TaskAudit
+	require.NoError(t, db.Find(&entries).Error)
+	require.Len(t, entries, 1)
+	require.Equal(t, models.AuditStatusChanged, entries[0].Action)
+	require.Equal(t, models.StatusTodo, entries[0].FromStatus)
+	require.Equal(t, models.StatusInProgress, entries[0].ToStatus)
+	require.Equal(t, "u-1", entries[0].UserID)
+}
+
+func TestResetPassword_NewCredentialsAndRevokedToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := testutil.NewInMemoryDB()
+	require.NoError(t, err)
+	database.DB = db
+
+	r := gin.New()
+	r.POST("/api/register", Register)
+	r.POST("/api/login", Login)
+	r.POST("/api/users/:id/reset-password", func(c *gin.Context) {
+		c.Set("user_id", "u-admin")
+		c.Set("role", models.RoleAdmin)
+	}, ResetPassword)
+	r.GET("/api/tasks", middleware.JWTAuthMiddleware(), GetTasks)
+
+	login := func(password string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(map[string]string{"username": "locked", "password": password})
+		req := httptest.NewRequest(http.MethodPost, "/api/login", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	listTasks := func(token string) int {
+		req := httptest.NewRequest(http.MethodGet, "/api/tasks", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	signup, _ := json.Marshal(map[string]string{"username": "locked", "password": "old-hash", "email": "locked@example.com"})
+	req := httptest.NewRequest(http.MethodPost, "/api/register", bytes.NewReader(signup))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	w = login("old-hash")
+	require.Equal(t, http.StatusOK, w.Code)
+	var user LoginResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &user))
+	require.Equal(t, http.StatusOK, listTasks(user.Token))
+
+	// Lock the account out
+	for i := 0; i < maxLoginAttempts(); i++ {
+		require.Equal(t, http.StatusUnauthorized, login("wrong").Code)
+	}
+	require.Equal(t, http.StatusLocked, login("old-hash").Code)
+
+	body := `{"password":"new-hash","revokeTokens":true}`
+	req = httptest.NewRequest(http.MethodPost, "/api/users/"+user.UserID+"/reset-password", bytes.NewReader([]byte(body)))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	require.Equal(t, http.StatusUnauthorized, login("old-hash").Code)
+	require.Equal(t, http.StatusOK, login("new-hash").Code)
+	require.Equal(t, http.StatusUnauthorized, listTasks(user.Token))
+
+	req = httptest.NewRequest(http.MethodPost, "/api/users/u-missing/reset-password", bytes.NewReader([]byte(body)))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestPromoteAdmin_FromEnv(t *testing.T) {
+	db, err := testutil.NewInMemoryDB()
+	require.NoError(t, err)
+	require.NoError(t, db.Create(&models.User{ID: "u-1", Username: "alice", Password: "x"}).Error)
+	require.NoError(t, db.Create(&models.User{ID: "u-2", Username: "bob", Password: "x"}).Error)
+
+	// Unset: nobody is promoted
+	require.NoError(t, PromoteAdmin(db))
+
+	t.Setenv("ADMIN_USERNAME", "alice")
+	require.NoError(t, PromoteAdmin(db))
+	require.NoError(t, PromoteAdmin(db)) // idempotent
+
+	var users []models.User
+	require.NoError(t, db.Order("id").Find(&users).Error)
+	require.Equal(t, models.RoleAdmin, users[0].Role)
+	require.Equal(t, models.RoleMember, users[1].Role)
+
+	t.Setenv("ADMIN_USERNAME", "nobody")
+	require.Error(t, PromoteAdmin(db))
+}
+
+func TestRestoreUserRevocations_RejectsDeletedUsersTokens(t *testing.T) {
+	db, err := testutil.NewInMemoryDB()
+	require.NoError(t, err)
+	user := models.User{ID: "u-restored-revocation", Username: "gone", Password: "x"}
+	require.NoError(t, db.Create(&user).Error)
+	token, err := auth.GenerateToken(user.ID, user.Username, models.RoleMember)
+	require.NoError(t, err)
+	claims, err := auth.ValidateToken(token)
+	require.NoError(t, err)
+
+	// Deleted before a restart: the in-memory revocation is gone
+	require.NoError(t, db.Delete(&user).Error)
+	require.False(t, auth.IsRevoked(token, claims))
+
+	require.NoError(t, RestoreUserRevocations(db))
+	require.True(t, auth.IsRevoked(token, claims))
 }
@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"sync/atomic"
+	"task-management-api/internal/auth"
+	"task-management-api/internal/cache"
+	"task-management-api/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// usersCache holds active users by ID for assignee/creator enrichment, so list endpoints
+// don't reload users per request. It is only consulted once warmed (WarmUsersCache);
+// until then, and always in lazy enrichment mode, lookups go straight to the database.
+var (
+	usersCache     = cache.NewSimpleCache[string, models.User](cache.Options{ConcurrencySafe: true})
+	usersCacheWarm atomic.Bool
+)
+
+func init() {
+	cache.Register(usersCache)
+}
+
+// WarmUsersCache (re)loads every active user into the cache. Call it at startup and periodically.
+// It does nothing in lazy enrichment mode.
+func WarmUsersCache(db *gorm.DB) error {
+	if userEnrichmentMode() == enrichLazy {
+		return nil
+	}
+	var users []models.User
+	if err := db.Find(&users).Error; err != nil {
+		return err
+	}
+	usersCache.Clear()
+	for _, u := range users {
+		usersCache.Set(u.ID, u, 0)
+	}
+	usersCacheWarm.Store(true)
+	return nil
+}
+
+// RestoreUserRevocations revokes the tokens of users deleted within the token lifetime.
+// Revocations are kept in memory only, so call it at startup to keep a deleted user's tokens
+// rejected across restarts.
+func RestoreUserRevocations(db *gorm.DB) error {
+	var users []models.User
+	if err := db.Unscoped().Where("deleted_at > ?", time.Now().Add(-auth.TokenLifetime())).Find(&users).Error; err != nil {
+		return err
+	}
+	for _, u := range users {
+		auth.RevokeUserTokens(u.ID, u.DeletedAt.Time)
+	}
+	return nil
+}
+
+// cacheUser stores a freshly created or logged-in user in the warmed cache
+func cacheUser(u models.User) {
+	if usersCacheWarm.Load() {
+		usersCache.Set(u.ID, u, 0)
+	}
+}
+
+// invalidateCachedUser drops a user after it was updated or deleted;
+// the next lookup reloads it from the database.
+func invalidateCachedUser(userID string) {
+	usersCache.Delete(userID)
+}
+
+// lookupUsers returns the given users keyed by ID. With a warm cache only misses are
+// loaded (in one query) and then cached; otherwise all are loaded from the database.
+// Unknown or deleted users are absent from the result.
+func lookupUsers(db *gorm.DB, ids []string) (map[string]models.User, error) {
+	byID := make(map[string]models.User, len(ids))
+	warm := usersCacheWarm.Load()
+	var missing []string
+	for _, id := range ids {
+		if id == "" {
+			continue
+		}
+		if _, seen := byID[id]; seen {
+			continue
+		}
+		if warm {
+			if u, ok := usersCache.Get(id); ok {
+				byID[id] = u
+				continue
+			}
+		}
+		missing = append(missing, id)
+	}
+	if len(missing) == 0 {
+		return byID, nil
+	}
+
+	var users []models.User
+	if err := db.Where("id IN ?", missing).Find(&users).Error; err != nil {
+		return byID, err
+	}
+	for _, u := range users {
+		byID[u.ID] = u
+		if warm {
+			usersCache.Set(u.ID, u, 0)
+		}
+	}
+	return byID, nil
+}
+
+// enrichAssignees fills task.Assignee from the assignee users and returns the users loaded.
+// extraIDs are loaded alongside (e.g. creators for ?expand=creator).
+func enrichAssignees(db *gorm.DB, tasks []models.Task, extraIDs ...string) map[string]models.User {
+	ids := make([]string, 0, len(tasks)+len(extraIDs))
+	for _, t := range tasks {
+		ids = append(ids, t.AssigneeID)
+	}
+	ids = append(ids, extraIDs...)
+
+	// Enrichment is best effort: on failure tasks keep their stored assignee
+	userByID, err := lookupUsers(db, ids)
+	for i := range tasks {
+		applyAssignee(&tasks[i], userByID, err == nil)
+	}
+	return userByID
+}
+
+// enrichAssignee is enrichAssignees for a single task
+func enrichAssignee(db *gorm.DB, task *models.Task) {
+	tasks := []models.Task{*task}
+	enrichAssignees(db, tasks)
+	*task = tasks[0]
+}
+
+// applyAssignee sets task.Assignee from the loaded users. An assignee without a user row
+// (e.g. a deleted account) is flagged with AssigneeMissing, unless the lookup failed (complete is false).
+func applyAssignee(task *models.Task, userByID map[string]models.User, complete bool) {
+	if task.AssigneeID == "" {
+		return
+	}
+	if u, ok := userByID[task.AssigneeID]; ok {
+		task.Assignee = models.Assignee{ID: u.ID, Name: u.Username}
+	} else if complete {
+		task.AssigneeMissing = true
+	}
+}
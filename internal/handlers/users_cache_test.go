@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"task-management-api/internal/auth"
+	"task-management-api/internal/database"
+	"task-management-api/internal/middleware"
+	"task-management-api/internal/models"
+	"task-management-api/internal/testutil"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestGetTasks_AssigneeEnrichmentUsesWarmCache(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := testutil.NewInMemoryDB()
+	require.NoError(t, err)
+	database.DB = db
+	t.Cleanup(resetUsersCache)
+
+	require.NoError(t, db.Create(&models.User{ID: "u-1", Username: "alice", Password: "x"}).Error)
+	require.NoError(t, db.Create(&models.User{ID: "u-2", Username: "bob", Password: "x"}).Error)
+	require.NoError(t, db.Create(&models.Task{ID: "task-1", Title: "T", TaskType: models.TypeStory, UserID: "u-1", AssigneeID: "u-2"}).Error)
+	require.NoError(t, WarmUsersCache(db))
+
+	r := gin.New()
+	r.Use(middleware.JWTAuthMiddleware())
+	r.GET("/api/tasks", GetTasks)
+	token, err := auth.GenerateToken("u-1", "alice", models.RoleMember)
+	require.NoError(t, err)
+	assigneeName := func() string {
+		req := httptest.NewRequest(http.MethodGet, "/api/tasks", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+		var body struct {
+			Tasks []struct {
+				Assignee models.Assignee `json:"assignee"`
+			} `json:"tasks"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		require.Len(t, body.Tasks, 1)
+		return body.Tasks[0].Assignee.Name
+	}
+
+	require.Equal(t, "bob", assigneeName())
+
+	// A change behind the cache's back is not seen until the entry is invalidated
+	require.NoError(t, db.Model(&models.User{}).Where("id = ?", "u-2").Update("username", "robert").Error)
+	require.Equal(t, "bob", assigneeName())
+
+	invalidateCachedUser("u-2")
+	require.Equal(t, "robert", assigneeName())
+}
+
+// resetUsersCache empties the cache and marks it cold again
+func resetUsersCache() {
+	usersCache.Clear()
+	usersCacheWarm.Store(false)
+}
+
+func TestGetTasks_LazyEnrichmentQueriesOnlyReferencedUsers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	t.Setenv("USER_ENRICHMENT", "lazy")
+	db, err := testutil.NewInMemoryDB()
+	require.NoError(t, err)
+	database.DB = db
+	t.Cleanup(resetUsersCache)
+
+	for i := 0; i < 50; i++ {
+		id := fmt.Sprintf("u-%d", i)
+		require.NoError(t, db.Create(&models.User{ID: id, Username: "user" + id, Password: "x"}).Error)
+	}
+	require.NoError(t, db.Create(&models.Task{ID: "task-1", Title: "T", TaskType: models.TypeStory, UserID: "u-0", AssigneeID: "u-1"}).Error)
+	require.NoError(t, db.Create(&models.Task{ID: "task-2", Title: "T", TaskType: models.TypeStory, UserID: "u-0", AssigneeID: "u-2"}).Error)
+	// Warming is a no-op in lazy mode
+	require.NoError(t, WarmUsersCache(db))
+	require.Zero(t, usersCache.Len())
+
+	// Record every query against the users table
+	type recordedQuery struct {
+		sql  string
+		vars []any
+	}
+	var userQueries []recordedQuery
+	require.NoError(t, db.Callback().Query().After("gorm:query").Register("test:record_users", func(tx *gorm.DB) {
+		if tx.Statement.Table == "users" {
+			userQueries = append(userQueries, recordedQuery{sql: tx.Statement.SQL.String(), vars: tx.Statement.Vars})
+		}
+	}))
+
+	r := gin.New()
+	r.Use(middleware.JWTAuthMiddleware())
+	r.GET("/api/tasks", GetTasks)
+	token, err := auth.GenerateToken("u-0", "user0", models.RoleMember)
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodGet, "/api/tasks?limit=1&sort=asc", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var body struct {
+		Tasks []struct {
+			Assignee models.Assignee `json:"assignee"`
+		} `json:"tasks"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	require.Len(t, body.Tasks, 1)
+	require.Equal(t, "useru-1", body.Tasks[0].Assignee.Name)
+
+	// One lookup, restricted to the page's single assignee (u-2 is on the next page)
+	require.Len(t, userQueries, 1)
+	require.Contains(t, userQueries[0].sql, "IN")
+	require.Equal(t, []any{"u-1"}, userQueries[0].vars)
+	require.Zero(t, usersCache.Len())
+}
@@ -1,11 +1,17 @@
 package handlers
 
 import (
+	"encoding/json"
 	"log"
 	"net/http"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"task-management-api/internal/database"
+	"task-management-api/internal/models"
 	"task-management-api/internal/realtime"
+	"task-management-api/internal/response"
 
 	"github.com/gin-gonic/gin"
 	"github.com/gorilla/websocket"
@@ -14,12 +20,38 @@ import (
 // wsClient implements realtime.Client by wrapping a websocket connection.
 type wsClient struct {
 	conn *websocket.Conn
+	// userID and role identify the authenticated user, for per-client replies such as snapshots
+	userID string
+	role   string
+	// writeMu serializes writes; gorilla allows only one concurrent writer
+	writeMu sync.Mutex
+	// lastPongAt holds the UnixNano time of the last pong (or connect) seen on conn
+	lastPongAt atomic.Int64
+}
+
+// clientMessage is an inbound message from a websocket client
+type clientMessage struct {
+	Action string `json:"action"`
+}
+
+// touch records that the peer was seen alive now.
+func (c *wsClient) touch() {
+	c.lastPongAt.Store(time.Now().UnixNano())
+}
+
+func (c *wsClient) IsAlive(timeout time.Duration) bool {
+	if c == nil || c.conn == nil {
+		return false
+	}
+	return time.Since(time.Unix(0, c.lastPongAt.Load())) <= timeout
 }
 
 func (c *wsClient) Send(message []byte) bool {
 	if c == nil || c.conn == nil {
 		return false
 	}
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
 	c.conn.SetWriteDeadline(time.Now().Add(5 * time.Second))
 	if err := c.conn.WriteMessage(websocket.TextMessage, message); err != nil {
 		return false
@@ -33,6 +65,45 @@ func (c *wsClient) Close() {
 	}
 }
 
+// handleClientMessage answers actions sent by the client: ping (diagnostics) and snapshot
+// (the current task list, for clients that detected drift). Replies go only to this client,
+// never through the hub. Unknown messages are ignored.
+func handleClientMessage(client *wsClient, data []byte) {
+	var msg clientMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return
+	}
+	switch msg.Action {
+	case "ping":
+		if bytes, err := json.Marshal(map[string]any{"type": "pong", "ts": time.Now().UnixMilli()}); err == nil {
+			client.Send(bytes)
+		}
+	case "snapshot":
+		if bytes, err := taskSnapshotFrame(client.userID, client.role); err == nil {
+			client.Send(bytes)
+		} else {
+			log.Println("websocket snapshot failed:", err)
+		}
+	}
+}
+
+// taskSnapshotFrame builds a snapshot frame with every non-archived task the user owns or is assigned to
+func taskSnapshotFrame(userID, role string) ([]byte, error) {
+	var tasks []models.Task
+	if err := database.GetDB().
+		Scopes(models.NotArchived).
+		Where("user_id = ? OR assignee_id = ?", userID, userID).
+		Order("created_at desc").
+		Find(&tasks).Error; err != nil {
+		return nil, err
+	}
+	return json.Marshal(map[string]any{
+		"type":  "snapshot",
+		"tasks": response.TasksView(tasks, role),
+		"ts":    time.Now().UnixMilli(),
+	})
+}
+
 var upgrader = websocket.Upgrader{
 	ReadBufferSize:  1024,
 	WriteBufferSize: 1024,
@@ -58,7 +129,8 @@ func WebSocketHandler(c *gin.Context) {
 		return
 	}
 
-	client := &wsClient{conn: conn}
+	client := &wsClient{conn: conn, userID: userID, role: c.GetString("role")}
+	client.touch()
 	hub := realtime.GetHub()
 	hub.Register(userID, client)
 
@@ -85,18 +157,21 @@ func WebSocketHandler(c *gin.Context) {
 		client.Close()
 	}()
 
-	// Reader loop: drain messages and keep connection alive via pong handler
+	// Reader loop: handle client actions and keep connection alive via pong handler
 	conn.SetReadLimit(1024)
 	conn.SetReadDeadline(time.Now().Add(60 * time.Second))
 	conn.SetPongHandler(func(string) error {
+		client.touch()
 		conn.SetReadDeadline(time.Now().Add(60 * time.Second))
 		return nil
 	})
 
 	for {
-		if _, _, err := conn.ReadMessage(); err != nil {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
 			// Normal close or error; exit loop
 			return
 		}
+		handleClientMessage(client, data)
 	}
 }
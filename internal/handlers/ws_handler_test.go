@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"task-management-api/internal/database"
+	"task-management-api/internal/models"
+	"task-management-api/internal/testutil"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+)
+
+// dialTestWebSocket serves WebSocketHandler for userID and returns a connected client.
+func dialTestWebSocket(t *testing.T, userID string) *websocket.Conn {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/api/ws", func(c *gin.Context) { c.Set("user_id", userID) }, WebSocketHandler)
+	srv := httptest.NewServer(r)
+	t.Cleanup(srv.Close)
+
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + "/api/ws"
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestWebSocket_PingAction(t *testing.T) {
+	conn := dialTestWebSocket(t, "u-ws")
+
+	require.NoError(t, conn.WriteJSON(map[string]string{"action": "ping"}))
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(2*time.Second)))
+	var reply map[string]any
+	require.NoError(t, conn.ReadJSON(&reply))
+	require.Equal(t, "pong", reply["type"])
+	require.Greater(t, reply["ts"].(float64), float64(0))
+}
+
+func TestWebSocket_SnapshotAction(t *testing.T) {
+	db, err := testutil.NewInMemoryDB()
+	require.NoError(t, err)
+	database.DB = db
+	require.NoError(t, db.Create(&models.Task{ID: "task-1", Title: "Mine", TaskType: models.TypeStory, UserID: "u-ws"}).Error)
+	require.NoError(t, db.Create(&models.Task{ID: "task-2", Title: "Assigned", TaskType: models.TypeStory, UserID: "u-9", AssigneeID: "u-ws"}).Error)
+	require.NoError(t, db.Create(&models.Task{ID: "task-3", Title: "Other", TaskType: models.TypeStory, UserID: "u-9"}).Error)
+
+	conn := dialTestWebSocket(t, "u-ws")
+	other := dialTestWebSocket(t, "u-ws")
+
+	require.NoError(t, conn.WriteJSON(map[string]string{"action": "snapshot"}))
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(2*time.Second)))
+	var frame struct {
+		Type  string           `json:"type"`
+		Tasks []map[string]any `json:"tasks"`
+	}
+	require.NoError(t, conn.ReadJSON(&frame))
+	require.Equal(t, "snapshot", frame.Type)
+	ids := []string{}
+	for _, task := range frame.Tasks {
+		ids = append(ids, task["id"].(string))
+	}
+	require.ElementsMatch(t, []string{"task-1", "task-2"}, ids)
+
+	// Only the requesting connection gets the frame
+	require.NoError(t, other.SetReadDeadline(time.Now().Add(200*time.Millisecond)))
+	_, _, err = other.ReadMessage()
+	require.Error(t, err)
+}
@@ -0,0 +1,118 @@
+package health
+
+import (
+	"os"
+	"runtime"
+	"sync"
+	"task-management-api/internal/cache"
+	"task-management-api/internal/database"
+	"time"
+)
+
+const (
+	StatusOK       = "ok"
+	StatusDegraded = "degraded"
+
+	PingOK   = "ok"
+	PingFail = "fail"
+)
+
+// Report is the payload served by GET /health
+type Report struct {
+	Status     string `json:"status"`
+	Version    string `json:"version"`
+	Commit     string `json:"commit"`
+	BuildDate  string `json:"buildDate"`
+	Uptime     string `json:"uptime"`
+	DBPing     string `json:"dbPing"`
+	CacheSize  int    `json:"cacheSize"`
+	Goroutines int    `json:"goroutines"`
+}
+
+// BuildInfo is the payload served by GET /api/version
+type BuildInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildDate string `json:"buildDate"`
+	GoVersion string `json:"goVersion"`
+}
+
+// Healthy reports whether every dependency check passed
+func (r Report) Healthy() bool {
+	return r.DBPing == PingOK
+}
+
+var (
+	startedAt = time.Now()
+
+	buildMu   sync.RWMutex
+	version   string
+	commit    string
+	buildDate string
+
+	// pingDB is swapped out in tests to simulate database failures
+	pingDB = database.Ping
+)
+
+// StartedAt returns when the server process started
+func StartedAt() time.Time {
+	return startedAt
+}
+
+// SetBuildInfo records the values injected at build time via -ldflags
+func SetBuildInfo(v, c, d string) {
+	buildMu.Lock()
+	defer buildMu.Unlock()
+	version, commit, buildDate = v, c, d
+}
+
+// Build returns the build info, with defaults for values not injected at build time
+func Build() BuildInfo {
+	buildMu.RLock()
+	b := BuildInfo{
+		Version:   version,
+		Commit:    commit,
+		BuildDate: buildDate,
+		GoVersion: runtime.Version(),
+	}
+	buildMu.RUnlock()
+
+	// The VERSION env var overrides the build-time version
+	if v := os.Getenv("VERSION"); v != "" {
+		b.Version = v
+	}
+	if b.Version == "" {
+		b.Version = "dev"
+	}
+	if b.Commit == "" {
+		b.Commit = "unknown"
+	}
+	if b.BuildDate == "" {
+		b.BuildDate = "unknown"
+	}
+	return b
+}
+
+// Check collects the current health report
+func Check() Report {
+	b := Build()
+	r := Report{
+		Version:   b.Version,
+		Commit:    b.Commit,
+		BuildDate: b.BuildDate,
+	}
+
+	r.Uptime = time.Since(startedAt).Round(time.Second).String()
+	r.CacheSize = cache.TotalLen()
+	r.Goroutines = runtime.NumGoroutine()
+
+	r.DBPing = PingOK
+	if err := pingDB(); err != nil {
+		r.DBPing = PingFail
+	}
+	r.Status = StatusOK
+	if !r.Healthy() {
+		r.Status = StatusDegraded
+	}
+	return r
+}
@@ -0,0 +1,48 @@
+package health
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheck_ReportsBuildInfoAndHealthyDB(t *testing.T) {
+	orig := pingDB
+	t.Cleanup(func() { pingDB = orig; SetBuildInfo("", "", "") })
+	pingDB = func() error { return nil }
+	SetBuildInfo("1.2.3", "abc123", "2024-01-01")
+
+	r := Check()
+	require.True(t, r.Healthy())
+	require.Equal(t, StatusOK, r.Status)
+	require.Equal(t, PingOK, r.DBPing)
+	require.Equal(t, "1.2.3", r.Version)
+	require.Equal(t, "abc123", r.Commit)
+	require.Equal(t, "2024-01-01", r.BuildDate)
+	require.Positive(t, r.Goroutines)
+	require.NotEmpty(t, r.Uptime)
+}
+
+func TestCheck_VersionEnvOverridesBuildVersion(t *testing.T) {
+	orig := pingDB
+	t.Cleanup(func() { pingDB = orig; SetBuildInfo("", "", "") })
+	pingDB = func() error { return nil }
+	SetBuildInfo("1.2.3", "", "")
+	t.Setenv("VERSION", "9.9.9")
+
+	r := Check()
+	require.Equal(t, "9.9.9", r.Version)
+	require.Equal(t, "unknown", r.Commit)
+}
+
+func TestCheck_DBPingFailure(t *testing.T) {
+	orig := pingDB
+	t.Cleanup(func() { pingDB = orig })
+	pingDB = func() error { return errors.New("disk I/O error") }
+
+	r := Check()
+	require.False(t, r.Healthy())
+	require.Equal(t, PingFail, r.DBPing)
+	require.Equal(t, StatusDegraded, r.Status)
+}
@@ -0,0 +1,93 @@
+package jsonapi
+
+import (
+	"task-management-api/internal/models"
+	"task-management-api/internal/response"
+)
+
+// MediaType is the JSON:API content type (https://jsonapi.org/format/)
+const MediaType = "application/vnd.api+json"
+
+// Resource type names used in the document
+const (
+	TypeTasks = "tasks"
+	TypeUsers = "users"
+)
+
+// resourceIdentifier returns a {"type","id"} linkage object; ids are mapped with response.ExternalID
+func resourceIdentifier(resourceType, id string) map[string]any {
+	return map[string]any{"type": resourceType, "id": response.ExternalID(id)}
+}
+
+// relationship wraps a linkage; an empty id yields a null to-one relationship
+func relationship(resourceType, id string) map[string]any {
+	if id == "" {
+		return map[string]any{"data": nil}
+	}
+	return map[string]any{"data": resourceIdentifier(resourceType, id)}
+}
+
+// Marshal formats tasks as a JSON:API document.
+// Each task links to its assignee (users) and parent story (tasks). Referenced users found in
+// users are side-loaded once each under "included".
+func Marshal(tasks []models.Task, users []models.User) map[string]any {
+	userByID := make(map[string]models.User, len(users))
+	for _, u := range users {
+		userByID[u.ID] = u
+	}
+
+	data := make([]map[string]any, 0, len(tasks))
+	included := make([]map[string]any, 0)
+	seenUsers := make(map[string]struct{})
+
+	for _, t := range tasks {
+		attributes := map[string]any{
+			"title":        t.Title,
+			"description":  t.Description,
+			"status":       t.Status,
+			"startDate":    t.StartDate,
+			"endDate":      t.EndDate,
+			"effort":       t.Effort,
+			"actualEffort": t.ActualEffort,
+			"priority":     t.Priority,
+			"taskType":     t.TaskType,
+			"createdAt":    t.CreatedAt,
+			"updatedAt":    t.UpdatedAt,
+		}
+		if t.AssigneeMissing {
+			attributes["assigneeMissing"] = true
+		}
+		data = append(data, map[string]any{
+			"type":       TypeTasks,
+			"id":         response.ExternalID(t.ID),
+			"attributes": attributes,
+			"relationships": map[string]any{
+				"assignee": relationship(TypeUsers, t.AssigneeID),
+				"parent":   relationship(TypeTasks, t.ProjectID),
+			},
+		})
+
+		// Side-load the assignee once, if we know about it
+		if t.AssigneeID == "" {
+			continue
+		}
+		if _, seen := seenUsers[t.AssigneeID]; seen {
+			continue
+		}
+		if u, ok := userByID[t.AssigneeID]; ok {
+			seenUsers[u.ID] = struct{}{}
+			included = append(included, map[string]any{
+				"type": TypeUsers,
+				"id":   response.ExternalID(u.ID),
+				"attributes": map[string]any{
+					"username": u.Username,
+				},
+			})
+		}
+	}
+
+	return map[string]any{
+		"data":     data,
+		"included": included,
+	}
+}
@@ -0,0 +1,67 @@
+package jsonapi
+
+import (
+	"testing"
+
+	"task-management-api/internal/models"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarshal_RelationshipsAndIncluded(t *testing.T) {
+	users := []models.User{
+		{ID: "u-1", Username: "alice"},
+		{ID: "u-2", Username: "bob"},
+		{ID: "u-3", Username: "carol"}, // not referenced
+	}
+	tasks := []models.Task{
+		{ID: "task-1", Title: "Story", TaskType: models.TypeStory, AssigneeID: "u-1"},
+		{ID: "task-2", Title: "Sub", TaskType: models.TypeSubtask, ProjectID: "task-1", AssigneeID: "u-1"},
+		{ID: "task-3", Title: "Defect", TaskType: models.TypeDefect, ProjectID: "task-1", AssigneeID: "u-2"},
+		{ID: "task-4", Title: "Unassigned", TaskType: models.TypeStory},
+	}
+
+	doc := Marshal(tasks, users)
+
+	data := doc["data"].([]map[string]any)
+	require.Len(t, data, 4)
+	require.Equal(t, TypeTasks, data[0]["type"])
+	require.Equal(t, "task-1", data[0]["id"])
+	require.Equal(t, "Story", data[0]["attributes"].(map[string]any)["title"])
+
+	rel := data[1]["relationships"].(map[string]any)
+	require.Equal(t, map[string]any{"type": TypeUsers, "id": "u-1"}, rel["assignee"].(map[string]any)["data"])
+	require.Equal(t, map[string]any{"type": TypeTasks, "id": "task-1"}, rel["parent"].(map[string]any)["data"])
+
+	// Stories have no parent; unassigned tasks have no assignee
+	require.Nil(t, data[0]["relationships"].(map[string]any)["parent"].(map[string]any)["data"])
+	require.Nil(t, data[3]["relationships"].(map[string]any)["assignee"].(map[string]any)["data"])
+
+	// Included holds each referenced user exactly once
+	included := doc["included"].([]map[string]any)
+	require.Len(t, included, 2)
+	seen := map[string]bool{}
+	for _, inc := range included {
+		require.Equal(t, TypeUsers, inc["type"])
+		id := inc["id"].(string)
+		require.False(t, seen[id], "duplicate included user %s", id)
+		seen[id] = true
+	}
+	require.True(t, seen["u-1"])
+	require.True(t, seen["u-2"])
+}
+
+func TestMarshal_StripIDPrefix(t *testing.T) {
+	t.Setenv("STRIP_ID_PREFIX", "true")
+	users := []models.User{{ID: "user-1", Username: "alice"}}
+	tasks := []models.Task{{ID: "task-2", Title: "Sub", TaskType: models.TypeSubtask, ProjectID: "task-1", AssigneeID: "user-1"}}
+
+	doc := Marshal(tasks, users)
+
+	data := doc["data"].([]map[string]any)
+	require.Equal(t, "2", data[0]["id"])
+	rel := data[0]["relationships"].(map[string]any)
+	require.Equal(t, map[string]any{"type": TypeUsers, "id": "1"}, rel["assignee"].(map[string]any)["data"])
+	require.Equal(t, map[string]any{"type": TypeTasks, "id": "1"}, rel["parent"].(map[string]any)["data"])
+	require.Equal(t, "1", doc["included"].([]map[string]any)[0]["id"])
+}
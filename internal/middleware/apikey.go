@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"errors"
+	"time"
+
+	"task-management-api/internal/auth"
+	"task-management-api/internal/database"
+	"task-management-api/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// errInvalidAPIKey covers malformed, unknown, expired and mismatched keys alike, so callers
+// cannot probe which part was wrong
+var errInvalidAPIKey = errors.New("invalid or expired API key")
+
+// authenticateAPIKey resolves a raw X-API-Key value to the (non-deactivated) user it belongs to
+func authenticateAPIKey(c *gin.Context, rawKey string) (models.User, error) {
+	var user models.User
+	id, secret, ok := auth.ParseAPIKey(rawKey)
+	if !ok {
+		return user, errInvalidAPIKey
+	}
+
+	db := database.GetDB().WithContext(c.Request.Context())
+	var key models.APIKey
+	if err := db.Where("id = ?", id).First(&key).Error; err != nil {
+		return user, errInvalidAPIKey
+	}
+	if key.Expired(time.Now()) || !auth.CheckAPIKeySecret(key.KeyHash, secret) {
+		return user, errInvalidAPIKey
+	}
+	if err := db.Where("id = ?", key.UserID).First(&user).Error; err != nil {
+		return user, errInvalidAPIKey
+	}
+	return user, nil
+}
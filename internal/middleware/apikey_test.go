@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"task-management-api/internal/auth"
+	"task-management-api/internal/database"
+	"task-management-api/internal/models"
+	"task-management-api/internal/testutil"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJWTAuthMiddleware_APIKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := testutil.NewInMemoryDB()
+	require.NoError(t, err)
+	database.DB = db
+	require.NoError(t, db.Create(&models.User{ID: "u-ci", Username: "ci-bot", Password: "x", Role: models.RoleMember}).Error)
+
+	id, rawKey, hash, err := auth.NewAPIKey()
+	require.NoError(t, err)
+	require.NoError(t, db.Create(&models.APIKey{ID: id, UserID: "u-ci", KeyHash: hash, Name: "ci"}).Error)
+
+	past := time.Now().Add(-time.Minute)
+	expiredID, expiredKey, expiredHash, err := auth.NewAPIKey()
+	require.NoError(t, err)
+	require.NoError(t, db.Create(&models.APIKey{ID: expiredID, UserID: "u-ci", KeyHash: expiredHash, Name: "old", ExpiresAt: &past}).Error)
+
+	r := gin.New()
+	r.Use(JWTAuthMiddleware())
+	r.GET("/api/whoami", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"userId": c.GetString("user_id"), "role": c.GetString("role")})
+	})
+	call := func(apiKey, bearer string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/whoami", nil)
+		if apiKey != "" {
+			req.Header.Set("X-API-Key", apiKey)
+		}
+		if bearer != "" {
+			req.Header.Set("Authorization", "Bearer "+bearer)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	// The key resolves to its user
+	w := call(rawKey, "")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.JSONEq(t, `{"userId":"u-ci","role":"member"}`, w.Body.String())
+
+	// Expired, tampered and malformed keys are rejected
+	require.Equal(t, http.StatusUnauthorized, call(expiredKey, "").Code)
+	require.Equal(t, http.StatusUnauthorized, call(rawKey+"0", "").Code)
+	require.Equal(t, http.StatusUnauthorized, call("not-a-key", "").Code)
+
+	// Bearer tokens keep working and take precedence when both are sent
+	token, err := auth.GenerateToken("u-1", "alice", models.RoleAdmin)
+	require.NoError(t, err)
+	w = call("", token)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.JSONEq(t, `{"userId":"u-1","role":"admin"}`, w.Body.String())
+	w = call(expiredKey, token)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.JSONEq(t, `{"userId":"u-1","role":"admin"}`, w.Body.String())
+
+	// Keys of deactivated users stop working
+	require.NoError(t, db.Delete(&models.User{ID: "u-ci"}).Error)
+	require.Equal(t, http.StatusUnauthorized, call(rawKey, "").Code)
+}
@@ -8,11 +8,29 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-// JWTAuthMiddleware validates JWT token in Authorization header
+// JWTAuthMiddleware validates JWT token in Authorization header.
+// Requests without one may authenticate with an API key in the X-API-Key header instead.
 func JWTAuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Get Authorization header
 		authHeader := c.GetHeader("Authorization")
+
+		// Integrations without a session send an API key; a Bearer token takes precedence
+		if apiKey := c.GetHeader("X-API-Key"); apiKey != "" && authHeader == "" {
+			user, err := authenticateAPIKey(c, apiKey)
+			if err != nil {
+				c.JSON(http.StatusUnauthorized, gin.H{
+					"error": "Invalid or expired API key",
+				})
+				c.Abort()
+				return
+			}
+			c.Set("user_id", user.ID)
+			c.Set("username", user.Username)
+			c.Set("role", user.Role)
+			c.Next()
+			return
+		}
 		tokenString := ""
 		if authHeader != "" {
 			// Extract token from "Bearer <token>"
@@ -43,9 +61,20 @@ func JWTAuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		// Reject tokens revoked since they were issued (logout or a password reset)
+		if auth.IsRevoked(tokenString, claims) {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "Token has been revoked",
+			})
+			c.Abort()
+			return
+		}
+
 		// Store user info in context for use in handlers
 		c.Set("user_id", claims.UserID)
 		c.Set("username", claims.Username)
+		c.Set("role", claims.Role)
+		c.Set("token", tokenString)
 
 		c.Next()
 	}
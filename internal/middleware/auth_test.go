@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"task-management-api/internal/auth"
+	"task-management-api/internal/models"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/require"
@@ -17,7 +18,7 @@ func TestJWTAuthMiddleware_Success(t *testing.T) {
 	r.Use(JWTAuthMiddleware())
 	r.GET("/protected", func(c *gin.Context) { c.Status(http.StatusOK) })
 
-	token, err := auth.GenerateToken("user-1", "alice")
+	token, err := auth.GenerateToken("user-1", "alice", models.RoleMember)
 	require.NoError(t, err)
 	req := httptest.NewRequest(http.MethodGet, "/protected", nil)
 	req.Header.Set("Authorization", "Bearer "+token)
@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// DefaultCORSMaxAge is how long (seconds) browsers may cache a preflight response by default
+	DefaultCORSMaxAge = 600
+	// MaxCORSMaxAge caps the preflight cache duration at one day
+	MaxCORSMaxAge = 86400
+)
+
+// CORSConfig configures the CORS middleware
+type CORSConfig struct {
+	// AllowedOrigin is echoed in Access-Control-Allow-Origin
+	AllowedOrigin string
+	// MaxAge is the Access-Control-Max-Age value (seconds) sent on preflight responses
+	MaxAge int
+}
+
+// CORSConfigFromEnv reads ALLOWED_ORIGIN and CORS_MAX_AGE_SECONDS.
+// CORS_MAX_AGE_SECONDS must be a non-negative integer; values above MaxCORSMaxAge are capped.
+func CORSConfigFromEnv() (CORSConfig, error) {
+	cfg := CORSConfig{
+		AllowedOrigin: os.Getenv("ALLOWED_ORIGIN"),
+		MaxAge:        DefaultCORSMaxAge,
+	}
+	if cfg.AllowedOrigin == "" {
+		// Development default; set ALLOWED_ORIGIN in production
+		cfg.AllowedOrigin = "http://localhost:3000"
+	}
+	if raw := strings.TrimSpace(os.Getenv("CORS_MAX_AGE_SECONDS")); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 {
+			return cfg, fmt.Errorf("CORS_MAX_AGE_SECONDS must be a non-negative integer, got %q", raw)
+		}
+		cfg.MaxAge = min(n, MaxCORSMaxAge)
+	}
+	return cfg, nil
+}
+
+// CORS sets the cross-origin headers for frontend integration and answers preflight requests
+func CORS(cfg CORSConfig) gin.HandlerFunc {
+	maxAge := strconv.Itoa(cfg.MaxAge)
+	return func(c *gin.Context) {
+		c.Writer.Header().Set("Access-Control-Allow-Origin", cfg.AllowedOrigin)
+		// Do not advertise credentials unless you use cookie-based auth
+		// c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With, X-Request-ID, X-Client-Source, X-API-Key")
+		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE, PATCH")
+
+		if c.Request.Method == "OPTIONS" {
+			// Let browsers cache the preflight instead of repeating it on every call
+			c.Writer.Header().Set("Access-Control-Max-Age", maxAge)
+			c.AbortWithStatus(204)
+			return
+		}
+
+		c.Next()
+	}
+}
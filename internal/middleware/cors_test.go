@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCORS_PreflightMaxAge(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	t.Setenv("CORS_MAX_AGE_SECONDS", "1200")
+	cfg, err := CORSConfigFromEnv()
+	require.NoError(t, err)
+
+	r := gin.New()
+	r.Use(CORS(cfg))
+	r.GET("/api/tasks", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodOptions, "/api/tasks", nil))
+	require.Equal(t, http.StatusNoContent, w.Code)
+	require.Equal(t, "1200", w.Header().Get("Access-Control-Max-Age"))
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/tasks", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Empty(t, w.Header().Get("Access-Control-Max-Age"))
+}
+
+func TestCORSConfigFromEnv_MaxAge(t *testing.T) {
+	cfg, err := CORSConfigFromEnv()
+	require.NoError(t, err)
+	require.Equal(t, DefaultCORSMaxAge, cfg.MaxAge)
+
+	t.Setenv("CORS_MAX_AGE_SECONDS", "999999")
+	cfg, err = CORSConfigFromEnv()
+	require.NoError(t, err)
+	require.Equal(t, MaxCORSMaxAge, cfg.MaxAge)
+
+	for _, bad := range []string{"-1", "ten"} {
+		t.Setenv("CORS_MAX_AGE_SECONDS", bad)
+		_, err = CORSConfigFromEnv()
+		require.Error(t, err)
+	}
+}
@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"task-management-api/internal/apperr"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ProblemContentType is the RFC 7807 media type for problem details
+const ProblemContentType = "application/problem+json"
+
+// ErrorHandler turns errors attached with c.Error into RFC 7807 problem details responses.
+// Register it before the handlers so it runs after them; handlers that already wrote a
+// response are left untouched.
+func ErrorHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+
+		err := c.Errors.Last().Err
+		status := http.StatusInternalServerError
+		detail := "An unexpected error occurred"
+		problem := gin.H{}
+
+		var (
+			notFound     apperr.NotFoundError
+			validation   apperr.ValidationError
+			conflict     apperr.ConflictError
+			unauthorized apperr.UnauthorizedError
+			forbidden    apperr.ForbiddenError
+		)
+		switch {
+		case errors.As(err, &notFound):
+			status, detail = notFound.StatusCode(), notFound.Error()
+		case errors.As(err, &validation):
+			status, detail = validation.StatusCode(), validation.Error()
+			problem["errors"] = validation.Fields
+		case errors.As(err, &conflict):
+			status, detail = conflict.StatusCode(), conflict.Error()
+		case errors.As(err, &unauthorized):
+			status, detail = unauthorized.StatusCode(), unauthorized.Error()
+		case errors.As(err, &forbidden):
+			status, detail = forbidden.StatusCode(), forbidden.Error()
+		default:
+			// Unknown errors are logged, never leaked to the client
+			log.Printf("unhandled error on %s %s: %v", c.Request.Method, c.Request.URL.Path, err)
+		}
+
+		problem["type"] = "about:blank"
+		problem["title"] = http.StatusText(status)
+		problem["status"] = status
+		problem["detail"] = detail
+		problem["instance"] = c.Request.URL.Path
+
+		c.Header("Content-Type", ProblemContentType)
+		c.JSON(status, problem)
+	}
+}
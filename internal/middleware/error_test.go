@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"task-management-api/internal/apperr"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorHandler_NotFoundProblem(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(ErrorHandler())
+	r.GET("/things/:id", func(c *gin.Context) {
+		_ = c.Error(apperr.NotFoundError{Resource: "Task", ID: c.Param("id")})
+	})
+	r.GET("/boom", func(c *gin.Context) {
+		_ = c.Error(errors.New("database exploded"))
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/things/task-1", nil))
+	require.Equal(t, http.StatusNotFound, w.Code)
+	require.Equal(t, ProblemContentType, w.Header().Get("Content-Type"))
+
+	var problem map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+	require.Equal(t, "Not Found", problem["title"])
+	require.Equal(t, float64(http.StatusNotFound), problem["status"])
+	require.Equal(t, "Task task-1 not found", problem["detail"])
+	require.Equal(t, "/things/task-1", problem["instance"])
+
+	// Unknown errors become a generic 500 without leaking details
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/boom", nil))
+	require.Equal(t, http.StatusInternalServerError, w.Code)
+	require.NotContains(t, w.Body.String(), "exploded")
+}
@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"task-management-api/internal/apperr"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrettyJSON_IndentsOnlyWhenRequested(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(PrettyJSON(), ErrorHandler())
+	r.GET("/tasks", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"tasks": []gin.H{{"id": "task-1"}}, "count": 1})
+	})
+	r.GET("/missing", func(c *gin.Context) {
+		_ = c.Error(apperr.NotFoundError{Resource: "task", ID: "x"})
+	})
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	w := get("/tasks")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NotContains(t, w.Body.String(), "\n")
+
+	w = get("/tasks?pretty=true")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Contains(t, w.Body.String(), "{\n  \"count\": 1,\n  \"tasks\": [\n    {\n      \"id\": \"task-1\"")
+
+	// Problem responses written by the error handler keep their status and are indented too
+	w = get("/missing?pretty=true")
+	require.Equal(t, http.StatusNotFound, w.Code)
+	require.Equal(t, ProblemContentType, w.Header().Get("Content-Type"))
+	require.Contains(t, w.Body.String(), "{\n  \"")
+}
@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"task-management-api/internal/cache"
+
+	"github.com/gin-gonic/gin"
+)
+
+// rateBucket holds the times of a client's requests within the current window
+type rateBucket struct {
+	hits []time.Time
+}
+
+// RateLimitMiddleware allows at most limit requests per client IP in any sliding window of the
+// given length; further requests get 429 with a Retry-After header until the oldest hit ages out.
+func RateLimitMiddleware(limit int, window time.Duration) gin.HandlerFunc {
+	buckets := cache.NewSimpleCache[string, *rateBucket](cache.Options{ConcurrencySafe: true})
+	cache.Register(buckets)
+	// Guards bucket contents and the read-modify-write of each request
+	var mu sync.Mutex
+	lastPurge := time.Now()
+
+	return func(c *gin.Context) {
+		now := time.Now()
+		ip := c.ClientIP()
+
+		mu.Lock()
+		// Idle clients' buckets expire after a window; sweep them out periodically
+		if now.Sub(lastPurge) > window {
+			buckets.PurgeExpired()
+			lastPurge = now
+		}
+		bucket, ok := buckets.Get(ip)
+		if !ok {
+			bucket = &rateBucket{}
+		}
+		// Drop hits that have slid out of the window
+		cutoff := now.Add(-window)
+		kept := bucket.hits[:0]
+		for _, t := range bucket.hits {
+			if t.After(cutoff) {
+				kept = append(kept, t)
+			}
+		}
+		bucket.hits = kept
+
+		if len(bucket.hits) >= limit {
+			retryAfter := bucket.hits[0].Add(window).Sub(now)
+			buckets.Set(ip, bucket, window)
+			mu.Unlock()
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			c.JSON(http.StatusTooManyRequests, gin.H{
+				"error": "Too many requests, please try again later",
+			})
+			c.Abort()
+			return
+		}
+		bucket.hits = append(bucket.hits, now)
+		buckets.Set(ip, bucket, window)
+		mu.Unlock()
+
+		c.Next()
+	}
+}
@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func newRateLimitedRouter(limit int, window time.Duration) func(ip string) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/api/login", RateLimitMiddleware(limit, window), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"message": "ok"})
+	})
+	return func(ip string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/login", nil)
+		req.RemoteAddr = ip + ":12345"
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+}
+
+func TestRateLimitMiddleware_RejectsOverLimit(t *testing.T) {
+	login := newRateLimitedRouter(3, time.Minute)
+
+	for i := 0; i < 3; i++ {
+		require.Equal(t, http.StatusOK, login("10.0.0.1").Code)
+	}
+	w := login("10.0.0.1")
+	require.Equal(t, http.StatusTooManyRequests, w.Code)
+	retryAfter, err := strconv.Atoi(w.Header().Get("Retry-After"))
+	require.NoError(t, err)
+	require.True(t, retryAfter > 0 && retryAfter <= 60)
+
+	// Other clients have their own budget
+	require.Equal(t, http.StatusOK, login("10.0.0.2").Code)
+}
+
+func TestRateLimitMiddleware_ResetsAfterWindow(t *testing.T) {
+	window := 200 * time.Millisecond
+	login := newRateLimitedRouter(2, window)
+
+	require.Equal(t, http.StatusOK, login("10.0.0.1").Code)
+	require.Equal(t, http.StatusOK, login("10.0.0.1").Code)
+	require.Equal(t, http.StatusTooManyRequests, login("10.0.0.1").Code)
+
+	time.Sleep(window + 50*time.Millisecond)
+	require.Equal(t, http.StatusOK, login("10.0.0.1").Code)
+}
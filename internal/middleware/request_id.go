@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"regexp"
+	"task-management-api/internal/requestid"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// validRequestID limits client-supplied IDs to short, log-safe tokens
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._-]{1,128}$`)
+
+// RequestID assigns each request a correlation ID, reusing the client's X-Request-ID when it is
+// a valid token (see validRequestID) and generating a fresh one otherwise.
+// The ID is echoed in the response header, stored in the gin context as "request_id",
+// and attached to the request context so database queries can be logged with it.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(requestid.Header)
+		if !validRequestID.MatchString(id) {
+			id = uuid.NewString()
+		}
+		c.Set("request_id", id)
+		c.Writer.Header().Set(requestid.Header, id)
+		c.Request = c.Request.WithContext(requestid.NewContext(c.Request.Context(), id))
+		c.Next()
+	}
+}
@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"task-management-api/internal/database"
+	"task-management-api/internal/models"
+	"task-management-api/internal/requestid"
+
+	"github.com/gin-gonic/gin"
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestRequestID_TagsGORMLogs(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var logs bytes.Buffer
+	base := logger.New(log.New(&logs, "", 0), logger.Config{LogLevel: logger.Info})
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: database.NewRequestLogger(base)})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.User{}))
+
+	r := gin.New()
+	r.Use(RequestID())
+	r.GET("/users", func(c *gin.Context) {
+		var users []models.User
+		_ = db.WithContext(c.Request.Context()).Find(&users).Error
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/users", nil)
+	req.Header.Set(requestid.Header, "req-123")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "req-123", w.Header().Get(requestid.Header))
+	require.Contains(t, logs.String(), "[request_id=req-123] SELECT * FROM `users`")
+}
+
+func TestRequestID_GeneratesWhenMissing(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(RequestID())
+	r.GET("/", func(c *gin.Context) { c.String(http.StatusOK, requestid.FromContext(c.Request.Context())) })
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	require.NotEmpty(t, w.Header().Get(requestid.Header))
+	require.Equal(t, w.Header().Get(requestid.Header), w.Body.String())
+}
+
+func TestRequestID_ReplacesInvalidClientID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(RequestID())
+	r.GET("/", func(c *gin.Context) { c.String(http.StatusOK, requestid.FromContext(c.Request.Context())) })
+
+	for _, id := range []string{"abc def", "line\rbreak", "<script>", strings.Repeat("a", 129)} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(requestid.Header, id)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		require.NotEqual(t, id, w.Header().Get(requestid.Header))
+		require.Len(t, w.Header().Get(requestid.Header), 36) // a generated UUID
+	}
+}
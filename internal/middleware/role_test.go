@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"task-management-api/internal/auth"
+	"task-management-api/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoleMiddleware_AdminOnlyRoute(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/api/admin/ping", JWTAuthMiddleware(), RoleMiddleware(models.RoleAdmin), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"role": c.GetString("role")})
+	})
+	call := func(role string) *httptest.ResponseRecorder {
+		token, err := auth.GenerateToken("u-1", "alice", role)
+		require.NoError(t, err)
+		req := httptest.NewRequest(http.MethodGet, "/api/admin/ping", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	require.Equal(t, http.StatusForbidden, call(models.RoleMember).Code)
+	// Tokens without a role claim are not admins either
+	require.Equal(t, http.StatusForbidden, call("").Code)
+
+	w := call(models.RoleAdmin)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.JSONEq(t, `{"role":"admin"}`, w.Body.String())
+}
+
+func TestRoleMiddleware_AnyOfSeveralRoles(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/api/ok", func(c *gin.Context) { c.Set("role", models.RoleMember) },
+		RoleMiddleware(models.RoleAdmin, models.RoleMember),
+		func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/ok", nil))
+	require.Equal(t, http.StatusNoContent, w.Code)
+}
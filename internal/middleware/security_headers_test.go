@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecurityHeaders(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	newRouter := func(enabled bool) *gin.Engine {
+		r := gin.New()
+		r.Use(SecurityHeaders(enabled))
+		r.GET("/api/tasks", func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"tasks": []string{}}) })
+		r.GET("/api/ws", func(c *gin.Context) { c.Status(http.StatusSwitchingProtocols) })
+		return r
+	}
+
+	w := httptest.NewRecorder()
+	newRouter(true).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/tasks", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, HSTSValue, w.Header().Get("Strict-Transport-Security"))
+	require.Equal(t, "nosniff", w.Header().Get("X-Content-Type-Options"))
+	require.Equal(t, "DENY", w.Header().Get("X-Frame-Options"))
+
+	// WebSocket upgrades are left alone
+	req := httptest.NewRequest(http.MethodGet, "/api/ws", nil)
+	req.Header.Set("Upgrade", "websocket")
+	w = httptest.NewRecorder()
+	newRouter(true).ServeHTTP(w, req)
+	require.Empty(t, w.Header().Get("Strict-Transport-Security"))
+
+	// Disabled for local development
+	w = httptest.NewRecorder()
+	newRouter(false).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/tasks", nil))
+	require.Empty(t, w.Header().Get("X-Frame-Options"))
+}
+
+func TestSecurityHeadersEnabled_Env(t *testing.T) {
+	t.Setenv("SECURITY_HEADERS", "")
+	require.True(t, SecurityHeadersEnabled())
+	t.Setenv("SECURITY_HEADERS", "false")
+	require.False(t, SecurityHeadersEnabled())
+}
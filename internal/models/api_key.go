@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// APIKey is a long-lived credential for integrations (CI pipelines, scripts) that act as a user
+// without a session. Only the bcrypt hash of the key's secret part is stored.
+type APIKey struct {
+	ID        string     `json:"id" gorm:"primaryKey"`
+	UserID    string     `json:"-" gorm:"column:user_id;not null;index"`
+	KeyHash   string     `json:"-" gorm:"column:key_hash;not null"`
+	Name      string     `json:"name" gorm:"not null"`
+	CreatedAt time.Time  `json:"createdAt"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty" gorm:"column:expires_at"` // nil never expires
+}
+
+// TableName specifies the table name for APIKey Model
+func (APIKey) TableName() string {
+	return "api_keys"
+}
+
+// Expired reports whether the key has an expiry at or before now
+func (k APIKey) Expired(now time.Time) bool {
+	return k.ExpiresAt != nil && !k.ExpiresAt.After(now)
+}
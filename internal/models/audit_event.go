@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// AuditEventType is the kind of authentication event recorded in the auth audit log
+type AuditEventType string
+
+const (
+	EventLoginSuccess   AuditEventType = "login_success"
+	EventLoginFailure   AuditEventType = "login_failure"
+	EventLogout         AuditEventType = "logout"
+	EventPasswordChange AuditEventType = "password_change"
+)
+
+// IsValid reports whether t is one of the known auth event types
+func (t AuditEventType) IsValid() bool {
+	switch t {
+	case EventLoginSuccess, EventLoginFailure, EventLogout, EventPasswordChange:
+		return true
+	}
+	return false
+}
+
+// AuditEvent is an append-only record of an authentication event: who logged in or out,
+// from where, and when passwords changed. UserID is empty for failed logins of unknown usernames.
+type AuditEvent struct {
+	ID        uint           `json:"id" gorm:"primaryKey"`
+	UserID    string         `json:"userId" gorm:"column:user_id;index:idx_audit_events_user_time"`
+	EventType AuditEventType `json:"eventType" gorm:"column:event_type;not null;index"`
+	IPAddress string         `json:"ipAddress" gorm:"column:ip_address"`
+	UserAgent string         `json:"userAgent" gorm:"column:user_agent"`
+	CreatedAt time.Time      `json:"createdAt" gorm:"index:idx_audit_events_user_time"`
+}
+
+// TableName specifies the table name for AuditEvent Model
+func (AuditEvent) TableName() string {
+	return "audit_events"
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+// Board represents a user's kanban board configuration
+type Board struct {
+	ID      string        `json:"id" gorm:"primaryKey"`
+	Name    string        `json:"name" gorm:"not null"`
+	UserID  string        `json:"-" gorm:"column:user_id;index"`
+	Columns []BoardColumn `json:"columns,omitempty" gorm:"foreignKey:BoardID;references:ID"`
+	gorm.Model
+}
+
+// TableName specifies the table name for Board Model
+func (Board) TableName() string {
+	return "boards"
+}
+
+// BoardColumn is a status lane on a board, ordered by Position
+type BoardColumn struct {
+	ID       string     `json:"id" gorm:"primaryKey"`
+	BoardID  string     `json:"boardId" gorm:"column:board_id;index;not null"`
+	Status   TaskStatus `json:"status" gorm:"not null"`
+	Position int        `json:"position" gorm:"not null;default:0"`
+	gorm.Model
+}
+
+// TableName specifies the table name for BoardColumn Model
+func (BoardColumn) TableName() string {
+	return "board_columns"
+}
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// Comment is one message in a task's discussion thread, written by UserID
+type Comment struct {
+	ID        string    `json:"id" gorm:"primaryKey"`
+	TaskID    string    `json:"taskId" gorm:"column:task_id;not null;index:idx_comments_task_time"`
+	UserID    string    `json:"authorId" gorm:"column:user_id;not null;index"`
+	Body      string    `json:"body" gorm:"not null"`
+	CreatedAt time.Time `json:"createdAt" gorm:"index:idx_comments_task_time"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// TableName specifies the table name for Comment Model
+func (Comment) TableName() string {
+	return "comments"
+}
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// LoginAttempt records one password check for a username, used to lock accounts after
+// repeated failures. Attempts for unknown usernames are not recorded.
+type LoginAttempt struct {
+	ID          uint      `json:"-" gorm:"primaryKey"`
+	Username    string    `json:"username" gorm:"not null;index:idx_login_attempts_user_time"`
+	IPAddress   string    `json:"ipAddress" gorm:"column:ip_address"`
+	AttemptedAt time.Time `json:"attemptedAt" gorm:"column:attempted_at;not null;index:idx_login_attempts_user_time"`
+	Succeeded   bool      `json:"succeeded" gorm:"not null;default:false"`
+}
+
+// TableName specifies the table name for LoginAttempt Model
+func (LoginAttempt) TableName() string {
+	return "login_attempts"
+}
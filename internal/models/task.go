@@ -1,6 +1,14 @@
 package models
 
 import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"task-management-api/internal/apperr"
+	"time"
+
 	"gorm.io/gorm"
 )
 
@@ -13,6 +21,36 @@ const (
 	StatusDone       TaskStatus = "done"
 )
 
+// IsValid reports whether s is one of the known statuses
+func (s TaskStatus) IsValid() bool {
+	switch s {
+	case StatusTodo, StatusInProgress, StatusDone:
+		return true
+	}
+	return false
+}
+
+// AllowedTransitions lists the statuses a task may move to from each status: forward one step
+// at a time (todo → inProgress → done) or back one step, never skipping a state.
+var AllowedTransitions = map[TaskStatus][]TaskStatus{
+	StatusTodo:       {StatusInProgress},
+	StatusInProgress: {StatusTodo, StatusDone},
+	StatusDone:       {StatusInProgress},
+}
+
+// CanTransition reports whether a task may move from one status to another.
+// Keeping the same status is always allowed; tasks with an unknown stored status may move to any valid one.
+func CanTransition(from, to TaskStatus) bool {
+	if from == to {
+		return true
+	}
+	next, ok := AllowedTransitions[from]
+	if !ok {
+		return to.IsValid()
+	}
+	return slices.Contains(next, to)
+}
+
 // Task Priority represents the priority of a task
 type TaskPriority string
 
@@ -22,6 +60,28 @@ const (
 	PriorityLow    TaskPriority = "low"
 )
 
+// IsValid reports whether p is one of the known priorities
+func (p TaskPriority) IsValid() bool {
+	switch p {
+	case PriorityHigh, PriorityMedium, PriorityLow:
+		return true
+	}
+	return false
+}
+
+// PriorityWeight returns the workload weight of a priority (high=3, medium=2, low=1).
+// Unknown priorities are weighted like the default (medium).
+func PriorityWeight(p TaskPriority) float64 {
+	switch p {
+	case PriorityHigh:
+		return 3.0
+	case PriorityLow:
+		return 1.0
+	default:
+		return 2.0
+	}
+}
+
 // TaskType represents the type of a task (story, defect, subtask)
 type TaskType string
 
@@ -31,6 +91,84 @@ const (
 	TypeSubtask TaskType = "subtask"
 )
 
+// IsValid reports whether t is one of the known task types
+func (t TaskType) IsValid() bool {
+	switch t {
+	case TypeStory, TypeDefect, TypeSubtask:
+		return true
+	}
+	return false
+}
+
+// TaskSource identifies the client that created a task (web, mobile, import)
+type TaskSource string
+
+const (
+	SourceWeb    TaskSource = "web"
+	SourceMobile TaskSource = "mobile"
+	SourceImport TaskSource = "import"
+)
+
+// IsValid reports whether s is one of the known creation sources
+func (s TaskSource) IsValid() bool {
+	switch s {
+	case SourceWeb, SourceMobile, SourceImport:
+		return true
+	}
+	return false
+}
+
+// Labels are free-form task tags such as "backend" or "urgent". They are stored as a JSON array
+// in the labels text column; rows written before labels existed hold NULL and read as no labels.
+type Labels []string
+
+// GormDataType stores labels in a text column
+func (Labels) GormDataType() string {
+	return "text"
+}
+
+// Value implements driver.Valuer, encoding the labels as a JSON array ("[]" when empty)
+func (l Labels) Value() (driver.Value, error) {
+	b, err := json.Marshal(l.orEmpty())
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// Scan implements sql.Scanner for the JSON text written by Value
+func (l *Labels) Scan(src any) error {
+	var data []byte
+	switch v := src.(type) {
+	case nil:
+		*l = Labels{}
+		return nil
+	case string:
+		data = []byte(v)
+	case []byte:
+		data = v
+	default:
+		return fmt.Errorf("labels: unsupported column type %T", src)
+	}
+	if len(data) == 0 {
+		*l = Labels{}
+		return nil
+	}
+	return json.Unmarshal(data, (*[]string)(l))
+}
+
+// MarshalJSON always renders an array, never null
+func (l Labels) MarshalJSON() ([]byte, error) {
+	return json.Marshal(l.orEmpty())
+}
+
+func (l Labels) orEmpty() []string {
+	if l == nil {
+		return []string{}
+	}
+	return l
+}
+
 // Assignee represents a task assignee
 type Assignee struct {
 	ID   string `json:"id"`
@@ -39,19 +177,29 @@ type Assignee struct {
 
 // Task represents a task in the system
 type Task struct {
-	ID          string       `json:"id" gorm:"primaryKey"`
-	Title       string       `json:"title" gorm:"not null"`
-	Description string       `json:"description"`
-	Status      TaskStatus   `json:"status" gorm:"not null;default:'todo'"`
-	ProjectID   string       `json:"projectId" gorm:"column:project_id"`
-	AssigneeID  string       `json:"-" gorm:"column:assignee_id"`
-	Assignee    Assignee     `json:"assignee" gorm:"-"`
-	StartDate   string       `json:"startDate" gorm:"column:start_date"`
-	EndDate     string       `json:"endDate" gorm:"column:end_date"`
-	Effort      int          `json:"effort" gorm:"default:1"`
-	Priority    TaskPriority `json:"priority" gorm:"default:'medium'"`
-	TaskType    TaskType     `json:"taskType" gorm:"column:task_type;default:'story'"`
-	UserID      string       `json:"-" gorm:"column:user_id;index"`
+	ID               string       `json:"id" gorm:"primaryKey"`
+	Title            string       `json:"title" gorm:"not null"`
+	Description      string       `json:"description"`
+	Status           TaskStatus   `json:"status" gorm:"not null;default:'todo'"`
+	ProjectID        string       `json:"projectId" gorm:"column:project_id"`
+	AssigneeID       string       `json:"-" gorm:"column:assignee_id"`
+	Assignee         Assignee     `json:"assignee" gorm:"-"`
+	AssigneeMissing  bool         `json:"assigneeMissing,omitempty" gorm:"-"` // assignee ID set but no such user (e.g. deleted)
+	StartDate        string       `json:"startDate" gorm:"column:start_date"`
+	EndDate          string       `json:"endDate" gorm:"column:end_date"`
+	Effort           int          `json:"effort" gorm:"default:1"`
+	ActualEffort     int          `json:"actualEffort" gorm:"column:actual_effort;default:0"`
+	ForecastedEffort int          `json:"forecastedEffort,omitempty" gorm:"-"`
+	Priority         TaskPriority `json:"priority" gorm:"default:'medium'"`
+	TaskType         TaskType     `json:"taskType" gorm:"column:task_type;default:'story'"`
+	Position         int          `json:"position" gorm:"default:0"`
+	Source           TaskSource   `json:"source,omitempty" gorm:"column:source;index"` // creating client; empty for tasks predating source tagging
+	Labels           Labels       `json:"labels" gorm:"column:labels"`
+	UserID           string       `json:"-" gorm:"column:user_id;index"`
+	AlertSent        bool         `json:"-" gorm:"column:alert_sent;default:false"`
+	PurgeAfter       *time.Time   `json:"-" gorm:"column:purge_after;index"`
+	ArchivedAt       *time.Time   `json:"archivedAt,omitempty" gorm:"column:archived_at;index"` // archived tasks are hidden from task lists
+	PriorityScore    float64      `json:"priorityScore,omitempty" gorm:"-"`                     // computed by the task read endpoints, see Task.Score
 	gorm.Model
 }
 
@@ -59,3 +207,111 @@ type Task struct {
 func (Task) TableName() string {
 	return "tasks"
 }
+
+// NotArchived is a query scope that leaves out archived tasks; every task list and board,
+// as well as background jobs, should apply it
+func NotArchived(db *gorm.DB) *gorm.DB {
+	return db.Where("archived_at IS NULL")
+}
+
+// BeforeSave enforces the story/child invariant on every write that goes through the model:
+// stories have no projectId; subtasks and defects must reference one.
+// Bulk updates without a loaded task (empty TaskType) are not checked.
+func (t *Task) BeforeSave(tx *gorm.DB) error {
+	switch t.TaskType {
+	case TypeStory:
+		if t.ProjectID != "" {
+			return apperr.ValidationError{Fields: map[string]string{"projectId": "must be empty for stories"}}
+		}
+	case TypeSubtask, TypeDefect:
+		if t.ProjectID == "" {
+			return apperr.ValidationError{Fields: map[string]string{"projectId": fmt.Sprintf("is required for %s tasks", t.TaskType)}}
+		}
+	}
+	return nil
+}
+
+// taskDateLayouts are the accepted formats for startDate/endDate
+var taskDateLayouts = []string{
+	"2006-01-02",  // ISO date
+	"2 Jan 2006",  // e.g., 30 Oct 2025
+	time.RFC3339,  // full RFC3339
+	"02 Jan 2006", // zero-padded day
+}
+
+// ParseTaskDate parses a task date in any of the accepted formats
+func ParseTaskDate(dateStr string) (time.Time, bool) {
+	if dateStr == "" {
+		return time.Time{}, false
+	}
+	for _, layout := range taskDateLayouts {
+		if t, err := time.Parse(layout, dateStr); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// Deadline returns when the task is due. Date-only end dates are due at the end of that day.
+// The second return value is false when the end date is missing or unparseable.
+func (t Task) Deadline() (time.Time, bool) {
+	end, ok := ParseTaskDate(t.EndDate)
+	if !ok {
+		return time.Time{}, false
+	}
+	if end.Hour() == 0 && end.Minute() == 0 && end.Second() == 0 && end.Nanosecond() == 0 {
+		return end.AddDate(0, 0, 1), true
+	}
+	return end, true
+}
+
+// IsOverdue reports whether the task is still open after its deadline at now.
+// Tasks without a parseable end date are never overdue.
+func (t Task) IsOverdue(now time.Time) bool {
+	deadline, ok := t.Deadline()
+	return ok && t.Status != StatusDone && now.After(deadline)
+}
+
+// ScoreWeights tunes Task.Score: Priority maps each priority to its base weight, Age is the
+// fraction of that base added per day since creation, Overdue is added per day past the
+// deadline, and Effort is subtracted per effort day so quick wins rank higher.
+type ScoreWeights struct {
+	Priority map[TaskPriority]float64
+	Age      float64
+	Overdue  float64
+	Effort   float64
+}
+
+// DefaultScoreWeights are the weights used when none are configured
+func DefaultScoreWeights() ScoreWeights {
+	return ScoreWeights{
+		Priority: map[TaskPriority]float64{
+			PriorityHigh:   PriorityWeight(PriorityHigh),
+			PriorityMedium: PriorityWeight(PriorityMedium),
+			PriorityLow:    PriorityWeight(PriorityLow),
+		},
+		Age:     0.05,
+		Overdue: 1,
+		Effort:  0.1,
+	}
+}
+
+// Score computes the task's priority score at now:
+// priority weight × (1 + Age × days open) + Overdue × days overdue − Effort × effort.
+// Done tasks are never overdue. The result is rounded to two decimals.
+func (t Task) Score(w ScoreWeights, now time.Time) float64 {
+	base, ok := w.Priority[t.Priority]
+	if !ok {
+		base = w.Priority[PriorityMedium]
+	}
+	score := base
+	if !t.CreatedAt.IsZero() && now.After(t.CreatedAt) {
+		score *= 1 + w.Age*now.Sub(t.CreatedAt).Hours()/24
+	}
+	if t.IsOverdue(now) {
+		deadline, _ := t.Deadline()
+		score += w.Overdue * now.Sub(deadline).Hours() / 24
+	}
+	score -= w.Effort * float64(t.Effort)
+	return math.Round(score*100) / 100
+}
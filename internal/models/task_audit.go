@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// AuditAction is the kind of change recorded in the task audit trail
+type AuditAction string
+
+const (
+	AuditCreated       AuditAction = "created"
+	AuditUpdated       AuditAction = "updated"
+	AuditStatusChanged AuditAction = "status_changed"
+	AuditDeleted       AuditAction = "deleted"
+	AuditArchived      AuditAction = "archived"
+	AuditUnarchived    AuditAction = "unarchived"
+	AuditRestored      AuditAction = "restored"
+	AuditMerged        AuditAction = "merged"
+)
+
+// TaskAudit is an append-only record of a change made to a task by a user.
+// FromStatus/ToStatus are set for status changes.
+type TaskAudit struct {
+	ID         uint        `json:"-" gorm:"primaryKey"`
+	TaskID     string      `json:"taskId" gorm:"column:task_id;index;not null"`
+	UserID     string      `json:"userId" gorm:"column:user_id;index:idx_task_audits_user_time;not null"`
+	Action     AuditAction `json:"action" gorm:"not null"`
+	FromStatus TaskStatus  `json:"fromStatus,omitempty" gorm:"column:from_status"`
+	ToStatus   TaskStatus  `json:"toStatus,omitempty" gorm:"column:to_status"`
+	CreatedAt  time.Time   `json:"createdAt" gorm:"index:idx_task_audits_user_time"`
+}
+
+// TableName specifies the table name for TaskAudit Model
+func (TaskAudit) TableName() string {
+	return "task_audits"
+}
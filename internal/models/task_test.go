@@ -0,0 +1,108 @@
+package models_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"task-management-api/internal/apperr"
+	"task-management-api/internal/models"
+	"task-management-api/internal/testutil"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaskBeforeSave_EnforcesProjectInvariant(t *testing.T) {
+	db, err := testutil.NewInMemoryDB()
+	require.NoError(t, err)
+
+	require.NoError(t, db.Create(&models.Task{ID: "task-story", Title: "Story", TaskType: models.TypeStory}).Error)
+	require.NoError(t, db.Create(&models.Task{ID: "task-sub", Title: "Sub", TaskType: models.TypeSubtask, ProjectID: "task-story"}).Error)
+
+	var verr apperr.ValidationError
+
+	// Story with a parent
+	err = db.Create(&models.Task{ID: "task-bad-story", Title: "Bad", TaskType: models.TypeStory, ProjectID: "task-story"}).Error
+	require.ErrorAs(t, err, &verr)
+	require.Contains(t, verr.Fields, "projectId")
+
+	// Defect without a parent
+	err = db.Create(&models.Task{ID: "task-bad-defect", Title: "Bad", TaskType: models.TypeDefect}).Error
+	require.ErrorAs(t, err, &verr)
+
+	// Narrow updates through the model are checked too
+	var sub models.Task
+	require.NoError(t, db.First(&sub, "id = ?", "task-sub").Error)
+	sub.ProjectID = ""
+	require.ErrorAs(t, db.Save(&sub).Error, &verr)
+
+	var count int64
+	require.NoError(t, db.Model(&models.Task{}).Count(&count).Error)
+	require.Equal(t, int64(2), count)
+	var stored models.Task
+	require.NoError(t, db.First(&stored, "id = ?", "task-sub").Error)
+	require.Equal(t, "task-story", stored.ProjectID)
+}
+
+func TestCanTransition(t *testing.T) {
+	cases := []struct {
+		from, to models.TaskStatus
+		ok       bool
+	}{
+		{models.StatusTodo, models.StatusInProgress, true},
+		{models.StatusInProgress, models.StatusDone, true},
+		{models.StatusDone, models.StatusInProgress, true},
+		{models.StatusInProgress, models.StatusTodo, true},
+		{models.StatusTodo, models.StatusTodo, true},
+		{models.StatusTodo, models.StatusDone, false},
+		{models.StatusDone, models.StatusTodo, false},
+		{models.StatusTodo, "archived", false},
+		// Legacy rows with an unknown status can be brought back into the workflow
+		{"", models.StatusDone, true},
+	}
+	for _, tc := range cases {
+		require.Equal(t, tc.ok, models.CanTransition(tc.from, tc.to), "%s -> %s", tc.from, tc.to)
+	}
+}
+
+func TestTaskIsOverdue(t *testing.T) {
+	now := time.Date(2025, 6, 10, 9, 0, 0, 0, time.UTC)
+	cases := []struct {
+		endDate string
+		status  models.TaskStatus
+		want    bool
+	}{
+		{"2025-06-09", models.StatusInProgress, true},
+		{"9 Jun 2025", models.StatusTodo, true},
+		{"2025-06-09", models.StatusDone, false},
+		{"2025-06-10", models.StatusInProgress, false}, // due by the end of today
+		{"2025-06-10T08:00:00Z", models.StatusInProgress, true},
+		{"not a date", models.StatusInProgress, false},
+		{"", models.StatusInProgress, false},
+	}
+	for _, tc := range cases {
+		task := models.Task{EndDate: tc.endDate, Status: tc.status}
+		require.Equal(t, tc.want, task.IsOverdue(now), "%q %s", tc.endDate, tc.status)
+	}
+}
+
+func TestLabels_RoundTripAndNull(t *testing.T) {
+	db, err := testutil.NewInMemoryDB()
+	require.NoError(t, err)
+
+	require.NoError(t, db.Create(&models.Task{ID: "task-1", Title: "T", TaskType: models.TypeStory,
+		Labels: models.Labels{"backend", "urgent"}}).Error)
+	require.NoError(t, db.Create(&models.Task{ID: "task-2", Title: "T", TaskType: models.TypeStory}).Error)
+	// Rows from before labels existed hold NULL
+	require.NoError(t, db.Exec("UPDATE tasks SET labels = NULL WHERE id = ?", "task-2").Error)
+
+	var labeled, legacy models.Task
+	require.NoError(t, db.First(&labeled, "id = ?", "task-1").Error)
+	require.Equal(t, models.Labels{"backend", "urgent"}, labeled.Labels)
+	require.NoError(t, db.First(&legacy, "id = ?", "task-2").Error)
+	require.Empty(t, legacy.Labels)
+
+	b, err := json.Marshal(legacy)
+	require.NoError(t, err)
+	require.Contains(t, string(b), `"labels":[]`)
+}
@@ -4,11 +4,19 @@ import (
 	"gorm.io/gorm"
 )
 
+// User roles
+const (
+	RoleAdmin  = "admin"
+	RoleMember = "member"
+)
+
 // User represents a user in the system
 type User struct {
 	ID       string `json:"id" gorm:"primaryKey"`
 	Username string `json:"username" gorm:"unique;not null"`
 	Password string `json:"-" gorm:"not null"`
+	Email    string `json:"email"`
+	Role     string `json:"role" gorm:"not null;default:'member'"`
 	gorm.Model
 }
 
@@ -0,0 +1,19 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+// UserSetting is a per-user preference stored server-side; Value holds raw JSON
+type UserSetting struct {
+	ID     string `json:"-" gorm:"primaryKey"`
+	UserID string `json:"-" gorm:"column:user_id;not null;uniqueIndex:idx_user_settings_user_key"`
+	Key    string `json:"key" gorm:"column:key;not null;uniqueIndex:idx_user_settings_user_key"`
+	Value  string `json:"value" gorm:"column:value;type:text;not null"`
+	gorm.Model
+}
+
+// TableName specifies the table name for UserSetting Model
+func (UserSetting) TableName() string {
+	return "user_settings"
+}
@@ -1,7 +1,11 @@
 package realtime
 
 import (
+	"os"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 )
 
 // Client represents a single websocket client connection.
@@ -9,23 +13,61 @@ import (
 type Client interface {
 	Send(message []byte) bool
 	Close()
+	// IsAlive reports whether the client has shown signs of life (e.g. a pong) within timeout.
+	IsAlive(timeout time.Duration) bool
+}
+
+// PausedBufferCap is the maximum number of broadcasts held while the hub is paused;
+// further broadcasts are dropped.
+const PausedBufferCap = 1000
+
+// DefaultSendRetries is how many times a failed send is retried before the client is dropped
+const DefaultSendRetries = 1
+
+// SendRetries returns how many times a failed websocket send is retried.
+// Configured via WS_SEND_RETRIES (a non-negative integer); defaults to 1.
+func SendRetries() int {
+	if n, err := strconv.Atoi(strings.TrimSpace(os.Getenv("WS_SEND_RETRIES"))); err == nil && n >= 0 {
+		return n
+	}
+	return DefaultSendRetries
 }
 
 // Hub maintains active user connections and broadcasts events to them.
 type Hub struct {
 	mu              sync.RWMutex
 	userIdToClients map[string]map[Client]struct{}
+	// sendRetries is how many extra attempts a failed send gets before the client is dropped
+	sendRetries int
+
+	// pauseMu guards the maintenance pause state below
+	pauseMu  sync.Mutex
+	paused   bool
+	buffered []bufferedMessage
+	dropped  int
+}
+
+// bufferedMessage is a broadcast held back while the hub is paused
+type bufferedMessage struct {
+	userID  string
+	message []byte
 }
 
 var hubInstance *Hub
 var once sync.Once
 
+// newHub creates an empty hub.
+func newHub() *Hub {
+	return &Hub{
+		userIdToClients: make(map[string]map[Client]struct{}),
+		sendRetries:     SendRetries(),
+	}
+}
+
 // GetHub returns a singleton hub instance.
 func GetHub() *Hub {
 	once.Do(func() {
-		hubInstance = &Hub{
-			userIdToClients: make(map[string]map[Client]struct{}),
-		}
+		hubInstance = newHub()
 	})
 	return hubInstance
 }
@@ -53,13 +95,129 @@ func (h *Hub) Unregister(userID string, client Client) {
 }
 
 // Broadcast sends a message to all clients of a user.
+// While the hub is paused the message is buffered (or dropped once the buffer is full) instead.
 func (h *Hub) Broadcast(userID string, message []byte) {
+	if h.holdIfPaused(userID, message) {
+		return
+	}
+	h.deliver(userID, message)
+}
+
+// deliver writes a message to every client of a user.
+// A failed send is retried up to sendRetries times (each attempt resets the write deadline);
+// clients that still fail are considered dead and are unregistered and closed.
+func (h *Hub) deliver(userID string, message []byte) {
+	var dead []Client
+	h.mu.RLock()
+	for c := range h.userIdToClients[userID] {
+		if !sendWithRetry(c, message, h.sendRetries) {
+			dead = append(dead, c)
+		}
+	}
+	h.mu.RUnlock()
+
+	// Unregister takes the write lock
+	for _, c := range dead {
+		h.Unregister(userID, c)
+		c.Close()
+	}
+}
+
+// sendWithRetry sends message, retrying up to retries more times, and reports whether it got through.
+func sendWithRetry(c Client, message []byte, retries int) bool {
+	for attempt := 0; attempt <= retries; attempt++ {
+		if c.Send(message) {
+			return true
+		}
+	}
+	return false
+}
+
+// TotalConnections returns the number of registered clients across all users.
+func (h *Hub) TotalConnections() int {
 	h.mu.RLock()
 	defer h.mu.RUnlock()
-	clients := h.userIdToClients[userID]
-	for c := range clients {
-		if ok := c.Send(message); !ok {
-			// client write failed; let the handler clean it up on its side
+	total := 0
+	for _, clients := range h.userIdToClients {
+		total += len(clients)
+	}
+	return total
+}
+
+// PruneStaleConnections unregisters and closes clients that have not been alive within timeout.
+// This catches connections that dropped without a proper close (e.g. TCP reset without FIN).
+// It returns the number of pruned clients.
+func (h *Hub) PruneStaleConnections(timeout time.Duration) int {
+	type staleClient struct {
+		userID string
+		client Client
+	}
+
+	// Collect under the read lock; Unregister takes the write lock
+	var stale []staleClient
+	h.mu.RLock()
+	for userID, clients := range h.userIdToClients {
+		for c := range clients {
+			if !c.IsAlive(timeout) {
+				stale = append(stale, staleClient{userID: userID, client: c})
+			}
 		}
 	}
+	h.mu.RUnlock()
+
+	for _, s := range stale {
+		h.Unregister(s.userID, s.client)
+		s.client.Close()
+	}
+	return len(stale)
+}
+
+// holdIfPaused buffers the message when the hub is paused and reports whether it did so.
+func (h *Hub) holdIfPaused(userID string, message []byte) bool {
+	h.pauseMu.Lock()
+	defer h.pauseMu.Unlock()
+	if !h.paused {
+		return false
+	}
+	if len(h.buffered) < PausedBufferCap {
+		h.buffered = append(h.buffered, bufferedMessage{userID: userID, message: message})
+	} else {
+		h.dropped++
+	}
+	return true
+}
+
+// SetPaused pauses or resumes broadcasting, e.g. during bulk migrations.
+// Resuming discards anything buffered while paused; use ResumeAndFlush to deliver it instead.
+func (h *Hub) SetPaused(paused bool) {
+	h.pauseMu.Lock()
+	defer h.pauseMu.Unlock()
+	h.paused = paused
+	if !paused {
+		h.buffered = nil
+		h.dropped = 0
+	}
+}
+
+// ResumeAndFlush resumes broadcasting and delivers the messages buffered while paused, in order.
+// It returns the number of messages flushed.
+func (h *Hub) ResumeAndFlush() int {
+	h.pauseMu.Lock()
+	pending := h.buffered
+	h.paused = false
+	h.buffered = nil
+	h.dropped = 0
+	h.pauseMu.Unlock()
+
+	for _, m := range pending {
+		h.deliver(m.userID, m.message)
+	}
+	return len(pending)
+}
+
+// PauseState reports whether the hub is paused, how many messages are buffered and how many were dropped.
+func (h *Hub) PauseState() (paused bool, buffered, dropped int) {
+	h.pauseMu.Lock()
+	defer h.pauseMu.Unlock()
+	return h.paused, len(h.buffered), h.dropped
 }
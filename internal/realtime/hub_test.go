@@ -0,0 +1,124 @@
+package realtime
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClient is a Client whose liveness is fixed by the test.
+type fakeClient struct {
+	alive  bool
+	closed bool
+	sent   [][]byte
+	// failures is how many upcoming sends fail before sends succeed again
+	failures int
+	attempts int
+}
+
+func (f *fakeClient) Send(message []byte) bool {
+	f.attempts++
+	if f.failures > 0 {
+		f.failures--
+		return false
+	}
+	f.sent = append(f.sent, message)
+	return true
+}
+
+func (f *fakeClient) Close() { f.closed = true }
+
+func (f *fakeClient) IsAlive(time.Duration) bool { return f.alive }
+
+func TestPruneStaleConnections(t *testing.T) {
+	h := newHub()
+	healthy := &fakeClient{alive: true}
+	stale := &fakeClient{alive: false}
+	h.Register("u-1", healthy)
+	h.Register("u-1", stale)
+	h.Register("u-2", &fakeClient{alive: false})
+	require.Equal(t, 3, h.TotalConnections())
+
+	pruned := h.PruneStaleConnections(90 * time.Second)
+	require.Equal(t, 2, pruned)
+	require.Equal(t, 1, h.TotalConnections())
+	require.True(t, stale.closed)
+	require.False(t, healthy.closed)
+
+	// Broadcasts only reach the remaining healthy client
+	h.Broadcast("u-1", []byte("hello"))
+	require.Len(t, healthy.sent, 1)
+	require.Empty(t, stale.sent)
+}
+
+func TestBroadcast_SuppressedWhilePaused(t *testing.T) {
+	h := newHub()
+	client := &fakeClient{alive: true}
+	h.Register("u-1", client)
+
+	h.SetPaused(true)
+	h.Broadcast("u-1", []byte("a"))
+	require.Empty(t, client.sent)
+
+	// Plain resume discards what was held back
+	h.SetPaused(false)
+	h.Broadcast("u-1", []byte("b"))
+	require.Equal(t, [][]byte{[]byte("b")}, client.sent)
+
+	// Flushing resume delivers the buffer in order; overflow beyond the cap is dropped
+	h.SetPaused(true)
+	for i := 0; i < PausedBufferCap+3; i++ {
+		h.Broadcast("u-1", []byte("c"))
+	}
+	paused, buffered, dropped := h.PauseState()
+	require.True(t, paused)
+	require.Equal(t, PausedBufferCap, buffered)
+	require.Equal(t, 3, dropped)
+	require.Len(t, client.sent, 1)
+
+	require.Equal(t, PausedBufferCap, h.ResumeAndFlush())
+	require.Len(t, client.sent, 1+PausedBufferCap)
+	paused, buffered, _ = h.PauseState()
+	require.False(t, paused)
+	require.Zero(t, buffered)
+}
+
+func TestBroadcast_RetriesFailedSend(t *testing.T) {
+	h := newHub()
+	h.sendRetries = 1
+	flaky := &fakeClient{alive: true, failures: 1}
+	h.Register("u-1", flaky)
+
+	h.Broadcast("u-1", []byte("hello"))
+	require.Equal(t, 2, flaky.attempts)
+	require.Equal(t, [][]byte{[]byte("hello")}, flaky.sent)
+	require.False(t, flaky.closed)
+	require.Equal(t, 1, h.TotalConnections())
+}
+
+func TestBroadcast_DropsClientAfterRetriesExhausted(t *testing.T) {
+	h := newHub()
+	h.sendRetries = 2
+	dead := &fakeClient{alive: true, failures: 10}
+	healthy := &fakeClient{alive: true}
+	h.Register("u-1", dead)
+	h.Register("u-1", healthy)
+
+	h.Broadcast("u-1", []byte("hello"))
+	require.Equal(t, 3, dead.attempts)
+	require.True(t, dead.closed)
+	require.Len(t, healthy.sent, 1)
+	require.Equal(t, 1, h.TotalConnections())
+}
+
+func TestSendRetries_Env(t *testing.T) {
+	t.Setenv("WS_SEND_RETRIES", "")
+	require.Equal(t, DefaultSendRetries, SendRetries())
+	t.Setenv("WS_SEND_RETRIES", "3")
+	require.Equal(t, 3, SendRetries())
+	t.Setenv("WS_SEND_RETRIES", "0")
+	require.Equal(t, 0, SendRetries())
+	t.Setenv("WS_SEND_RETRIES", "-1")
+	require.Equal(t, DefaultSendRetries, SendRetries())
+}
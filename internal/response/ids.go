@@ -0,0 +1,48 @@
+package response
+
+import (
+	"os"
+	"strings"
+)
+
+// ID prefixes used internally for generated identifiers
+const (
+	TaskIDPrefix = "task-"
+	UserIDPrefix = "user-"
+)
+
+// stripIDPrefix reports whether outbound IDs should drop their type prefix (STRIP_ID_PREFIX=true)
+func stripIDPrefix() bool {
+	return strings.EqualFold(os.Getenv("STRIP_ID_PREFIX"), "true")
+}
+
+// ExternalID returns id as exposed to clients: without its task-/user- prefix when STRIP_ID_PREFIX is on
+func ExternalID(id string) string {
+	if !stripIDPrefix() {
+		return id
+	}
+	for _, prefix := range []string{TaskIDPrefix, UserIDPrefix} {
+		if strings.HasPrefix(id, prefix) {
+			return strings.TrimPrefix(id, prefix)
+		}
+	}
+	return id
+}
+
+// ExternalIDs applies ExternalID to every id in the list
+func ExternalIDs(ids []string) []string {
+	out := make([]string, 0, len(ids))
+	for _, id := range ids {
+		out = append(out, ExternalID(id))
+	}
+	return out
+}
+
+// InternalTaskID maps a client-supplied task id back to its stored form.
+// With STRIP_ID_PREFIX on, bare ids get the task- prefix re-added; prefixed ids pass through.
+func InternalTaskID(id string) string {
+	if !stripIDPrefix() || id == "" || strings.HasPrefix(id, TaskIDPrefix) {
+		return id
+	}
+	return TaskIDPrefix + id
+}
@@ -0,0 +1,65 @@
+package response
+
+import (
+	"encoding/json"
+	"task-management-api/internal/models"
+	"time"
+)
+
+// TaskView builds the JSON object for a task as seen by a caller with the given role.
+// Every task carries a computed overdue flag (see models.Task.IsOverdue) and an archived flag.
+// Admins additionally see the internal owner (userId) and raw assignee (assigneeId) fields.
+func TaskView(task models.Task, role string) map[string]any {
+	view := make(map[string]any)
+	if b, err := json.Marshal(task); err == nil {
+		_ = json.Unmarshal(b, &view)
+	}
+	view["id"] = ExternalID(task.ID)
+	view["overdue"] = task.IsOverdue(time.Now())
+	view["archived"] = task.ArchivedAt != nil
+	view["projectId"] = ExternalID(task.ProjectID)
+	if assignee, ok := view["assignee"].(map[string]any); ok {
+		assignee["id"] = ExternalID(task.Assignee.ID)
+	}
+	// Only soft-deleted rows loaded with Unscoped carry a deletion time
+	if task.DeletedAt.Valid {
+		view["deletedAt"] = task.DeletedAt.Time
+	}
+	if role == models.RoleAdmin {
+		view["userId"] = ExternalID(task.UserID)
+		view["assigneeId"] = ExternalID(task.AssigneeID)
+	}
+	return view
+}
+
+// TasksView applies TaskView to every task in the list
+func TasksView(tasks []models.Task, role string) []map[string]any {
+	views := make([]map[string]any, 0, len(tasks))
+	for _, t := range tasks {
+		views = append(views, TaskView(t, role))
+	}
+	return views
+}
+
+// UserView builds the safe JSON object for a user as seen by a caller with the given role.
+// Members only see id and username; admins also see account timestamps.
+func UserView(u models.User, role string) map[string]any {
+	view := map[string]any{
+		"id":       ExternalID(u.ID),
+		"username": u.Username,
+	}
+	if role == models.RoleAdmin {
+		view["createdAt"] = u.CreatedAt
+		view["updatedAt"] = u.UpdatedAt
+	}
+	return view
+}
+
+// UsersView applies UserView to every user in the list
+func UsersView(users []models.User, role string) []map[string]any {
+	views := make([]map[string]any, 0, len(users))
+	for _, u := range users {
+		views = append(views, UserView(u, role))
+	}
+	return views
+}
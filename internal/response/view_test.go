@@ -0,0 +1,58 @@
+package response
+
+import (
+	"testing"
+
+	"task-management-api/internal/models"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaskView_AdminSeesInternalFields(t *testing.T) {
+	task := models.Task{ID: "task-1", Title: "T", AssigneeID: "u-2", UserID: "u-1"}
+
+	admin := TaskView(task, models.RoleAdmin)
+	require.Equal(t, "task-1", admin["id"])
+	require.Equal(t, "T", admin["title"])
+	require.Equal(t, "u-1", admin["userId"])
+	require.Equal(t, "u-2", admin["assigneeId"])
+
+	member := TaskView(task, models.RoleMember)
+	require.Equal(t, "task-1", member["id"])
+	require.NotContains(t, member, "userId")
+	require.NotContains(t, member, "assigneeId")
+
+	// Unknown/empty role is treated as a member
+	require.NotContains(t, TaskView(task, ""), "userId")
+}
+
+func TestUsersView_FiltersByRole(t *testing.T) {
+	users := []models.User{{ID: "u-1", Username: "alice", Password: "secret"}}
+
+	member := UsersView(users, models.RoleMember)
+	require.Len(t, member, 1)
+	require.Equal(t, map[string]any{"id": "u-1", "username": "alice"}, member[0])
+
+	admin := UsersView(users, models.RoleAdmin)
+	require.Contains(t, admin[0], "createdAt")
+	require.NotContains(t, admin[0], "password")
+}
+
+func TestExternalAndInternalIDs(t *testing.T) {
+	task := models.Task{ID: "task-42", ProjectID: "task-7", Assignee: models.Assignee{ID: "u-1", Name: "alice"}}
+
+	// Off by default
+	require.Equal(t, "task-42", TaskView(task, models.RoleMember)["id"])
+	require.Equal(t, "42", InternalTaskID("42"))
+
+	t.Setenv("STRIP_ID_PREFIX", "true")
+	view := TaskView(task, models.RoleMember)
+	require.Equal(t, "42", view["id"])
+	require.Equal(t, "7", view["projectId"])
+	require.Equal(t, "u-1", view["assignee"].(map[string]any)["id"])
+	require.Equal(t, "abc", ExternalID("user-abc"))
+
+	// Round trip: bare ids regain the prefix, prefixed ids are untouched
+	require.Equal(t, "task-42", InternalTaskID("42"))
+	require.Equal(t, "task-42", InternalTaskID("task-42"))
+}
@@ -1,70 +1,142 @@
 package routes
 
 import (
-    "os"
+    "net/http"
+    "task-management-api/internal/health"
     "task-management-api/internal/handlers"
     "task-management-api/internal/middleware"
+    "task-management-api/internal/models"
+    "time"
 
     "github.com/gin-gonic/gin"
 )
 
+// Login attempts allowed per client IP within loginRateWindow, to slow password guessing
+const (
+	loginRateLimit  = 10
+	loginRateWindow = time.Minute
+)
+
 func SetupRoutes() *gin.Engine {
 	// Create a new GIN Router
 	ginRouter := gin.Default()
 
-    // CORS middleware (for frontend integration)
-    ginRouter.Use(func(c *gin.Context) {
-        allowedOrigin := os.Getenv("ALLOWED_ORIGIN")
-        if allowedOrigin == "" {
-            // Development default; set ALLOWED_ORIGIN in production
-            allowedOrigin = "http://localhost:3000"
-        }
-        c.Writer.Header().Set("Access-Control-Allow-Origin", allowedOrigin)
-        // Do not advertise credentials unless you use cookie-based auth
-        // c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-        c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
-        c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE, PATCH")
+	// CORS middleware (for frontend integration); the config is validated at startup
+	corsConfig, _ := middleware.CORSConfigFromEnv()
+	ginRouter.Use(middleware.CORS(corsConfig))
 
-        if c.Request.Method == "OPTIONS" {
-            c.AbortWithStatus(204)
-            return
-        }
+	// Security headers (HSTS, nosniff, frame denial); SECURITY_HEADERS=false disables them for local dev
+	ginRouter.Use(middleware.SecurityHeaders(middleware.SecurityHeadersEnabled()))
 
-        c.Next()
-    })
+	// Tag each request with a correlation ID for logs
+	ginRouter.Use(middleware.RequestID())
+	// Indent JSON bodies on ?pretty=true (outside ErrorHandler so problem responses are covered)
+	ginRouter.Use(middleware.PrettyJSON())
+	// Map domain errors raised via c.Error to problem details responses
+	ginRouter.Use(middleware.ErrorHandler())
 
 	// Health check endpoint
 	ginRouter.GET("/health", func(c *gin.Context) {
-		c.JSON(200, gin.H{
-			"status":  "ok",
-			"message": "Server Task Management API is running in Health Check Endpoint",
-		})
+		report := health.Check()
+		status := http.StatusOK
+		if !report.Healthy() {
+			status = http.StatusServiceUnavailable
+		}
+		c.JSON(status, report)
 	})
 
 	// Public routes (no authentication required)
 	api := ginRouter.Group("/api")
 	{
 		// Login endpoint
-		api.POST("/login", handlers.Login)
+		api.POST("/login", middleware.RateLimitMiddleware(loginRateLimit, loginRateWindow), handlers.Login)
+		// Account creation
+		api.POST("/register", handlers.Register)
+		// Exchange a still-valid token for a fresh one
+		api.POST("/refresh", handlers.Refresh)
+		// Build info, for correlating bug reports with deployed builds
+		api.GET("/version", func(c *gin.Context) {
+			c.JSON(http.StatusOK, health.Build())
+		})
 	}
 
 	// Protected routes (authentication required)
 	protectedRoutes := api.Group("")
 	protectedRoutes.Use(middleware.JWTAuthMiddleware())
+	// Chained on admin-only routes, after the JWT middleware has set the caller's role
+	adminOnly := middleware.RoleMiddleware(models.RoleAdmin)
 	{
+		// Revoke the current token
+		protectedRoutes.POST("/logout", handlers.Logout)
 		// WebSocket endpoint
 		protectedRoutes.GET("/ws", handlers.WebSocketHandler)
 		// Task endpoints
 		protectedRoutes.GET("/tasks", handlers.GetTasks)
+		protectedRoutes.GET("/tasks/autocomplete", handlers.AutocompleteTasks)
+		protectedRoutes.GET("/tasks/defaults", handlers.GetTaskDefaults)
+		protectedRoutes.GET("/tasks/stream", handlers.StreamTasks)
+		protectedRoutes.GET("/tasks/sla-risk", handlers.GetSLARisk)
+		protectedRoutes.GET("/tasks/trash", handlers.GetTrash)
 		protectedRoutes.GET("/tasks/:id", handlers.GetTaskByID)
+		protectedRoutes.GET("/tasks/:id/children", handlers.GetTaskChildren)
+		protectedRoutes.GET("/tasks/:id/comments", handlers.GetComments)
 		protectedRoutes.POST("/tasks", handlers.CreateTask)
+		protectedRoutes.POST("/tasks/bulk", handlers.BulkCreateTasks)
+		protectedRoutes.POST("/tasks/move-status", handlers.MoveTaskStatus)
+		protectedRoutes.POST("/tasks/reassign", adminOnly, handlers.ReassignTasks)
+		protectedRoutes.POST("/tasks/archive-done", handlers.ArchiveDoneTasks)
+		protectedRoutes.POST("/tasks/:id/restore", handlers.RestoreTask)
+		protectedRoutes.POST("/tasks/:id/auto-complete", handlers.AutoCompleteTask)
+		protectedRoutes.POST("/tasks/:id/merge", handlers.MergeTask)
+		protectedRoutes.POST("/tasks/:id/comments", handlers.CreateComment)
 		protectedRoutes.PUT("/tasks/:id", handlers.UpdateTask)
+		protectedRoutes.PATCH("/tasks/status", handlers.BulkUpdateStatus)
+		protectedRoutes.PATCH("/tasks/bulk/status", handlers.BulkUpdateStatus)
 		protectedRoutes.PATCH("/tasks/:id/status", handlers.UpdateTaskStatus)
+		protectedRoutes.PATCH("/tasks/:id/snooze", handlers.SnoozeTask)
+		protectedRoutes.PATCH("/tasks/:id/archive", handlers.ArchiveTask)
+		protectedRoutes.PATCH("/tasks/:id/unarchive", handlers.UnarchiveTask)
+		protectedRoutes.DELETE("/tasks/bulk", handlers.BulkDeleteTasks)
 		protectedRoutes.DELETE("/tasks/:id", handlers.DeleteTask)
+		protectedRoutes.DELETE("/tasks/:id/comments/:commentId", handlers.DeleteComment)
+		// Parent story checks ahead of bulk imports
+		protectedRoutes.POST("/stories/validate", handlers.ValidateStories)
+		// Board endpoints
+		protectedRoutes.POST("/boards", handlers.CreateBoard)
+		protectedRoutes.GET("/boards/:boardId/columns", handlers.GetBoardColumns)
+		protectedRoutes.PATCH("/boards/:boardId/columns/reorder", handlers.ReorderBoardColumns)
+		// Effort preview for a date range
+		protectedRoutes.GET("/effort", handlers.PreviewEffort)
 		// Stats endpoint by user
 		protectedRoutes.GET("/stats/:userid", handlers.GetStatsByUser)
+		protectedRoutes.GET("/stats/weekly-digest", handlers.GetWeeklyDigest)
+		protectedRoutes.GET("/stats/effort-distribution", handlers.GetEffortDistribution)
+		protectedRoutes.GET("/stats/workload", handlers.GetWorkloadSummary)
+		protectedRoutes.GET("/stats/leaderboard", handlers.GetLeaderboard)
+		protectedRoutes.POST("/stats/by-users", handlers.GetStatsByUsers)
+		// API keys for integrations acting as the current user
+		protectedRoutes.GET("/apikeys", handlers.ListAPIKeys)
+		protectedRoutes.POST("/apikeys", handlers.CreateAPIKey)
+		protectedRoutes.DELETE("/apikeys/:id", handlers.DeleteAPIKey)
+		// Current user's preferences
+		protectedRoutes.GET("/me/settings", handlers.GetMySettings)
+		protectedRoutes.PUT("/me/settings/:key", handlers.PutMySetting)
 		// Users endpoint
 		protectedRoutes.GET("/users", handlers.GetAllUsers)
+		protectedRoutes.GET("/users/:id/workload", handlers.GetUserWorkload)
+		protectedRoutes.GET("/users/:id/velocity", handlers.GetUserVelocity)
+		protectedRoutes.GET("/users/:id/activity", handlers.GetUserActivity)
+		protectedRoutes.DELETE("/users/:id", adminOnly, handlers.DeleteUser)
+		protectedRoutes.POST("/users/:id/reset-password", adminOnly, handlers.ResetPassword)
+		// Maintenance endpoints (admin only)
+		protectedRoutes.GET("/maintenance/orphans", adminOnly, handlers.GetOrphanedTasks)
+		protectedRoutes.POST("/maintenance/orphans/detach", adminOnly, handlers.DetachOrphanedTasks)
+		protectedRoutes.GET("/admin/metrics/snapshot", adminOnly, handlers.GetMetricsSnapshot)
+		protectedRoutes.PUT("/admin/realtime/pause", adminOnly, handlers.SetRealtimePaused)
+		protectedRoutes.GET("/admin/auditlog", adminOnly, handlers.GetAuthAuditLog)
+		protectedRoutes.GET("/audit", adminOnly, handlers.GetAuditFeed)
+		// Token introspection for resource servers (admin only)
+		protectedRoutes.POST("/introspect", adminOnly, handlers.IntrospectToken)
 	}
 
 	return ginRouter
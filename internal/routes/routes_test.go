@@ -1,19 +1,86 @@
 package routes
 
 import (
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"runtime"
 	"testing"
 
+	"task-management-api/internal/auth"
+	"task-management-api/internal/database"
+	"task-management-api/internal/models"
+	"task-management-api/internal/testutil"
+
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/require"
 )
 
 func TestHealth(t *testing.T) {
 	gin.SetMode(gin.TestMode)
+	db, err := testutil.NewInMemoryDB()
+	require.NoError(t, err)
+	database.DB = db
+
 	r := SetupRoutes()
 	w := httptest.NewRecorder()
 	req := httptest.NewRequest(http.MethodGet, "/health", nil)
 	r.ServeHTTP(w, req)
 	require.Equal(t, http.StatusOK, w.Code)
 }
+
+func TestHealth_DBPingFailureReturns503(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := testutil.NewInMemoryDB()
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	require.NoError(t, sqlDB.Close())
+	database.DB = db
+
+	r := SetupRoutes()
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health", nil))
+	require.Equal(t, http.StatusServiceUnavailable, w.Code)
+	require.Contains(t, w.Body.String(), `"dbPing":"fail"`)
+}
+
+func TestVersion_DefaultsWithoutLdflags(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	t.Setenv("VERSION", "")
+
+	r := SetupRoutes()
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/version", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var body map[string]string
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	require.Equal(t, map[string]string{
+		"version":   "dev",
+		"commit":    "unknown",
+		"buildDate": "unknown",
+		"goVersion": runtime.Version(),
+	}, body)
+}
+
+func TestAdminRoutes_RequireAdminRole(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := testutil.NewInMemoryDB()
+	require.NoError(t, err)
+	database.DB = db
+
+	r := SetupRoutes()
+	get := func(role string) int {
+		token, err := auth.GenerateToken("u-1", "alice", role)
+		require.NoError(t, err)
+		req := httptest.NewRequest(http.MethodGet, "/api/maintenance/orphans", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	require.Equal(t, http.StatusForbidden, get(models.RoleMember))
+	require.Equal(t, http.StatusOK, get(models.RoleAdmin))
+}
@@ -0,0 +1,68 @@
+package search
+
+import (
+	"fmt"
+	"io"
+
+	"gorm.io/gorm"
+)
+
+// ReindexBatchSize is the number of tasks copied into the index per statement
+const ReindexBatchSize = 500
+
+// EnsureIndex creates the tasks_fts full-text table if it does not exist yet.
+// Rows are keyed by the rowid of the matching tasks row.
+func EnsureIndex(db *gorm.DB) error {
+	return db.Exec("CREATE VIRTUAL TABLE IF NOT EXISTS tasks_fts USING fts5(title, description)").Error
+}
+
+// Reindex rebuilds tasks_fts from the tasks table inside a single transaction.
+// The index is cleared first, so re-running is safe. Progress lines are written to out.
+// With dryRun set nothing is modified; the planned work is reported instead.
+// Returns the number of tasks indexed (or that would be indexed).
+func Reindex(db *gorm.DB, out io.Writer, dryRun bool) (int64, error) {
+	var total int64
+	if err := db.Table("tasks").Count(&total).Error; err != nil {
+		return 0, err
+	}
+
+	if dryRun {
+		fmt.Fprintf(out, "Dry run: would clear tasks_fts and index %d tasks in batches of %d\n", total, ReindexBatchSize)
+		return total, nil
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := EnsureIndex(tx); err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM tasks_fts").Error; err != nil {
+			return err
+		}
+
+		var indexed int64
+		var lastRowID int64
+		for indexed < total {
+			// Keyset pagination on rowid keeps each batch cheap regardless of table size
+			res := tx.Exec(
+				"INSERT INTO tasks_fts (rowid, title, description) SELECT rowid, title, description FROM tasks WHERE rowid > ? ORDER BY rowid LIMIT ?",
+				lastRowID, ReindexBatchSize,
+			)
+			if res.Error != nil {
+				return res.Error
+			}
+			if res.RowsAffected == 0 {
+				break
+			}
+			if err := tx.Raw("SELECT MAX(rowid) FROM tasks_fts").Scan(&lastRowID).Error; err != nil {
+				return err
+			}
+			indexed += res.RowsAffected
+			fmt.Fprintf(out, "Indexed %d/%d tasks\n", indexed, total)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return total, nil
+}
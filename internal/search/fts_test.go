@@ -0,0 +1,66 @@
+package search
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"task-management-api/internal/models"
+	"task-management-api/internal/testutil"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReindex_PopulatesIndexIdempotently(t *testing.T) {
+	db, err := testutil.NewInMemoryDB()
+	require.NoError(t, err)
+
+	for i := 0; i < ReindexBatchSize+20; i++ {
+		title := fmt.Sprintf("Routine task %d", i)
+		if i == 7 {
+			title = "Migrate billing service"
+		}
+		require.NoError(t, db.Create(&models.Task{
+			ID:          fmt.Sprintf("task-%d", i),
+			Title:       title,
+			Description: "details",
+			TaskType:    models.TypeStory,
+			UserID:      "u-1",
+		}).Error)
+	}
+
+	for run := 0; run < 2; run++ {
+		var out bytes.Buffer
+		n, err := Reindex(db, &out, false)
+		require.NoError(t, err)
+		require.Equal(t, int64(ReindexBatchSize+20), n)
+		require.Contains(t, out.String(), fmt.Sprintf("Indexed %d/%d tasks", ReindexBatchSize, n))
+		require.Contains(t, out.String(), fmt.Sprintf("Indexed %d/%d tasks", n, n))
+
+		var indexed int64
+		require.NoError(t, db.Raw("SELECT COUNT(*) FROM tasks_fts").Scan(&indexed).Error)
+		require.Equal(t, n, indexed)
+	}
+
+	var ids []string
+	require.NoError(t, db.Raw(
+		"SELECT tasks.id FROM tasks_fts JOIN tasks ON tasks.rowid = tasks_fts.rowid WHERE tasks_fts MATCH ?", "billing",
+	).Scan(&ids).Error)
+	require.Equal(t, []string{"task-7"}, ids)
+}
+
+func TestReindex_DryRunDoesNotModify(t *testing.T) {
+	db, err := testutil.NewInMemoryDB()
+	require.NoError(t, err)
+	require.NoError(t, db.Create(&models.Task{ID: "task-1", Title: "Alpha", TaskType: models.TypeStory, UserID: "u-1"}).Error)
+
+	var out bytes.Buffer
+	n, err := Reindex(db, &out, true)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), n)
+	require.Contains(t, out.String(), "Dry run")
+
+	var tables int64
+	require.NoError(t, db.Raw("SELECT COUNT(*) FROM sqlite_master WHERE name = 'tasks_fts'").Scan(&tables).Error)
+	require.Zero(t, tables)
+}
@@ -16,7 +16,18 @@ func NewInMemoryDB() (*gorm.DB, error) {
 	if err != nil {
 		return nil, err
 	}
-	if err := db.AutoMigrate(&models.User{}, &models.Task{}); err != nil {
+	if err := db.AutoMigrate(
+		&models.User{},
+		&models.Task{},
+		&models.Board{},
+		&models.BoardColumn{},
+		&models.UserSetting{},
+		&models.TaskAudit{},
+		&models.APIKey{},
+		&models.LoginAttempt{},
+		&models.AuditEvent{},
+		&models.Comment{},
+	); err != nil {
 		return nil, err
 	}
 	return db, nil
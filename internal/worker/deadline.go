@@ -0,0 +1,57 @@
+package worker
+
+import (
+	"encoding/json"
+	"log"
+	"task-management-api/internal/models"
+	"task-management-api/internal/response"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DeadlineAlertWindow is how far ahead of a deadline the assignee is alerted
+const DeadlineAlertWindow = 24 * time.Hour
+
+// Broadcaster delivers a realtime message to a user's connections (realtime.Hub satisfies it)
+type Broadcaster interface {
+	Broadcast(userID string, message []byte)
+}
+
+// SendDeadlineAlerts alerts assignees of open, non-archived tasks due within DeadlineAlertWindow of now.
+// Each task is alerted at most once (tracked by alert_sent). It returns the number of alerts sent.
+func SendDeadlineAlerts(db *gorm.DB, hub Broadcaster, now time.Time) (int, error) {
+	// End dates are free-form strings, so candidates are filtered in Go after parsing
+	var candidates []models.Task
+	if err := db.Scopes(models.NotArchived).
+		Where("status <> ? AND alert_sent = ? AND assignee_id <> ''", models.StatusDone, false).
+		Find(&candidates).Error; err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, task := range candidates {
+		deadline, ok := task.Deadline()
+		if !ok || deadline.Before(now) || deadline.After(now.Add(DeadlineAlertWindow)) {
+			continue
+		}
+
+		// Mark first so a failed broadcast is not retried every tick
+		if err := db.Model(&models.Task{}).Where("id = ?", task.ID).UpdateColumn("alert_sent", true).Error; err != nil {
+			log.Printf("deadline alert: failed to mark task %s: %v", task.ID, err)
+			continue
+		}
+
+		evt := map[string]any{
+			"type":           "deadline_alert",
+			"taskId":         response.ExternalID(task.ID),
+			"hoursRemaining": int(deadline.Sub(now).Hours()),
+			"version":        1,
+		}
+		if bytes, err := json.Marshal(evt); err == nil {
+			hub.Broadcast(task.AssigneeID, bytes)
+			sent++
+		}
+	}
+	return sent, nil
+}
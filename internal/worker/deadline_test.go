@@ -0,0 +1,63 @@
+package worker
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"task-management-api/internal/models"
+	"task-management-api/internal/testutil"
+
+	"github.com/stretchr/testify/require"
+)
+
+// recordingHub captures broadcasts per user.
+type recordingHub struct {
+	messages map[string][][]byte
+}
+
+func (h *recordingHub) Broadcast(userID string, message []byte) {
+	if h.messages == nil {
+		h.messages = map[string][][]byte{}
+	}
+	h.messages[userID] = append(h.messages[userID], message)
+}
+
+func TestSendDeadlineAlerts_OncePerTask(t *testing.T) {
+	db, err := testutil.NewInMemoryDB()
+	require.NoError(t, err)
+
+	now := time.Date(2025, 3, 10, 10, 0, 0, 0, time.UTC)
+	seed := []models.Task{
+		{ID: "task-soon", EndDate: "2025-03-10", Status: models.StatusInProgress, AssigneeID: "u-2"}, // due end of today
+		{ID: "task-later", EndDate: "2025-03-20", Status: models.StatusTodo, AssigneeID: "u-2"},
+		{ID: "task-done", EndDate: "2025-03-10", Status: models.StatusDone, AssigneeID: "u-2"},
+		{ID: "task-past", EndDate: "2025-03-01", Status: models.StatusTodo, AssigneeID: "u-2"},
+	}
+	for _, task := range seed {
+		task.Title = task.ID
+		task.TaskType = models.TypeStory
+		task.UserID = "u-1"
+		require.NoError(t, db.Create(&task).Error)
+	}
+	archived := models.Task{ID: "task-archived", Title: "task-archived", TaskType: models.TypeStory, UserID: "u-1",
+		EndDate: "2025-03-10", Status: models.StatusTodo, AssigneeID: "u-2", ArchivedAt: &now}
+	require.NoError(t, db.Create(&archived).Error)
+
+	hub := &recordingHub{}
+	sent, err := SendDeadlineAlerts(db, hub, now)
+	require.NoError(t, err)
+	require.Equal(t, 1, sent)
+
+	// Next tick: already alerted, nothing new
+	sent, err = SendDeadlineAlerts(db, hub, now.Add(time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, 0, sent)
+
+	require.Len(t, hub.messages["u-2"], 1)
+	var evt map[string]any
+	require.NoError(t, json.Unmarshal(hub.messages["u-2"][0], &evt))
+	require.Equal(t, "deadline_alert", evt["type"])
+	require.Equal(t, "task-soon", evt["taskId"])
+	require.Equal(t, float64(14), evt["hoursRemaining"])
+}
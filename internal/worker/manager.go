@@ -0,0 +1,91 @@
+package worker
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// job is a function run periodically by the Manager.
+type job struct {
+	name     string
+	interval time.Duration
+	run      func()
+}
+
+// Manager runs registered jobs on their own tickers until stopped.
+type Manager struct {
+	mu      sync.Mutex
+	jobs    []job
+	stop    chan struct{}
+	wg      sync.WaitGroup
+	started bool
+}
+
+// NewManager creates an empty worker manager.
+func NewManager() *Manager {
+	return &Manager{stop: make(chan struct{})}
+}
+
+// Every registers fn to run every interval once the manager is started.
+// Jobs registered after Start are ignored.
+func (m *Manager) Every(name string, interval time.Duration, fn func()) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.started {
+		log.Printf("worker: ignoring job %q registered after start", name)
+		return
+	}
+	m.jobs = append(m.jobs, job{name: name, interval: interval, run: fn})
+}
+
+// Start launches one goroutine per registered job.
+func (m *Manager) Start() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.started {
+		return
+	}
+	m.started = true
+	for _, j := range m.jobs {
+		m.wg.Add(1)
+		go m.loop(j)
+	}
+}
+
+// Stop signals all jobs to exit and waits for in-flight runs to finish.
+func (m *Manager) Stop() {
+	m.mu.Lock()
+	if !m.started {
+		m.mu.Unlock()
+		return
+	}
+	m.started = false
+	close(m.stop)
+	m.mu.Unlock()
+	m.wg.Wait()
+}
+
+func (m *Manager) loop(j job) {
+	defer m.wg.Done()
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-m.stop:
+			return
+		case <-ticker.C:
+			m.runSafely(j)
+		}
+	}
+}
+
+// runSafely runs a job, recovering from panics so one bad run doesn't kill the worker.
+func (m *Manager) runSafely(j job) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("worker: job %q panicked: %v", j.name, r)
+		}
+	}()
+	j.run()
+}
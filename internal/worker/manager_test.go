@@ -0,0 +1,24 @@
+package worker
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestManager_RunsJobsUntilStopped(t *testing.T) {
+	var runs atomic.Int32
+	m := NewManager()
+	m.Every("counter", 5*time.Millisecond, func() { runs.Add(1) })
+	m.Every("panics", 5*time.Millisecond, func() { panic("boom") })
+
+	m.Start()
+	require.Eventually(t, func() bool { return runs.Load() >= 2 }, time.Second, 5*time.Millisecond)
+	m.Stop()
+
+	after := runs.Load()
+	time.Sleep(20 * time.Millisecond)
+	require.Equal(t, after, runs.Load(), "job ran after Stop")
+}
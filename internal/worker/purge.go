@@ -0,0 +1,17 @@
+package worker
+
+import (
+	"task-management-api/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PurgeDeletedTasks hard-deletes soft-deleted tasks whose grace period (purge_after) has passed.
+// It returns the number of tasks removed.
+func PurgeDeletedTasks(db *gorm.DB, now time.Time) (int64, error) {
+	result := db.Unscoped().
+		Where("deleted_at IS NOT NULL AND purge_after IS NOT NULL AND purge_after <= ?", now).
+		Delete(&models.Task{})
+	return result.RowsAffected, result.Error
+}
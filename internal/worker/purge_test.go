@@ -0,0 +1,41 @@
+package worker
+
+import (
+	"testing"
+	"time"
+
+	"task-management-api/internal/models"
+	"task-management-api/internal/testutil"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPurgeDeletedTasks_OnlyExpired(t *testing.T) {
+	db, err := testutil.NewInMemoryDB()
+	require.NoError(t, err)
+
+	now := time.Now()
+	expired := now.Add(-time.Minute)
+	pending := now.Add(time.Hour)
+	for _, task := range []models.Task{
+		{ID: "task-expired", PurgeAfter: &expired},
+		{ID: "task-pending", PurgeAfter: &pending},
+		{ID: "task-legacy"}, // deleted before grace tracking; left alone
+		{ID: "task-live", PurgeAfter: &expired},
+	} {
+		task.Title = task.ID
+		task.TaskType = models.TypeStory
+		require.NoError(t, db.Create(&task).Error)
+		if task.ID != "task-live" {
+			require.NoError(t, db.Delete(&task).Error)
+		}
+	}
+
+	n, err := PurgeDeletedTasks(db, now)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), n)
+
+	var remaining []string
+	require.NoError(t, db.Unscoped().Model(&models.Task{}).Order("id").Pluck("id", &remaining).Error)
+	require.Equal(t, []string{"task-legacy", "task-live", "task-pending"}, remaining)
+}